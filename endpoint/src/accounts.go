@@ -0,0 +1,340 @@
+/*
+// ----------------------------------------------------------------------------
+// accounts.go
+// Countertop Server Endpoint Account Sign In
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package endpoint
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	pb "github.com/theorangechefco/cts/go-protos"
+	"github.com/theorangechefco/cts/go-shared-libs/cts/util"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// Registers an email address and password for the caller's anonymous
+// profile, or for a new profile if the caller is not signed in, and signs
+// the device in
+func (s *Server) Register(ctx context.Context, request *pb.RegisterRequest) (*pb.SessionToken, error) {
+	currentUser, err := s.currentUser(ctx, "Register")
+	if err != nil {
+		return nil, err
+	}
+	request.Currentuser = currentUser
+
+	profileConn, profileClient, profilePoolErr := s.getProfileClient("Register")
+	defer s.ProfilePool.CarefullyPut(profileConn, &profilePoolErr)
+	if profilePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from profile pool. Error: %v", profilePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	userID, err := profileClient.Register(context.Background(), request)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "create",
+			"tag":   "profile",
+			"rpc":   "Register"},
+			fmt.Sprintf("Cannot register %q. Error: %v", request.Email, err))
+		switch grpc.Code(err) {
+		case codes.InvalidArgument, codes.AlreadyExists, codes.NotFound:
+			return nil, err
+		}
+		return nil, grpc.Errorf(codes.Internal, "Cannot register.")
+	}
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "create",
+		"tag":   "profile",
+		"rpc":   "Register"},
+		fmt.Sprintf("Registered %q for user with UUID %s", request.Email, userID.Uuid))
+
+	return s.signIn(ctx, userID, request.Profile.Identifier.Deviceidentifier, "Register")
+}
+
+// Signs the device in with an email address and password
+func (s *Server) Login(ctx context.Context, request *pb.LoginRequest) (*pb.SessionToken, error) {
+	if request.Deviceidentifier == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "Device identifier not specified.")
+	}
+
+	profileConn, profileClient, profilePoolErr := s.getProfileClient("Login")
+	defer s.ProfilePool.CarefullyPut(profileConn, &profilePoolErr)
+	if profilePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from profile pool. Error: %v", profilePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	userID, err := profileClient.VerifyCredentials(context.Background(), request)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "verify",
+			"tag":   "credentials",
+			"rpc":   "Login"},
+			fmt.Sprintf("Cannot sign in %q on device %s. Error: %v", request.Email, request.Deviceidentifier, err))
+		if grpc.Code(err) == codes.Unauthenticated {
+			return nil, err
+		}
+		return nil, grpc.Errorf(codes.Internal, "Cannot sign in.")
+	}
+
+	return s.signIn(ctx, userID, request.Deviceidentifier, "Login")
+}
+
+// Changes the user's password, and signs out every other device
+func (s *Server) ChangePassword(ctx context.Context, request *pb.ChangePasswordRequest) (*pb.Response, error) {
+	identConn, identClient, identPoolErr := s.getIdentityClient("ChangePassword")
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+	token, userID, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "ChangePassword", true)
+	if err != nil {
+		return nil, err
+	}
+
+	profileConn, profileClient, profilePoolErr := s.getProfileClient("ChangePassword")
+	defer s.ProfilePool.CarefullyPut(profileConn, &profilePoolErr)
+	if profilePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from profile pool. Error: %v", profilePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	change := &pb.ChangePasswordRequest{Id: userID, Currentpassword: request.Currentpassword, Newpassword: request.Newpassword}
+	if _, err := profileClient.ChangePassword(context.Background(), change); err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "update",
+			"tag":   "credentials",
+			"rpc":   "ChangePassword"},
+			fmt.Sprintf("Cannot change password for user with UUID %s. Error: %v", userID.Uuid, err))
+		switch grpc.Code(err) {
+		case codes.InvalidArgument, codes.PermissionDenied, codes.FailedPrecondition:
+			return nil, err
+		}
+		return nil, grpc.Errorf(codes.Internal, "Cannot change password.")
+	}
+
+	revoked, err := identClient.RevokeOtherSessions(context.Background(), &pb.SessionToken{Id: token})
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "close",
+			"tag":   "session",
+			"rpc":   "ChangePassword"},
+			fmt.Sprintf("Changed password, but cannot revoke other sessions for user with UUID %s. Error: %v", userID.Uuid, err))
+		return nil, grpc.Errorf(codes.Internal, "Password changed, but cannot sign out other devices.")
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "update",
+		"tag":   "credentials",
+		"rpc":   "ChangePassword"},
+		fmt.Sprintf("Changed password for user with UUID %s, revoked %d other sessions.", userID.Uuid, revoked.Revoked))
+	return &pb.Response{Success: true}, nil
+}
+
+// Mails a password reset code to the email address, if it is registered
+func (s *Server) RequestPasswordReset(ctx context.Context, request *pb.PasswordResetRequest) (*pb.Response, error) {
+	profileConn, profileClient, profilePoolErr := s.getProfileClient("RequestPasswordReset")
+	defer s.ProfilePool.CarefullyPut(profileConn, &profilePoolErr)
+	if profilePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from profile pool. Error: %v", profilePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	response, err := profileClient.RequestPasswordReset(context.Background(), request)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "send",
+			"tag":   "credentials",
+			"rpc":   "RequestPasswordReset"},
+			fmt.Sprintf("Cannot send password reset code to %q. Error: %v", request.Email, err))
+		switch grpc.Code(err) {
+		case codes.InvalidArgument, codes.Unavailable:
+			return nil, err
+		}
+		return nil, grpc.Errorf(codes.Internal, "Cannot send password reset code.")
+	}
+	return response, nil
+}
+
+// Sets a new password with a mailed reset code, and signs out every device
+func (s *Server) ResetPassword(ctx context.Context, request *pb.ResetPasswordRequest) (*pb.Response, error) {
+	profileConn, profileClient, profilePoolErr := s.getProfileClient("ResetPassword")
+	defer s.ProfilePool.CarefullyPut(profileConn, &profilePoolErr)
+	if profilePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from profile pool. Error: %v", profilePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	userID, err := profileClient.ResetPassword(context.Background(), request)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "update",
+			"tag":   "credentials",
+			"rpc":   "ResetPassword"},
+			fmt.Sprintf("Cannot reset password for %q. Error: %v", request.Email, err))
+		switch grpc.Code(err) {
+		case codes.InvalidArgument, codes.PermissionDenied:
+			return nil, err
+		}
+		return nil, grpc.Errorf(codes.Internal, "Cannot reset password.")
+	}
+
+	identConn, identClient, identPoolErr := s.getIdentityClient("ResetPassword")
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	revoked, err := identClient.RevokeAllSessions(context.Background(), userID)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "close",
+			"tag":   "session",
+			"rpc":   "ResetPassword"},
+			fmt.Sprintf("Reset password, but cannot revoke sessions for user with UUID %s. Error: %v", userID.Uuid, err))
+		return nil, grpc.Errorf(codes.Internal, "Password reset, but cannot sign out devices.")
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "update",
+		"tag":   "credentials",
+		"rpc":   "ResetPassword"},
+		fmt.Sprintf("Reset password for user with UUID %s, revoked %d sessions.", userID.Uuid, revoked.Revoked))
+	return &pb.Response{Success: true}, nil
+}
+
+// Signs the device in with an ID token from an OpenID Connect provider. The
+// first time the provider account signs in, it is linked to the caller's
+// anonymous profile, or to a new profile if the caller is not signed in
+func (s *Server) FederatedLogin(ctx context.Context, request *pb.IdTokenLoginRequest) (*pb.SessionToken, error) {
+	if s.IDTokens == nil {
+		return nil, grpc.Errorf(codes.Unimplemented, "Signing in with an identity provider is not enabled.")
+	}
+	if request.Deviceidentifier == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "Device identifier not specified.")
+	}
+
+	claims, err := s.IDTokens.Verify(request.Idtoken)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "verify",
+			"tag":   "idtoken",
+			"rpc":   "FederatedLogin"},
+			fmt.Sprintf("Cannot verify ID token for device %s. Error: %v", request.Deviceidentifier, err))
+		if _, ok := err.(*util.KeySetFetchError); ok {
+			return nil, grpc.Errorf(codes.Unavailable, "Cannot verify ID token, try again later.")
+		}
+		return nil, grpc.Errorf(codes.Unauthenticated, "Invalid ID token.")
+	}
+
+	currentUser, err := s.currentUser(ctx, "FederatedLogin")
+	if err != nil {
+		return nil, err
+	}
+
+	profile := request.Profile
+	if profile == nil {
+		profile = &pb.Profile{}
+	}
+	profile.Identifier = &pb.Identifier{Deviceidentifier: request.Deviceidentifier}
+	federated := &pb.FederatedLoginRequest{Issuer: claims.Issuer, Subject: claims.Subject, Profile: profile, Currentuser: currentUser}
+	// Unverified addresses could belong to anyone
+	if claims.EmailVerified {
+		federated.Email = claims.Email
+	}
+
+	profileConn, profileClient, profilePoolErr := s.getProfileClient("FederatedLogin")
+	defer s.ProfilePool.CarefullyPut(profileConn, &profilePoolErr)
+	if profilePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from profile pool. Error: %v", profilePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	userID, err := profileClient.FederatedLogin(context.Background(), federated)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "profile",
+			"rpc":   "FederatedLogin"},
+			fmt.Sprintf("Cannot sign in %s account %s on device %s. Error: %v", claims.Issuer, claims.Subject, request.Deviceidentifier, err))
+		switch grpc.Code(err) {
+		case codes.AlreadyExists, codes.NotFound:
+			return nil, err
+		}
+		return nil, grpc.Errorf(codes.Internal, "Cannot sign in.")
+	}
+
+	return s.signIn(ctx, userID, request.Deviceidentifier, "FederatedLogin")
+}
+
+// currentUser returns the user whose session token the caller presents, or
+// nil if it presents none. An account is only added to an existing profile
+// for its owner; the device identifier alone proves nothing.
+func (s *Server) currentUser(ctx context.Context, rpc string) (*pb.UserId, error) {
+	md, _ := metadata.FromContext(ctx)
+	if _, ok := md["token"]; !ok {
+		return nil, nil
+	}
+
+	identConn, identClient, identPoolErr := s.getIdentityClient(rpc)
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+	_, userID, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, rpc, true)
+	return userID, err
+}
+
+// signIn issues a session token for the user on the device.
+func (s *Server) signIn(ctx context.Context, userID *pb.UserId, deviceID string, rpc string) (*pb.SessionToken, error) {
+	identConn, identClient, identPoolErr := s.getIdentityClient(rpc)
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	sessionRequest := &pb.SessionRequest{Uuid: userID.Uuid, Deviceid: deviceID, Appversion: appVersion(ctx)}
+	token, err := identClient.GenerateSessionToken(context.Background(), sessionRequest)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "create",
+			"tag":   "session",
+			"rpc":   rpc},
+			fmt.Sprintf("Cannot generate token for user with UUID %s. Error: %v", userID.Uuid, err))
+		return nil, grpc.Errorf(codes.Internal, "Cannot generate session token for user %s.", userID.Uuid)
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "create",
+		"tag":   "session",
+		"rpc":   rpc},
+		fmt.Sprintf("Signed in user with UUID %s on device %s.", userID.Uuid, deviceID))
+	return token, nil
+}
@@ -0,0 +1,357 @@
+/*
+// ----------------------------------------------------------------------------
+// authoring.go
+// Countertop Server Endpoint Recipe Authoring RPCs
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package endpoint
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	pb "github.com/theorangechefco/cts/go-protos"
+	"github.com/theorangechefco/cts/go-shared-libs/cts/util"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// Authoring RPCs are restricted to users whose profile has the admin role.
+// The admin's UUID is recorded as the editor of every change.
+
+func (s *Server) CreateRecipe(ctx context.Context, writeRequest *pb.RecipeWriteRequest) (*pb.RecipeVersion, error) {
+	adminID, err := s.authenticateAdmin(ctx, "CreateRecipe")
+	if err != nil {
+		return nil, err
+	}
+	writeRequest.Editor = adminID.Uuid
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("CreateRecipe")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for CreateRecipe by admin %s. Error: %v", adminID.Uuid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	result, resultErr := recipeClient.CreateRecipe(context.Background(), writeRequest)
+	if err := s.authoringResult("CreateRecipe", adminID, recipeIDOf(writeRequest.Recipe), resultErr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Server) UpdateRecipe(ctx context.Context, writeRequest *pb.RecipeWriteRequest) (*pb.RecipeVersion, error) {
+	adminID, err := s.authenticateAdmin(ctx, "UpdateRecipe")
+	if err != nil {
+		return nil, err
+	}
+	writeRequest.Editor = adminID.Uuid
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("UpdateRecipe")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for UpdateRecipe by admin %s. Error: %v", adminID.Uuid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	result, resultErr := recipeClient.UpdateRecipe(context.Background(), writeRequest)
+	if err := s.authoringResult("UpdateRecipe", adminID, recipeIDOf(writeRequest.Recipe), resultErr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Server) DeleteRecipe(ctx context.Context, authoringRequest *pb.AuthoringRequest) (*pb.RecipeVersion, error) {
+	adminID, err := s.authenticateAdmin(ctx, "DeleteRecipe")
+	if err != nil {
+		return nil, err
+	}
+	authoringRequest.Editor = adminID.Uuid
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("DeleteRecipe")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for DeleteRecipe by admin %s. Error: %v", adminID.Uuid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	result, resultErr := recipeClient.DeleteRecipe(context.Background(), authoringRequest)
+	if err := s.authoringResult("DeleteRecipe", adminID, authoringRequest.Id, resultErr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Server) PublishRecipe(ctx context.Context, publishRequest *pb.PublishRequest) (*pb.RecipeVersion, error) {
+	adminID, err := s.authenticateAdmin(ctx, "PublishRecipe")
+	if err != nil {
+		return nil, err
+	}
+	publishRequest.Editor = adminID.Uuid
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("PublishRecipe")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for PublishRecipe by admin %s. Error: %v", adminID.Uuid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	result, resultErr := recipeClient.PublishRecipe(context.Background(), publishRequest)
+	if err := s.authoringResult("PublishRecipe", adminID, publishRequest.Id, resultErr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Server) RollbackRecipe(ctx context.Context, rollbackRequest *pb.RollbackRequest) (*pb.RecipeVersion, error) {
+	adminID, err := s.authenticateAdmin(ctx, "RollbackRecipe")
+	if err != nil {
+		return nil, err
+	}
+	rollbackRequest.Editor = adminID.Uuid
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("RollbackRecipe")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for RollbackRecipe by admin %s. Error: %v", adminID.Uuid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	result, resultErr := recipeClient.RollbackRecipe(context.Background(), rollbackRequest)
+	if err := s.authoringResult("RollbackRecipe", adminID, rollbackRequest.Id, resultErr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Server) GetRecipeHistory(ctx context.Context, authoringRequest *pb.AuthoringRequest) (*pb.RecipeHistory, error) {
+	adminID, err := s.authenticateAdmin(ctx, "GetRecipeHistory")
+	if err != nil {
+		return nil, err
+	}
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("GetRecipeHistory")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for GetRecipeHistory by admin %s. Error: %v", adminID.Uuid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	result, resultErr := recipeClient.GetRecipeHistory(context.Background(), authoringRequest)
+	if err := s.authoringResult("GetRecipeHistory", adminID, authoringRequest.Id, resultErr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Server) CreateRecipePack(ctx context.Context, writeRequest *pb.RecipePackWriteRequest) (*pb.RecipeVersion, error) {
+	adminID, err := s.authenticateAdmin(ctx, "CreateRecipePack")
+	if err != nil {
+		return nil, err
+	}
+	writeRequest.Editor = adminID.Uuid
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("CreateRecipePack")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for CreateRecipePack by admin %s. Error: %v", adminID.Uuid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	result, resultErr := recipeClient.CreateRecipePack(context.Background(), writeRequest)
+	if err := s.authoringResult("CreateRecipePack", adminID, packIDOf(writeRequest.Recipepack), resultErr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Server) UpdateRecipePack(ctx context.Context, writeRequest *pb.RecipePackWriteRequest) (*pb.RecipeVersion, error) {
+	adminID, err := s.authenticateAdmin(ctx, "UpdateRecipePack")
+	if err != nil {
+		return nil, err
+	}
+	writeRequest.Editor = adminID.Uuid
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("UpdateRecipePack")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for UpdateRecipePack by admin %s. Error: %v", adminID.Uuid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	result, resultErr := recipeClient.UpdateRecipePack(context.Background(), writeRequest)
+	if err := s.authoringResult("UpdateRecipePack", adminID, packIDOf(writeRequest.Recipepack), resultErr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Server) DeleteRecipePack(ctx context.Context, authoringRequest *pb.AuthoringRequest) (*pb.RecipeVersion, error) {
+	adminID, err := s.authenticateAdmin(ctx, "DeleteRecipePack")
+	if err != nil {
+		return nil, err
+	}
+	authoringRequest.Editor = adminID.Uuid
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("DeleteRecipePack")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for DeleteRecipePack by admin %s. Error: %v", adminID.Uuid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	result, resultErr := recipeClient.DeleteRecipePack(context.Background(), authoringRequest)
+	if err := s.authoringResult("DeleteRecipePack", adminID, authoringRequest.Id, resultErr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Server) PublishRecipePack(ctx context.Context, publishRequest *pb.PublishRequest) (*pb.RecipeVersion, error) {
+	adminID, err := s.authenticateAdmin(ctx, "PublishRecipePack")
+	if err != nil {
+		return nil, err
+	}
+	publishRequest.Editor = adminID.Uuid
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("PublishRecipePack")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for PublishRecipePack by admin %s. Error: %v", adminID.Uuid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	result, resultErr := recipeClient.PublishRecipePack(context.Background(), publishRequest)
+	if err := s.authoringResult("PublishRecipePack", adminID, publishRequest.Id, resultErr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Server) RollbackRecipePack(ctx context.Context, rollbackRequest *pb.RollbackRequest) (*pb.RecipeVersion, error) {
+	adminID, err := s.authenticateAdmin(ctx, "RollbackRecipePack")
+	if err != nil {
+		return nil, err
+	}
+	rollbackRequest.Editor = adminID.Uuid
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("RollbackRecipePack")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for RollbackRecipePack by admin %s. Error: %v", adminID.Uuid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	result, resultErr := recipeClient.RollbackRecipePack(context.Background(), rollbackRequest)
+	if err := s.authoringResult("RollbackRecipePack", adminID, rollbackRequest.Id, resultErr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Server) GetRecipePackHistory(ctx context.Context, authoringRequest *pb.AuthoringRequest) (*pb.RecipeHistory, error) {
+	adminID, err := s.authenticateAdmin(ctx, "GetRecipePackHistory")
+	if err != nil {
+		return nil, err
+	}
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("GetRecipePackHistory")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for GetRecipePackHistory by admin %s. Error: %v", adminID.Uuid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	result, resultErr := recipeClient.GetRecipePackHistory(context.Background(), authoringRequest)
+	if err := s.authoringResult("GetRecipePackHistory", adminID, authoringRequest.Id, resultErr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// authenticateAdmin checks the session token and that the user it belongs to
+// has the admin role.
+func (s *Server) authenticateAdmin(ctx context.Context, rpc string) (*pb.UserId, error) {
+	identConn, identClient, identPoolErr := s.getIdentityClient(rpc)
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+	_, userID, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, rpc, true)
+	if err != nil {
+		return nil, err
+	}
+
+	profileConn, profileClient, profilePoolErr := s.getProfileClient(rpc)
+	defer s.ProfilePool.CarefullyPut(profileConn, &profilePoolErr)
+	if profilePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from profile pool when checking role of user: %s. Error: %v", userID.Uuid, profilePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	profile, profileErr := profileClient.GetProfileInfoByUUID(context.Background(), userID)
+	if profileErr != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "authorization",
+			"event": "fetch",
+			"tag":   "profile",
+			"rpc":   rpc},
+			fmt.Sprintf("Cannot fetch profile for user with UUID %s. Error: %v", userID.Uuid, profileErr))
+		return nil, grpc.Errorf(codes.Internal, "Cannot fetch profile for user %s.", userID.Uuid)
+	}
+	if profile.Role != pb.Role_ADMIN {
+		s.Logger.Error(logrus.Fields{
+			"phase": "authorization",
+			"event": "connection",
+			"tag":   "notadmin",
+			"rpc":   rpc},
+			fmt.Sprintf("User %s is not an admin, access denied", userID.Uuid))
+		return nil, grpc.Errorf(codes.PermissionDenied, "Admin role required, access denied.")
+	}
+	return userID, nil
+}
+
+// authoringResult logs the outcome of an authoring call, passing through
+// errors the admin can act on.
+func (s *Server) authoringResult(rpc string, adminID *pb.UserId, documentID string, resultErr error) error {
+	if resultErr != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "update",
+			"tag":   "recipestore",
+			"rpc":   rpc},
+			fmt.Sprintf("%s of %s by admin %s failed. Error: %v", rpc, documentID, adminID.Uuid, resultErr))
+		switch grpc.Code(resultErr) {
+		case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.FailedPrecondition:
+			return resultErr
+		}
+		return grpc.Errorf(codes.Internal, "Problem running %s.", rpc)
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "update",
+		"tag":   "recipestore",
+		"rpc":   rpc},
+		fmt.Sprintf("%s of %s by admin %s succeeded", rpc, documentID, adminID.Uuid))
+	return nil
+}
+
+func recipeIDOf(recipe *pb.Recipe) string {
+	if recipe == nil {
+		return ""
+	}
+	return recipe.Id
+}
+
+func packIDOf(pack *pb.RecipePack) string {
+	if pack == nil {
+		return ""
+	}
+	return pack.Id
+}
@@ -11,8 +11,10 @@
 package endpoint
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/Sirupsen/logrus"
 	pb "github.com/theorangechefco/cts/go-protos"
@@ -21,6 +23,7 @@ import (
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 )
 
 type Server struct {
@@ -28,6 +31,10 @@ type Server struct {
 	IdentityPool  *util.HostConnPool
 	ProfilePool   *util.HostConnPool
 	Logger         *logger.CtsLogger
+	// Verifier, if set, checks signed session tokens locally
+	Verifier *util.TokenVerifier
+	// IDTokens, if set, checks ID tokens for FederatedLogin
+	IDTokens *util.IDTokenVerifier
 }
 
 func (s *Server) GetRecipe(ctx context.Context, recipeReq *pb.RecipeRequest) (*pb.Recipe, error) {
@@ -37,7 +44,7 @@ func (s *Server) GetRecipe(ctx context.Context, recipeReq *pb.RecipeRequest) (*p
 		errorMsg := fmt.Sprintf("Problem fetching identity service connection from pool for recipe request with ID: %s. Error: %v", recipeReq.Recipeid, identPoolErr)
 		return nil, grpc.Errorf(codes.Internal, errorMsg)
 	}
-	if _, _, err := util.Authenticate(s.Logger, identClient, ctx, "GetRecipe", true); err != nil {
+	if _, _, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "GetRecipe", true); err != nil {
 		return nil, err
 	}
 
@@ -69,6 +76,51 @@ func (s *Server) GetRecipe(ctx context.Context, recipeReq *pb.RecipeRequest) (*p
 	return recipe, nil
 }
 
+// Returns a recipe sized to the requested servings, optionally converted to
+// metric or imperial units
+func (s *Server) ScaleRecipe(ctx context.Context, scaleRequest *pb.ScaleRecipeRequest) (*pb.Recipe, error) {
+	identConn, identClient, identPoolErr := s.getIdentityClient("ScaleRecipe")
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching identity service connection from pool for scale request for recipe with ID: %s. Error: %v", scaleRequest.Recipeid, identPoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+	if _, _, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "ScaleRecipe", true); err != nil {
+		return nil, err
+	}
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("ScaleRecipe")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for scale request for recipe with ID: %s. Error: %v", scaleRequest.Recipeid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	recipe, recipeErr := recipeClient.ScaleRecipe(context.Background(), scaleRequest)
+	if recipeErr != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "scale",
+			"tag":   "recipestore",
+			"rpc":   "ScaleRecipe"},
+			fmt.Sprintf("Cannot scale recipe with ID %s. Error: %v", scaleRequest.Recipeid, recipeErr))
+		switch grpc.Code(recipeErr) {
+		case codes.NotFound, codes.InvalidArgument, codes.FailedPrecondition:
+			return nil, recipeErr
+		}
+		return nil, grpc.Errorf(codes.Internal, "Cannot scale recipe with ID %s.", scaleRequest.Recipeid)
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "scale",
+		"tag":   "recipestore",
+		"rpc":   "ScaleRecipe"},
+		fmt.Sprintf("Successfully scaled recipe with ID %s to %d servings", scaleRequest.Recipeid, scaleRequest.Servings))
+
+	return recipe, nil
+}
+
 func (s *Server) GetRecipePacks(recipePackRequest *pb.RecipePacksRequest, serviceStream pb.EndpointService_GetRecipePacksServer) error {
 	ctx := serviceStream.Context()
 
@@ -78,7 +130,7 @@ func (s *Server) GetRecipePacks(recipePackRequest *pb.RecipePacksRequest, servic
 		errorMsg := fmt.Sprintf("Problem fetching identity service connection from pool for recipe pack request: %v. Error: %v", recipePackRequest, identPoolErr)
 		return grpc.Errorf(codes.Internal, errorMsg)
 	}
-	if _, _, err := util.Authenticate(s.Logger, identClient, ctx, "GetRecipePacks", true); err != nil {
+	if _, _, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "GetRecipePacks", true); err != nil {
 		return err
 	}
 
@@ -125,6 +177,203 @@ func (s *Server) GetRecipePacks(recipePackRequest *pb.RecipePacksRequest, servic
 	return nil
 }
 
+// Streams recipe packs ranked for the authenticated user's stored profile
+func (s *Server) GetRecommendedRecipePacks(null *pb.EmptyRequest, serviceStream pb.EndpointService_GetRecommendedRecipePacksServer) error {
+	ctx := serviceStream.Context()
+
+	identConn, identClient, identPoolErr := s.getIdentityClient("GetRecommendedRecipePacks")
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
+		return grpc.Errorf(codes.Internal, errorMsg)
+	}
+	_, userID, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "GetRecommendedRecipePacks", true)
+	if err != nil {
+		return err
+	}
+
+	profileConn, profileClient, profilePoolErr := s.getProfileClient("GetRecommendedRecipePacks")
+	defer s.ProfilePool.CarefullyPut(profileConn, &profilePoolErr)
+	if profilePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from profile pool when fetching profile for user: %s. Error: %v", userID.Uuid, profilePoolErr)
+		return grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	profile, profileErr := profileClient.GetProfileInfoByUUID(context.Background(), userID)
+	if profileErr != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "profile",
+			"rpc":   "GetRecommendedRecipePacks"},
+			fmt.Sprintf("Cannot fetch profile for user with UUID %s. Error: %v", userID.Uuid, profileErr))
+		switch grpc.Code(profileErr) {
+		case codes.NotFound, codes.InvalidArgument:
+			return profileErr
+		}
+		return grpc.Errorf(codes.Internal, "Cannot fetch profile for user %s.", userID.Uuid)
+	}
+
+	recipePackRequest, requestErr := recipePacksRequestFromProfile(profile)
+	if requestErr != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "profile",
+			"rpc":   "GetRecommendedRecipePacks"},
+			fmt.Sprintf("Cannot build recipe pack request for user with UUID %s. Error: %v", userID.Uuid, requestErr))
+		return grpc.Errorf(codes.FailedPrecondition, "Profile incomplete: %v.", requestErr)
+	}
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("GetRecommendedRecipePacks")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for recipe pack request: %v. Error: %v", recipePackRequest, recipePoolErr)
+		return grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	clientStream, err := recipeClient.GetRecipePacks(context.Background(), recipePackRequest)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "recipestore",
+			"rpc":   "GetRecommendedRecipePacks"},
+			fmt.Sprintf("Problem establishing connection to recipe service: %v", err))
+		return grpc.Errorf(codes.Internal, "Problem pulling recipes")
+	}
+
+	counter := 0
+	for {
+		recipePack, err := clientStream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			s.Logger.Error(logrus.Fields{
+				"phase": "process",
+				"event": "fetch",
+				"tag":   "recipestore",
+				"rpc":   "GetRecommendedRecipePacks"},
+				fmt.Sprintf("Problem receiving recipe packs from recipe service: %v", err))
+			switch grpc.Code(err) {
+			case codes.NotFound, codes.InvalidArgument:
+				return err
+			}
+			return grpc.Errorf(codes.Internal, "Problem pulling recipes")
+		}
+		if serverErr := serviceStream.Send(recipePack); serverErr != nil {
+			s.Logger.Error(logrus.Fields{
+				"phase": "process",
+				"event": "fetch",
+				"tag":   "recipestore",
+				"rpc":   "GetRecommendedRecipePacks"},
+				fmt.Sprintf("Problem sending data to client: %v", serverErr))
+			return grpc.Errorf(codes.Internal, "Problem sending data to client.")
+		}
+		counter++
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "fetch",
+		"tag":   "recipestore",
+		"rpc":   "GetRecommendedRecipePacks"},
+		fmt.Sprintf("Returned %d recommended recipe packs for user with UUID %s", counter, userID.Uuid))
+
+	return nil
+}
+
+// Derives a ranked recipe pack request from a stored profile
+func recipePacksRequestFromProfile(profile *pb.Profile) (*pb.RecipePacksRequest, error) {
+	if profile.Dietaryprofile == nil {
+		return nil, errors.New("dietary profile not set")
+	}
+	restriction := profile.Dietaryrestriction
+	if restriction == nil {
+		restriction = &pb.DietaryRestriction{}
+	}
+	return &pb.RecipePacksRequest{
+		Dietaryprofile:     profile.Dietaryprofile,
+		Dietaryrestriction: restriction,
+		Mealplan:           profile.Mealplan,
+		Weightgoal:         profile.Weightgoal,
+		Activitylevel:      profile.Activitylevel,
+		Rank:               true,
+	}, nil
+}
+
+func (s *Server) SearchRecipes(searchRequest *pb.RecipeSearchRequest, serviceStream pb.EndpointService_SearchRecipesServer) error {
+	ctx := serviceStream.Context()
+
+	identConn, identClient, identPoolErr := s.getIdentityClient("SearchRecipes")
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching identity service connection from pool for recipe search request: %v. Error: %v", searchRequest, identPoolErr)
+		return grpc.Errorf(codes.Internal, errorMsg)
+	}
+	if _, _, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "SearchRecipes", true); err != nil {
+		return err
+	}
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("SearchRecipes")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for recipe search request: %v. Error: %v", searchRequest, recipePoolErr)
+		return grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	clientStream, err := recipeClient.SearchRecipes(context.Background(), searchRequest)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "recipestore",
+			"rpc":   "SearchRecipes"},
+			fmt.Sprintf("Problem establishing connection to recipe service: %v", err))
+		return grpc.Errorf(codes.Internal, "Problem searching recipes")
+	}
+
+	counter := 0
+	for {
+		result, err := clientStream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			s.Logger.Error(logrus.Fields{
+				"phase": "process",
+				"event": "fetch",
+				"tag":   "recipestore",
+				"rpc":   "SearchRecipes"},
+				fmt.Sprintf("Problem receiving search results from recipe service: %v", err))
+			if grpc.Code(err) == codes.InvalidArgument {
+				return err
+			}
+			return grpc.Errorf(codes.Internal, "Problem searching recipes")
+		}
+		if serverErr := serviceStream.Send(result); serverErr != nil {
+			s.Logger.Error(logrus.Fields{
+				"phase": "process",
+				"event": "fetch",
+				"tag":   "recipestore",
+				"rpc":   "SearchRecipes"},
+				fmt.Sprintf("Problem sending data to client: %v", serverErr))
+			return grpc.Errorf(codes.Internal, "Problem sending data to client.")
+		}
+		counter++
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "fetch",
+		"tag":   "recipestore",
+		"rpc":   "SearchRecipes"},
+		fmt.Sprintf("Returned %d recipes for recipe search request %v", counter, searchRequest))
+
+	return nil
+}
+
 // Returns the session token based on the identifier
 func (s *Server) GetSessionToken(ctx context.Context, identifier *pb.Identifier) (*pb.SessionToken, error) {
 	profileConn, profileClient, profilePoolErr := s.getProfileClient("GetSessionToken")
@@ -142,6 +391,9 @@ func (s *Server) GetSessionToken(ctx context.Context, identifier *pb.Identifier)
 			"tag":   "session",
 			"rpc":   "GetSessionToken"},
 			fmt.Sprintf("Cannot fetch for UUID for identifier %v, error: %v", identifier, err))
+		if grpc.Code(err) == codes.PermissionDenied {
+			return nil, grpc.Errorf(codes.Unauthenticated, "Sign in with your email address and password.")
+		}
 		return nil, grpc.Errorf(codes.Unauthenticated, "Invalid identifier %v.", identifier)
 	}
 
@@ -152,7 +404,8 @@ func (s *Server) GetSessionToken(ctx context.Context, identifier *pb.Identifier)
 		return nil, grpc.Errorf(codes.Internal, errorMsg)
 	}
 
-	token, tokenGenErr := identClient.GenerateSessionToken(context.Background(), userID)
+	sessionRequest := &pb.SessionRequest{Uuid: userID.Uuid, Deviceid: identifier.Deviceidentifier, Appversion: appVersion(ctx)}
+	token, tokenGenErr := identClient.GenerateSessionToken(context.Background(), sessionRequest)
 	if tokenGenErr != nil {
 		s.Logger.Error(logrus.Fields{
 			"phase": "process",
@@ -205,7 +458,11 @@ func (s *Server) CreateProfile(ctx context.Context, profile *pb.Profile) (*pb.Se
 		return nil, grpc.Errorf(codes.Internal, errorMsg)
 	}
 
-	token, tokenGenErr := identClient.GenerateSessionToken(context.Background(), userID)
+	sessionRequest := &pb.SessionRequest{Uuid: userID.Uuid, Appversion: appVersion(ctx)}
+	if profile.Identifier != nil {
+		sessionRequest.Deviceid = profile.Identifier.Deviceidentifier
+	}
+	token, tokenGenErr := identClient.GenerateSessionToken(context.Background(), sessionRequest)
 	if tokenGenErr != nil {
 		s.Logger.Error(logrus.Fields{
 			"phase": "process",
@@ -226,6 +483,41 @@ func (s *Server) CreateProfile(ctx context.Context, profile *pb.Profile) (*pb.Se
 	return token, nil
 }
 
+// Exchanges a refresh token for a new access and refresh token pair, so the
+// app can renew its session without the user signing in again. The access
+// token may already have expired, so the request is not authenticated.
+func (s *Server) RefreshSession(ctx context.Context, request *pb.RefreshRequest) (*pb.SessionToken, error) {
+	identConn, identClient, identPoolErr := s.getIdentityClient("RefreshSession")
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	token, err := identClient.RefreshSession(context.Background(), request)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "refresh",
+			"tag":   "session",
+			"rpc":   "RefreshSession"},
+			fmt.Sprintf("Cannot refresh session. Error: %v", err))
+		switch grpc.Code(err) {
+		case codes.InvalidArgument, codes.Unauthenticated:
+			return nil, err
+		}
+		return nil, grpc.Errorf(codes.Internal, "Cannot refresh session.")
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "refresh",
+		"tag":   "session",
+		"rpc":   "RefreshSession"},
+		"Refreshed session, issued a new access token.")
+	return token, nil
+}
+
 func (s *Server) GetProfileInfo(ctx context.Context, null *pb.EmptyRequest) (*pb.Profile, error) {
 	identConn, identClient, identPoolErr := s.getIdentityClient("GetProfileInfo")
 	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
@@ -233,7 +525,7 @@ func (s *Server) GetProfileInfo(ctx context.Context, null *pb.EmptyRequest) (*pb
 		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
 		return nil, grpc.Errorf(codes.Internal, errorMsg)
 	}
-	_, userID, err := util.Authenticate(s.Logger, identClient, ctx, "GetProfileInfo", true)
+	_, userID, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "GetProfileInfo", true)
 	if err != nil {
 		return nil, err
 	}
@@ -265,6 +557,48 @@ func (s *Server) GetProfileInfo(ctx context.Context, null *pb.EmptyRequest) (*pb
 	return profile, nil
 }
 
+func (s *Server) GetCalorieTarget(ctx context.Context, null *pb.EmptyRequest) (*pb.CalorieTarget, error) {
+	identConn, identClient, identPoolErr := s.getIdentityClient("GetCalorieTarget")
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+	_, userID, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "GetCalorieTarget", true)
+	if err != nil {
+		return nil, err
+	}
+
+	profileConn, profileClient, profilePoolErr := s.getProfileClient("GetCalorieTarget")
+	defer s.ProfilePool.CarefullyPut(profileConn, &profilePoolErr)
+	if profilePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from profile pool when fetching calorie target for user: %s. Error: %v", userID.Uuid, profilePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	target, targetErr := profileClient.GetCalorieTarget(context.Background(), userID)
+	if targetErr != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "profile",
+			"rpc":   "GetCalorieTarget"},
+			fmt.Sprintf("Cannot fetch calorie target for user with UUID %s. Error: %v", userID.Uuid, targetErr))
+		if grpc.Code(targetErr) == codes.FailedPrecondition {
+			return nil, targetErr
+		}
+		return nil, grpc.Errorf(codes.Internal, "Cannot fetch calorie target for user %s.", userID.Uuid)
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "fetch",
+		"tag":   "profile",
+		"rpc":   "GetCalorieTarget"},
+		fmt.Sprintf("Successfully fetched calorie target for user with UUID %s ", userID.Uuid))
+	return target, nil
+}
+
 func (s *Server) SetProfileInfo(ctx context.Context, profile *pb.Profile) (*pb.Response, error) {
 	identConn, identClient, identPoolErr := s.getIdentityClient("SetProfileInfo")
 	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
@@ -272,7 +606,7 @@ func (s *Server) SetProfileInfo(ctx context.Context, profile *pb.Profile) (*pb.R
 		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
 		return nil, grpc.Errorf(codes.Internal, errorMsg)
 	}
-	_, userID, err := util.Authenticate(s.Logger, identClient, ctx, "SetProfileInfo", true)
+	_, userID, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "SetProfileInfo", true)
 	if err != nil {
 		return nil, err
 	}
@@ -312,7 +646,7 @@ func (s *Server) CloseSession(ctx context.Context, null *pb.EmptyRequest) (*pb.R
 		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
 		return nil, grpc.Errorf(codes.Internal, errorMsg)
 	}
-	token, userID, err := util.Authenticate(s.Logger, identClient, ctx, "CloseSession", true)
+	token, userID, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "CloseSession", true)
 	if err != nil {
 		return nil, err
 	}
@@ -337,6 +671,118 @@ func (s *Server) CloseSession(ctx context.Context, null *pb.EmptyRequest) (*pb.R
 	return response, nil
 }
 
+// Lists the user's signed in devices
+func (s *Server) ListSessions(ctx context.Context, null *pb.EmptyRequest) (*pb.SessionList, error) {
+	identConn, identClient, identPoolErr := s.getIdentityClient("ListSessions")
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+	token, _, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "ListSessions", false)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := identClient.ListSessions(context.Background(), &pb.SessionToken{Id: token})
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "session",
+			"rpc":   "ListSessions"},
+			fmt.Sprintf("Cannot list sessions. Error: %v", err))
+		if grpc.Code(err) == codes.NotFound {
+			return nil, grpc.Errorf(codes.Unauthenticated, "Valid session token not provided, access denied.")
+		}
+		return nil, grpc.Errorf(codes.Internal, "Cannot list sessions.")
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "fetch",
+		"tag":   "session",
+		"rpc":   "ListSessions"},
+		fmt.Sprintf("Returning %d sessions.", len(sessions.Sessions)))
+	return sessions, nil
+}
+
+// Signs out one of the user's devices
+func (s *Server) RevokeSession(ctx context.Context, sessionID *pb.SessionId) (*pb.Response, error) {
+	identConn, identClient, identPoolErr := s.getIdentityClient("RevokeSession")
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+	token, userID, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "RevokeSession", true)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := identClient.RevokeSession(context.Background(), &pb.RevokeSessionRequest{Token: token, Sessionid: sessionID.Id})
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "close",
+			"tag":   "session",
+			"rpc":   "RevokeSession"},
+			fmt.Sprintf("Cannot revoke session %s for user with UUID %s. Error: %v", sessionID.Id, userID.Uuid, err))
+		if grpc.Code(err) == codes.NotFound {
+			return nil, grpc.Errorf(codes.NotFound, "Session %s does not exist.", sessionID.Id)
+		}
+		return nil, grpc.Errorf(codes.Internal, "Cannot revoke session.")
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "close",
+		"tag":   "session",
+		"rpc":   "RevokeSession"},
+		fmt.Sprintf("Revoked session %s for user with UUID %s.", sessionID.Id, userID.Uuid))
+	return response, nil
+}
+
+// Signs out every device but the caller's
+func (s *Server) RevokeOtherSessions(ctx context.Context, null *pb.EmptyRequest) (*pb.RevokeSessionsResponse, error) {
+	identConn, identClient, identPoolErr := s.getIdentityClient("RevokeOtherSessions")
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+	token, userID, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "RevokeOtherSessions", true)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := identClient.RevokeOtherSessions(context.Background(), &pb.SessionToken{Id: token})
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "close",
+			"tag":   "session",
+			"rpc":   "RevokeOtherSessions"},
+			fmt.Sprintf("Cannot revoke other sessions for user with UUID %s. Error: %v", userID.Uuid, err))
+		return nil, grpc.Errorf(codes.Internal, "Cannot revoke other sessions.")
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "close",
+		"tag":   "session",
+		"rpc":   "RevokeOtherSessions"},
+		fmt.Sprintf("Revoked %d other sessions for user with UUID %s.", response.Revoked, userID.Uuid))
+	return response, nil
+}
+
+// appVersion returns the app version the client sent in its request
+// metadata, if any.
+func appVersion(ctx context.Context) string {
+	md, _ := metadata.FromContext(ctx)
+	return strings.Join(md["appversion"], "")
+}
+
 func (s *Server) getProfileClient(rpc string) (*grpc.ClientConn, pb.ProfileServiceClient, error) {
 	conn, err := s.ProfilePool.Get()
 	if err != nil {
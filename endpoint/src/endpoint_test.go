@@ -11,58 +11,36 @@
 package endpoint_test
 
 import (
-	"errors"
-	"flag"
 	"fmt"
 	"io"
+	"net"
 	"os"
-	"strings"
 	"testing"
 	"time"
 
 	"github.com/Sirupsen/logrus"
-	"github.com/fzzy/radix/redis"
 	"github.com/golang/protobuf/proto"
+	endpoint "github.com/theorangechefco/cts/endpoint"
 	pb "github.com/theorangechefco/cts/go-protos"
 	"github.com/theorangechefco/cts/go-shared-libs/cts/logger"
 	"github.com/theorangechefco/cts/go-shared-libs/cts/util"
+	identity "github.com/theorangechefco/cts/identity"
+	profile "github.com/theorangechefco/cts/profile"
+	recipestore "github.com/theorangechefco/cts/recipestore"
 
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/grpclog"
 	"google.golang.org/grpc/metadata"
 )
 
-var (
-	serverAddr = flag.String("server_addr", "localhost:50053", "The server address in the format of host:port")
-	redisHost  = flag.String("redis_host", "0.0.0.0:6379", "Hostname of Redis server")
-	redisPass  = flag.String("redis_pass", "abc", "Password of Redis server")
-	dbHost     = flag.String("db_host", ":::::::", "Hostname of MySQL server")
-	dbPort     = flag.Int("db_port", 3306, "Port of MySQL server")
-	dbUser     = flag.String("db_user", "admin", "Username of MySQL server")
-	dbPass     = flag.String("db_pass", "admin", "Password of MySQL server user")
-	dbName     = flag.String("db_name", "profile", "Database name")
-
-	redisClient *redis.Client
-	client      pb.EndpointServiceClient
-)
-
-func TestCreateAccountAndCreateToken(t *testing.T) {
-	// Move this to identity service and make this higher levvel
-	logObj := logger.NewLogger("test", "", 8080, true, logrus.DebugLevel)
-	redisClient, _ := util.NewClient(logObj, *redisHost, *redisPass)
-	defer redisClient.Close()
-
-	util.BlowAwayRedis(redisClient)
-	util.ClearUserDB(*dbHost, int32(*dbPort), *dbUser, *dbPass, *dbName)
-
-	identifier := pb.Identifier{
-		Deviceidentifier: "abc123",
-	}
+var client pb.EndpointServiceClient
 
-	profile := pb.Profile{
-		Identifier:    &identifier,
+func testProfile(deviceID string) *pb.Profile {
+	return &pb.Profile{
+		Identifier: &pb.Identifier{
+			Deviceidentifier: deviceID,
+		},
 		Firstname:     "John",
 		Birthyear:     1985,
 		Gender:        pb.Gender_MALE,
@@ -79,130 +57,90 @@ func TestCreateAccountAndCreateToken(t *testing.T) {
 			Soyfree: true,
 		},
 	}
+}
 
-	token, err := client.CreateProfile(context.Background(), &profile)
-	if err != nil {
-		t.Errorf("%v", err)
-	}
-	util.BlowAwayRedis(redisClient)
-
-	token, err = client.GetSessionToken(context.Background(), &identifier)
-	if err != nil {
-		t.Errorf(err.Error())
-	}
-	sessionTokenKey := strings.Join([]string{"token", token.Id}, "_")
-	err = checkForRedisKey(*redisClient, *redisPass, sessionTokenKey, true)
+// signedIn creates a profile for the device, and returns a context carrying
+// its session token
+func signedIn(t *testing.T, deviceID string) context.Context {
+	token, err := client.CreateProfile(context.Background(), testProfile(deviceID))
 	if err != nil {
-		t.Errorf("%v", err)
+		t.Fatalf("CreateProfile(%s) = _, %v", deviceID, err)
 	}
-
+	return withToken(token)
 }
 
-func TestCloseSession(t *testing.T) {
-	util.BlowAwayRedis(redisClient)
+func withToken(token *pb.SessionToken) context.Context {
+	md := metadata.New(map[string]string{"token": token.Id})
+	return metadata.NewContext(context.Background(), md)
+}
 
-	logObj := logger.NewLogger("test", "", 8080, true, logrus.DebugLevel)
-	redisClient, _ := util.NewClient(logObj, *redisHost, *redisPass)
-	defer redisClient.Close()
+func TestCreateAccountAndCreateToken(t *testing.T) {
+	identifier := pb.Identifier{
+		Deviceidentifier: "create-token",
+	}
+	profile := testProfile(identifier.Deviceidentifier)
 
-	util.SetupUser(*dbHost, *dbPort, *dbUser, *dbPass, *dbName)
-	token, _ := util.SetupRedisAccount(*redisHost, *redisPass)
+	if _, err := client.CreateProfile(context.Background(), profile); err != nil {
+		t.Fatalf("CreateProfile(_) = _, %v", err)
+	}
 
-	sessionTokenKey := strings.Join([]string{"token", token.Id}, "_")
-	err := checkForRedisKey(*redisClient, *redisPass, sessionTokenKey, true)
+	token, err := client.GetSessionToken(context.Background(), &identifier)
 	if err != nil {
-		t.Errorf("%v", err)
+		t.Fatalf("GetSessionToken(%s) = _, %v", identifier.Deviceidentifier, err)
 	}
-	md := metadata.New(map[string]string{"token": token.Id})
-	ctx := metadata.NewContext(context.Background(), md)
+	if _, err := client.GetProfileInfo(withToken(token), &pb.EmptyRequest{}); err != nil {
+		t.Errorf("GetProfileInfo(_) with new token = _, %v", err)
+	}
+}
 
-	response, closeErr := client.CloseSession(ctx, &pb.EmptyRequest{})
+func TestCloseSession(t *testing.T) {
+	ctx := signedIn(t, "close-session")
 
-	if closeErr != nil {
-		t.Errorf("%v.CloseSession(_) = _, %v: ", client, err)
+	response, err := client.CloseSession(ctx, &pb.EmptyRequest{})
+	if err != nil {
+		t.Fatalf("CloseSession(_) = _, %v", err)
 	}
-	if response != nil && !response.Success {
+	if !response.Success {
 		t.Errorf("response.Success should be true.")
 	}
-	err = checkForRedisKey(*redisClient, *redisPass, sessionTokenKey, false)
-	if err != nil {
-		t.Errorf("%v", err)
+	if _, err := client.GetProfileInfo(ctx, &pb.EmptyRequest{}); grpc.Code(err) != codes.Unauthenticated {
+		t.Errorf("GetProfileInfo(_) with closed session = _, %v, want %v", err, codes.Unauthenticated)
 	}
 }
 
-func TestCreateProfileAndGetProfile(t *testing.T) {
-	util.ClearUserDB(*dbHost, int32(*dbPort), *dbUser, *dbPass, *dbName)
-	logObj := logger.NewLogger("test", "", 8080, true, logrus.DebugLevel)
-	redisClient, _ := util.NewClient(logObj, *redisHost, *redisPass)
-	defer redisClient.Close()
-	util.BlowAwayRedis(redisClient)
-
-	profile := pb.Profile{
-		Identifier: &pb.Identifier{
-			Deviceidentifier: "abc123",
-		},
-		Firstname:     "John",
-		Birthyear:     1985,
-		Gender:        pb.Gender_MALE,
-		Heightcm:      185,
-		Weightkg:      90,
-		Goalweightkg:  80,
-		Activitylevel: pb.ActivityLevel_SEDENTARY,
-		Mealplan:      pb.MealPlan_EIGHTEEN_HUNDRED,
-		Weightgoal:    pb.WeightGoal_LOSE,
-		Dietaryprofile: &pb.DietaryProfile{
-			Omnivore: true,
-		},
-		Dietaryrestriction: &pb.DietaryRestriction{
-			Soyfree: true,
-		},
+func TestMissingToken(t *testing.T) {
+	if _, err := client.GetProfileInfo(context.Background(), &pb.EmptyRequest{}); grpc.Code(err) != codes.Unauthenticated {
+		t.Errorf("GetProfileInfo(_) without token = _, %v, want %v", err, codes.Unauthenticated)
 	}
-
-	token, err := client.CreateProfile(context.Background(), &profile)
-	if err != nil {
-		t.Errorf(err.Error())
+	ctx := withToken(&pb.SessionToken{Id: "not-a-token"})
+	if _, err := client.GetProfileInfo(ctx, &pb.EmptyRequest{}); grpc.Code(err) != codes.Unauthenticated {
+		t.Errorf("GetProfileInfo(_) with unknown token = _, %v, want %v", err, codes.Unauthenticated)
 	}
-	sessionTokenKey := strings.Join([]string{"token", token.Id}, "_")
-	err = checkForRedisKey(*redisClient, *redisPass, sessionTokenKey, true)
+}
+
+func TestCreateProfileAndGetProfile(t *testing.T) {
+	profile := testProfile("get-profile")
+
+	token, err := client.CreateProfile(context.Background(), profile)
 	if err != nil {
-		t.Errorf(err.Error())
+		t.Fatalf("CreateProfile(_) = _, %v", err)
 	}
 
-	md := metadata.New(map[string]string{"token": token.Id})
-	ctx := metadata.NewContext(context.Background(), md)
-
-	returnedProfile, getProfileError := client.GetProfileInfo(ctx, &pb.EmptyRequest{})
-	if getProfileError != nil {
-		t.Errorf(getProfileError.Error())
+	returnedProfile, err := client.GetProfileInfo(withToken(token), &pb.EmptyRequest{})
+	if err != nil {
+		t.Fatalf("GetProfileInfo(_) = _, %v", err)
 	}
-	if !proto.Equal(&profile, returnedProfile) {
-		t.Errorf("Profiles are not equal")
+	if !proto.Equal(profile, returnedProfile) {
+		t.Errorf("GetProfileInfo(_) = %v, want %v", returnedProfile, profile)
 	}
 }
 
 func TestCreateAndUpdateProfile(t *testing.T) {
-	util.ClearUserDB(*dbHost, int32(*dbPort), *dbUser, *dbPass, *dbName)
-	logObj := logger.NewLogger("test", "", 8080, true, logrus.DebugLevel)
-	redisClient, _ := util.NewClient(logObj, *redisHost, *redisPass)
-	defer redisClient.Close()
-	util.BlowAwayRedis(redisClient)
-	_, err := util.SetupUser(*dbHost, *dbPort, *dbUser, *dbPass, *dbName)
-	if err != nil {
-		t.Errorf(err.Error())
-	}
-	token, redisErr := util.SetupRedisAccount(*redisHost, *redisPass)
-	if redisErr != nil {
-		t.Errorf(redisErr.Error())
-	}
-	sessionTokenKey := strings.Join([]string{"token", token.Id}, "_")
-	err = checkForRedisKey(*redisClient, *redisPass, sessionTokenKey, true)
-	if err != nil {
-		t.Errorf(err.Error())
-	}
+	ctx := signedIn(t, "update-profile")
 
 	updatedProfile := pb.Profile{
 		Identifier: &pb.Identifier{
-			Deviceidentifier: "ecb7381b-566c-4e53-b3ea-add8cd372d6a",
+			Deviceidentifier: "update-profile",
 		},
 		Firstname:     "Bob",
 		Birthyear:     1985,
@@ -223,117 +161,77 @@ func TestCreateAndUpdateProfile(t *testing.T) {
 		},
 	}
 
-	md := metadata.New(map[string]string{"token": token.Id})
-	ctx := metadata.NewContext(context.Background(), md)
-
-	response, setProfileError := client.SetProfileInfo(ctx, &updatedProfile)
-	if setProfileError != nil {
-		t.Errorf(setProfileError.Error())
+	response, err := client.SetProfileInfo(ctx, &updatedProfile)
+	if err != nil {
+		t.Fatalf("SetProfileInfo(_) = _, %v", err)
 	}
-	if response != nil && response.Success != true {
+	if !response.Success {
 		t.Errorf("Profile did not update successfully")
 	}
 
-	returnedProfile, getProfileError := client.GetProfileInfo(ctx, &pb.EmptyRequest{})
-	if getProfileError != nil {
-		t.Errorf(getProfileError.Error())
+	// The meal plan follows the updated biometrics
+	target, err := client.GetCalorieTarget(ctx, &pb.EmptyRequest{})
+	if err != nil {
+		t.Fatalf("GetCalorieTarget(_) = _, %v", err)
 	}
+	updatedProfile.Mealplan = target.Mealplan
 
+	returnedProfile, err := client.GetProfileInfo(ctx, &pb.EmptyRequest{})
+	if err != nil {
+		t.Fatalf("GetProfileInfo(_) = _, %v", err)
+	}
 	if !proto.Equal(&updatedProfile, returnedProfile) {
-		t.Errorf("Updated profile did not save correctly.")
+		t.Errorf("GetProfileInfo(_) = %v, want %v", returnedProfile, &updatedProfile)
 	}
 }
 
 func TestGetRecipe(t *testing.T) {
-	util.ClearUserDB(*dbHost, int32(*dbPort), *dbUser, *dbPass, *dbName)
-	logObj := logger.NewLogger("test", "", 8080, true, logrus.DebugLevel)
-	redisClient, _ := util.NewClient(logObj, *redisHost, *redisPass)
-	defer redisClient.Close()
-	util.BlowAwayRedis(redisClient)
-	_, err := util.SetupUser(*dbHost, *dbPort, *dbUser, *dbPass, *dbName)
-	if err != nil {
-		t.Errorf(err.Error())
-	}
-	token, redisErr := util.SetupRedisAccount(*redisHost, *redisPass)
-	if redisErr != nil {
-		t.Errorf(redisErr.Error())
-	}
-	sessionTokenKey := strings.Join([]string{"token", token.Id}, "_")
-	err = checkForRedisKey(*redisClient, *redisPass, sessionTokenKey, true)
-	if err != nil {
-		t.Errorf(err.Error())
-	}
+	ctx := signedIn(t, "get-recipe")
 
-	md := metadata.New(map[string]string{"token": token.Id})
-	ctx := metadata.NewContext(context.Background(), md)
-
-	recipe, recipeErr := client.GetRecipe(ctx, &pb.RecipeRequest{Recipeid: "142"})
-	if recipeErr != nil {
-		if grpc.Code(recipeErr) != codes.NotFound {
-			t.Errorf("GetRecipe(_) = _, %v", recipeErr)
-		} else {
-			t.Errorf("Recipe with ID 142 not found.")
-		}
+	recipe, err := client.GetRecipe(ctx, &pb.RecipeRequest{Recipeid: "142"})
+	if err != nil {
+		t.Fatalf("GetRecipe(142) = _, %v", err)
 	}
-	if recipe == nil {
-		t.Errorf("Recipe not fetched.")
+	if recipe.Id != "142" {
+		t.Errorf("GetRecipe(142) = %v, want recipe 142", recipe)
 	}
 }
 
 func TestGetMissingRecipe(t *testing.T) {
-	util.ClearUserDB(*dbHost, int32(*dbPort), *dbUser, *dbPass, *dbName)
-	logObj := logger.NewLogger("test", "", 8080, true, logrus.DebugLevel)
-	redisClient, _ := util.NewClient(logObj, *redisHost, *redisPass)
-	defer redisClient.Close()
-	util.BlowAwayRedis(redisClient)
-	_, err := util.SetupUser(*dbHost, *dbPort, *dbUser, *dbPass, *dbName)
-	if err != nil {
-		t.Errorf(err.Error())
-	}
-	token, redisErr := util.SetupRedisAccount(*redisHost, *redisPass)
-	if redisErr != nil {
-		t.Errorf(redisErr.Error())
-	}
-	sessionTokenKey := strings.Join([]string{"token", token.Id}, "_")
-	err = checkForRedisKey(*redisClient, *redisPass, sessionTokenKey, true)
-	if err != nil {
-		t.Errorf(err.Error())
-	}
+	ctx := signedIn(t, "get-missing-recipe")
 
-	md := metadata.New(map[string]string{"token": token.Id})
-	ctx := metadata.NewContext(context.Background(), md)
-
-	recipe, recipeErr := client.GetRecipe(ctx, &pb.RecipeRequest{Recipeid: "141"})
-	if recipeErr == nil || grpc.Code(recipeErr) != codes.NotFound {
-		t.Errorf("GetRecipe(_) = _, %v", recipeErr)
+	recipe, err := client.GetRecipe(ctx, &pb.RecipeRequest{Recipeid: "141"})
+	if grpc.Code(err) != codes.NotFound {
+		t.Errorf("GetRecipe(141) = _, %v, want %v", err, codes.NotFound)
 	}
 	if recipe != nil {
 		t.Errorf("Should not have returned recipe.")
 	}
 }
 
-func TestGetMessagePack(t *testing.T) {
-	util.ClearUserDB(*dbHost, int32(*dbPort), *dbUser, *dbPass, *dbName)
-	logObj := logger.NewLogger("test", "", 8080, true, logrus.DebugLevel)
-	redisClient, _ := util.NewClient(logObj, *redisHost, *redisPass)
-	defer redisClient.Close()
-	util.BlowAwayRedis(redisClient)
-	_, err := util.SetupUser(*dbHost, *dbPort, *dbUser, *dbPass, *dbName)
+func countRecipePacks(t *testing.T, ctx context.Context, request *pb.RecipePacksRequest) int {
+	stream, err := client.GetRecipePacks(ctx, request)
 	if err != nil {
-		t.Errorf(err.Error())
+		t.Fatalf("GetRecipePacks(_) = _, %v", err)
 	}
-	token, redisErr := util.SetupRedisAccount(*redisHost, *redisPass)
-	if redisErr != nil {
-		t.Errorf(redisErr.Error())
-	}
-	sessionTokenKey := strings.Join([]string{"token", token.Id}, "_")
-	err = checkForRedisKey(*redisClient, *redisPass, sessionTokenKey, true)
-	if err != nil {
-		t.Errorf(err.Error())
+	counter := 0
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("GetRecipePacks(_) = _, %v", err)
+		}
+		counter++
 	}
+	return counter
+}
 
-	counter := 0
-	recipePackRequst := pb.RecipePacksRequest{
+func TestGetMessagePack(t *testing.T) {
+	ctx := signedIn(t, "get-packs")
+
+	recipePackRequest := pb.RecipePacksRequest{
 		Dietaryprofile: &pb.DietaryProfile{
 			Omnivore:   true,
 			Vegetarian: false,
@@ -347,53 +245,15 @@ func TestGetMessagePack(t *testing.T) {
 			Lowsodium:  false},
 		Mealplan: pb.MealPlan_EIGHTEEN_HUNDRED}
 
-	md := metadata.New(map[string]string{"token": token.Id})
-	ctx := metadata.NewContext(context.Background(), md)
-
-	stream, recipeErr := client.GetRecipePacks(ctx, &recipePackRequst)
-	if recipeErr != nil {
-		t.Errorf("GetRecipePacks(_) = _, %v: ", recipeErr)
-		t.FailNow()
-
-	}
-
-	for {
-		_, streamErr := stream.Recv()
-		if streamErr == io.EOF {
-			break
-		}
-		if streamErr != nil {
-			t.Errorf("GetRecipePacks(_) = _, %v", err)
-		}
-		counter++
-	}
-	if counter == 0 {
+	if counter := countRecipePacks(t, ctx, &recipePackRequest); counter == 0 {
 		t.Errorf("No recipes returned")
 	}
 }
 
 func TestGetMissingMessagePack(t *testing.T) {
-	util.ClearUserDB(*dbHost, int32(*dbPort), *dbUser, *dbPass, *dbName)
-	logObj := logger.NewLogger("test", "", 8080, true, logrus.DebugLevel)
-	redisClient, _ := util.NewClient(logObj, *redisHost, *redisPass)
-	defer redisClient.Close()
-	util.BlowAwayRedis(redisClient)
-	_, err := util.SetupUser(*dbHost, *dbPort, *dbUser, *dbPass, *dbName)
-	if err != nil {
-		t.Errorf(err.Error())
-	}
-	token, redisErr := util.SetupRedisAccount(*redisHost, *redisPass)
-	if redisErr != nil {
-		t.Errorf(redisErr.Error())
-	}
-	sessionTokenKey := strings.Join([]string{"token", token.Id}, "_")
-	err = checkForRedisKey(*redisClient, *redisPass, sessionTokenKey, true)
-	if err != nil {
-		t.Errorf(err.Error())
-	}
+	ctx := signedIn(t, "get-missing-packs")
 
-	counter := 0
-	recipePackRequst := pb.RecipePacksRequest{
+	recipePackRequest := pb.RecipePacksRequest{
 		Dietaryprofile: &pb.DietaryProfile{
 			Omnivore:   false,
 			Vegetarian: false,
@@ -407,90 +267,127 @@ func TestGetMissingMessagePack(t *testing.T) {
 			Lowsodium:  false},
 		Mealplan: pb.MealPlan_EIGHTEEN_HUNDRED}
 
-	md := metadata.New(map[string]string{"token": token.Id})
-	ctx := metadata.NewContext(context.Background(), md)
-
-	stream, recipeErr := client.GetRecipePacks(ctx, &recipePackRequst)
-	if recipeErr != nil {
-		t.Errorf("GetRecipePacks(_) = _, %v: ", recipeErr)
-		t.FailNow()
-
-	}
-
-	for {
-		_, streamErr := stream.Recv()
-		if streamErr == io.EOF {
-			break
-		}
-		if streamErr != nil {
-			t.Errorf("GetRecipePacks(_) = _, %v", err)
-		}
-		counter++
-	}
-	if counter != 0 {
+	if counter := countRecipePacks(t, ctx, &recipePackRequest); counter != 0 {
 		t.Errorf("Should not have returned recipes")
 	}
 }
 
 //
-// Testing util functions
+// In-process services
 //
 
-func createRedisEntry(redisClient redis.Client, pass string, key string, value string) error {
-	if pass != "" {
-		authErr := redisClient.Cmd("AUTH", pass).Err
-		if authErr != nil {
-			return errors.New("Invalid Redis password")
-		}
+func testRecipes() []*pb.Recipe {
+	return []*pb.Recipe{
+		&pb.Recipe{Id: "142", Name: "Green smoothie"},
+	}
+}
+
+func testRecipePacks() []*pb.RecipePack {
+	return []*pb.RecipePack{
+		&pb.RecipePack{
+			Id:             "p1",
+			Name:           "Omnivore 1800",
+			Dietaryprofile: &pb.DietaryProfile{Omnivore: true},
+			Mealplan:       pb.MealPlan_EIGHTEEN_HUNDRED,
+		},
 	}
+}
 
-	err := redisClient.Cmd("SET", key, value, "EX", 86400).Err
+// serve starts a gRPC server on a free local port, registering services
+// with it
+func serve(register func(*grpc.Server)) (*grpc.Server, *net.TCPAddr, error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
-		errorString := fmt.Sprintf("Could not set value \"%s\" for key \"%s\". Error: %v", value, key, err)
-		return errors.New(errorString)
+		return nil, nil, err
 	}
-	return nil
+	grpcServer := grpc.NewServer()
+	register(grpcServer)
+	go grpcServer.Serve(lis)
+	return grpcServer, lis.Addr().(*net.TCPAddr), nil
 }
 
-func checkForRedisKey(redisClient redis.Client, pass string, key string, shouldExist bool) error {
-	if pass != "" {
-		authErr := redisClient.Cmd("AUTH", pass).Err
-		if authErr != nil {
-			return errors.New("Invalid Redis password")
-		}
+// startServices runs the endpoint, and the identity, profile and recipe
+// services it calls, in this process with in-memory storage. It returns the
+// address of the endpoint service.
+func startServices() (string, []*grpc.Server, error) {
+	logObj := logger.NewLogger("test", "", 8080, false, logrus.ErrorLevel)
+	var servers []*grpc.Server
+
+	identityServer, identityAddr, err := serve(func(s *grpc.Server) {
+		pb.RegisterIdentityServiceServer(s, &identity.Server{
+			Logger:     logObj,
+			Sessions:   identity.NewMemorySessionStore(),
+			AccessTTL:  15 * time.Minute,
+			RefreshTTL: 24 * time.Hour,
+		})
+	})
+	if err != nil {
+		return "", servers, err
 	}
-	exists, err := redisClient.Cmd("EXISTS", key).Bool()
+	servers = append(servers, identityServer)
+
+	profileServer, profileAddr, err := serve(func(s *grpc.Server) {
+		pb.RegisterProfileServiceServer(s, &profile.Server{
+			Logger:     logObj,
+			Repository: profile.NewMemoryProfileRepository(),
+		})
+	})
 	if err != nil {
-		errorString := fmt.Sprintf("Could not check if redis key %s exists. Error: %v", key, err)
-		return errors.New(errorString)
-	}
-	if exists != shouldExist {
-		var existsString string
-		if shouldExist {
-			existsString = "not exist"
-		} else {
-			existsString = "exist"
-		}
-		errorString := fmt.Sprintf("Key \"%s\" for session token does %s", key, existsString)
-		return errors.New(errorString)
+		return "", servers, err
+	}
+	servers = append(servers, profileServer)
+
+	recipes := recipestore.NewMemoryRecipeStore(testRecipes()...)
+	recipes.PutRecipePacks(testRecipePacks()...)
+	recipeServer, recipeAddr, err := serve(func(s *grpc.Server) {
+		pb.RegisterRecipeServiceServer(s, &recipestore.Server{
+			Logger:     logObj,
+			Repository: recipes,
+		})
+	})
+	if err != nil {
+		return "", servers, err
+	}
+	servers = append(servers, recipeServer)
+
+	endpointInstance := &endpoint.Server{Logger: logObj}
+	if endpointInstance.IdentityPool, err = util.NewConnPool(logObj, "identity", identityAddr.IP.String(), identityAddr.Port, false, "", 2); err != nil {
+		return "", servers, err
+	}
+	if endpointInstance.ProfilePool, err = util.NewConnPool(logObj, "profile", profileAddr.IP.String(), profileAddr.Port, false, "", 2); err != nil {
+		return "", servers, err
 	}
-	return nil
+	if endpointInstance.RecipePool, err = util.NewConnPool(logObj, "recipe", recipeAddr.IP.String(), recipeAddr.Port, false, "", 2); err != nil {
+		return "", servers, err
+	}
+	endpointServer, endpointAddr, err := serve(func(s *grpc.Server) {
+		pb.RegisterEndpointServiceServer(s, endpointInstance)
+	})
+	if err != nil {
+		return "", servers, err
+	}
+	servers = append(servers, endpointServer)
+
+	return endpointAddr.String(), servers, nil
 }
 
 func TestMain(m *testing.M) {
-	flag.Parse()
-	conn, err := grpc.Dial(*serverAddr, []grpc.DialOption{grpc.WithInsecure()}...)
+	addr, servers, err := startServices()
 	if err != nil {
-		fmt.Println("fail to dial: %v", err)
+		fmt.Printf("cannot start services: %v\n", err)
 		os.Exit(-1)
 	}
-
-	redisClient, err = redis.DialTimeout("tcp", *redisHost, time.Second*60)
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
 	if err != nil {
-		grpclog.Fatalf("Cannot connect to redis server")
+		fmt.Printf("fail to dial: %v\n", err)
+		os.Exit(-1)
 	}
-
-	defer conn.Close()
 	client = pb.NewEndpointServiceClient(conn)
-	os.Exit(m.Run())
+
+	code := m.Run()
+	conn.Close()
+	for _, server := range servers {
+		server.Stop()
+	}
+	os.Exit(code)
 }
@@ -14,11 +14,13 @@ import (
 	"flag"
 	"fmt"
 	"net"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	endpointutil "github.com/theorangechefco/cts/endpoint"
 	pb "github.com/theorangechefco/cts/go-protos"
 	"github.com/theorangechefco/cts/go-shared-libs/cts/logger"
+	"github.com/theorangechefco/cts/go-shared-libs/cts/util"
 	"google.golang.org/grpc"
 
 	"google.golang.org/grpc/credentials"
@@ -49,6 +51,12 @@ var (
 	profileCertFile = flag.String("profile_cert_file", "keys/server1.pem", "The profile service TLS cert file")
 	profilePoolSize = flag.Int("profile_service_pool_size", 50, "The profile service pool size")
 
+	verifyTokens      = flag.Bool("verify_tokens", true, "Verify signed session tokens locally instead of looking them up through the identity service")
+	tokenSyncInterval = flag.Duration("token_sync_interval", 30*time.Second, "How often signing keys and closed sessions are fetched from the identity service")
+
+	oidcIssuers      = flag.String("oidc_issuers", "", "JSON file listing the OpenID Connect providers users can sign in with. If left blank, FederatedLogin is disabled")
+	oidcKeySetMaxAge = flag.Duration("oidc_key_set_max_age", time.Hour, "How long OpenID Connect providers' signing keys are cached")
+
 
 	stdErrLog   = flag.Bool("stderr_log", true, "Log to STDERR")
 	fluentdHost = flag.String("fluentd_host", "", "Fluentd agent hostname. If left blank, fluentd logging disabled")
@@ -120,6 +128,32 @@ func main() {
 		"tag":   "identity"},
 		fmt.Sprintf("Connected to Identity Service at: %s", *identityServerAddr))
 
+	if *verifyTokens {
+		verifier := util.NewTokenVerifier()
+		verifierClient := pb.NewIdentityServiceClient(identityConn)
+		go verifier.Poll(endpointServerInstance.Logger, *tokenSyncInterval, func() error {
+			return verifier.Sync(verifierClient)
+		})
+		endpointServerInstance.Verifier = verifier
+	}
+
+	if *oidcIssuers != "" {
+		issuers, err := util.LoadOIDCIssuers(*oidcIssuers)
+		if err != nil {
+			endpointServerInstance.Logger.Fatal(logrus.Fields{
+				"phase": "startup",
+				"event": "setup",
+				"tag":   "idtoken"},
+				fmt.Sprintf("Cannot load OpenID Connect issuers: %v", err))
+		}
+		endpointServerInstance.IDTokens = util.NewIDTokenVerifier(issuers, *oidcKeySetMaxAge)
+		endpointServerInstance.Logger.Info(logrus.Fields{
+			"phase": "startup",
+			"event": "setup",
+			"tag":   "idtoken"},
+			fmt.Sprintf("Accepting ID tokens from %d OpenID Connect issuers", len(issuers)))
+	}
+
 	profileServerOpts := getDialOpts(*profileTLS, *profileCertFile, "profile", endpointServerInstance.Logger)
 	profileConn, err := grpc.Dial(*profileServerAddr, profileServerOpts...)
 	if err != nil {
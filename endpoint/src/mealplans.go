@@ -0,0 +1,228 @@
+/*
+// ----------------------------------------------------------------------------
+// mealplans.go
+// Countertop Server Endpoint Weekly Meal Plan RPCs
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package endpoint
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	pb "github.com/theorangechefco/cts/go-protos"
+	"github.com/theorangechefco/cts/go-shared-libs/cts/util"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// Generates and stores a new weekly meal plan for the authenticated user
+func (s *Server) GenerateMealPlan(ctx context.Context, null *pb.EmptyRequest) (*pb.WeeklyMealPlan, error) {
+	identConn, identClient, identPoolErr := s.getIdentityClient("GenerateMealPlan")
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+	_, userID, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "GenerateMealPlan", true)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, target, err := s.getProfileAndCalorieTarget(userID, "GenerateMealPlan")
+	if err != nil {
+		return nil, err
+	}
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("GenerateMealPlan")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for meal plan for user: %s. Error: %v", userID.Uuid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	plan, planErr := recipeClient.GenerateMealPlan(context.Background(), &pb.MealPlanRequest{
+		Useruuid:           userID.Uuid,
+		Dietaryprofile:     profile.Dietaryprofile,
+		Dietaryrestriction: profile.Dietaryrestriction,
+		Dailycalories:      target.Dailycalories,
+	})
+	if planErr != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "create",
+			"tag":   "recipestore",
+			"rpc":   "GenerateMealPlan"},
+			fmt.Sprintf("Cannot generate meal plan for user with UUID %s. Error: %v", userID.Uuid, planErr))
+		switch grpc.Code(planErr) {
+		case codes.NotFound, codes.InvalidArgument, codes.FailedPrecondition:
+			return nil, planErr
+		}
+		return nil, grpc.Errorf(codes.Internal, "Cannot generate meal plan.")
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "create",
+		"tag":   "recipestore",
+		"rpc":   "GenerateMealPlan"},
+		fmt.Sprintf("Generated meal plan for user with UUID %s", userID.Uuid))
+	return plan, nil
+}
+
+func (s *Server) GetMealPlan(ctx context.Context, null *pb.EmptyRequest) (*pb.WeeklyMealPlan, error) {
+	identConn, identClient, identPoolErr := s.getIdentityClient("GetMealPlan")
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+	_, userID, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "GetMealPlan", true)
+	if err != nil {
+		return nil, err
+	}
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("GetMealPlan")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for meal plan for user: %s. Error: %v", userID.Uuid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	plan, planErr := recipeClient.GetMealPlan(context.Background(), userID)
+	if planErr != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "recipestore",
+			"rpc":   "GetMealPlan"},
+			fmt.Sprintf("Cannot fetch meal plan for user with UUID %s. Error: %v", userID.Uuid, planErr))
+		if grpc.Code(planErr) == codes.NotFound {
+			return nil, grpc.Errorf(codes.NotFound, "Meal plan not found.")
+		}
+		return nil, grpc.Errorf(codes.Internal, "Cannot fetch meal plan.")
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "fetch",
+		"tag":   "recipestore",
+		"rpc":   "GetMealPlan"},
+		fmt.Sprintf("Successfully fetched meal plan for user with UUID %s", userID.Uuid))
+	return plan, nil
+}
+
+// Swaps a single meal in the authenticated user's plan, identified by day
+// and meal index
+func (s *Server) RegenerateMeal(ctx context.Context, regenerateRequest *pb.RegenerateMealRequest) (*pb.WeeklyMealPlan, error) {
+	identConn, identClient, identPoolErr := s.getIdentityClient("RegenerateMeal")
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+	_, userID, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "RegenerateMeal", true)
+	if err != nil {
+		return nil, err
+	}
+
+	profileConn, profileClient, profilePoolErr := s.getProfileClient("RegenerateMeal")
+	defer s.ProfilePool.CarefullyPut(profileConn, &profilePoolErr)
+	if profilePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from profile pool when fetching profile for user: %s. Error: %v", userID.Uuid, profilePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	profile, profileErr := profileClient.GetProfileInfoByUUID(context.Background(), userID)
+	if profileErr != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "profile",
+			"rpc":   "RegenerateMeal"},
+			fmt.Sprintf("Cannot fetch profile for user with UUID %s. Error: %v", userID.Uuid, profileErr))
+		if grpc.Code(profileErr) == codes.NotFound {
+			return nil, profileErr
+		}
+		return nil, grpc.Errorf(codes.Internal, "Cannot fetch profile for user %s.", userID.Uuid)
+	}
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("RegenerateMeal")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for meal plan for user: %s. Error: %v", userID.Uuid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	plan, planErr := recipeClient.RegenerateMeal(context.Background(), &pb.RegenerateMealRequest{
+		Useruuid:           userID.Uuid,
+		Day:                regenerateRequest.Day,
+		Meal:               regenerateRequest.Meal,
+		Dietaryprofile:     profile.Dietaryprofile,
+		Dietaryrestriction: profile.Dietaryrestriction,
+	})
+	if planErr != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "update",
+			"tag":   "recipestore",
+			"rpc":   "RegenerateMeal"},
+			fmt.Sprintf("Cannot regenerate meal %d on day %d for user with UUID %s. Error: %v", regenerateRequest.Meal, regenerateRequest.Day, userID.Uuid, planErr))
+		switch grpc.Code(planErr) {
+		case codes.NotFound, codes.InvalidArgument, codes.FailedPrecondition:
+			return nil, planErr
+		}
+		return nil, grpc.Errorf(codes.Internal, "Cannot regenerate meal.")
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "update",
+		"tag":   "recipestore",
+		"rpc":   "RegenerateMeal"},
+		fmt.Sprintf("Regenerated meal %d on day %d for user with UUID %s", regenerateRequest.Meal, regenerateRequest.Day, userID.Uuid))
+	return plan, nil
+}
+
+func (s *Server) getProfileAndCalorieTarget(userID *pb.UserId, rpc string) (*pb.Profile, *pb.CalorieTarget, error) {
+	profileConn, profileClient, profilePoolErr := s.getProfileClient(rpc)
+	defer s.ProfilePool.CarefullyPut(profileConn, &profilePoolErr)
+	if profilePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from profile pool when fetching profile for user: %s. Error: %v", userID.Uuid, profilePoolErr)
+		return nil, nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	profile, profileErr := profileClient.GetProfileInfoByUUID(context.Background(), userID)
+	if profileErr != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "profile",
+			"rpc":   rpc},
+			fmt.Sprintf("Cannot fetch profile for user with UUID %s. Error: %v", userID.Uuid, profileErr))
+		if grpc.Code(profileErr) == codes.NotFound {
+			return nil, nil, profileErr
+		}
+		return nil, nil, grpc.Errorf(codes.Internal, "Cannot fetch profile for user %s.", userID.Uuid)
+	}
+
+	target, targetErr := profileClient.GetCalorieTarget(context.Background(), userID)
+	if targetErr != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "profile",
+			"rpc":   rpc},
+			fmt.Sprintf("Cannot fetch calorie target for user with UUID %s. Error: %v", userID.Uuid, targetErr))
+		switch grpc.Code(targetErr) {
+		case codes.NotFound, codes.FailedPrecondition:
+			return nil, nil, targetErr
+		}
+		return nil, nil, grpc.Errorf(codes.Internal, "Cannot fetch calorie target for user %s.", userID.Uuid)
+	}
+	return profile, target, nil
+}
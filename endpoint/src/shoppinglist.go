@@ -0,0 +1,70 @@
+/*
+// ----------------------------------------------------------------------------
+// shoppinglist.go
+// Countertop Server Endpoint Shopping List RPC
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package endpoint
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	pb "github.com/theorangechefco/cts/go-protos"
+	"github.com/theorangechefco/cts/go-shared-libs/cts/util"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// Builds one consolidated shopping list from the requested recipes and, if
+// asked for, the authenticated user's stored meal plan
+func (s *Server) GetShoppingList(ctx context.Context, shoppingListRequest *pb.ShoppingListRequest) (*pb.ShoppingList, error) {
+	identConn, identClient, identPoolErr := s.getIdentityClient("GetShoppingList")
+	defer s.IdentityPool.CarefullyPut(identConn, &identPoolErr)
+	if identPoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching connection from identity pool. Error: %v", identPoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+	_, userID, err := util.AuthenticateWithVerifier(s.Logger, s.Verifier, identClient, ctx, "GetShoppingList", true)
+	if err != nil {
+		return nil, err
+	}
+
+	recipeConn, recipeClient, recipePoolErr := s.getRecipeClient("GetShoppingList")
+	defer s.RecipePool.CarefullyPut(recipeConn, &recipePoolErr)
+	if recipePoolErr != nil {
+		errorMsg := fmt.Sprintf("Problem fetching recipe service connection from pool for shopping list for user: %s. Error: %v", userID.Uuid, recipePoolErr)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	shoppingList, listErr := recipeClient.GetShoppingList(context.Background(), &pb.ShoppingListRequest{
+		Recipeids:    shoppingListRequest.Recipeids,
+		Frommealplan: shoppingListRequest.Frommealplan,
+		Useruuid:     userID.Uuid,
+	})
+	if listErr != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "recipestore",
+			"rpc":   "GetShoppingList"},
+			fmt.Sprintf("Cannot build shopping list for user with UUID %s. Error: %v", userID.Uuid, listErr))
+		switch grpc.Code(listErr) {
+		case codes.NotFound, codes.InvalidArgument:
+			return nil, listErr
+		}
+		return nil, grpc.Errorf(codes.Internal, "Cannot build shopping list.")
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "fetch",
+		"tag":   "recipestore",
+		"rpc":   "GetShoppingList"},
+		fmt.Sprintf("Built shopping list with %d aisles for user with UUID %s", len(shoppingList.Aisles), userID.Uuid))
+	return shoppingList, nil
+}
@@ -0,0 +1,140 @@
+/*
+// ----------------------------------------------------------------------------
+// buffer.go
+// Countertop Server Event Recording Write Buffer
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+var ErrBufferClosed = errors.New("event buffer closed")
+
+type pendingEvent struct {
+	record *EventRecord
+	done   chan error
+}
+
+// EventBuffer queues events and writes them to its sink in batches of up to
+// batchSize, or whatever has queued up once flushInterval passes. The queue
+// is bounded: once it is full, because the sink has fallen behind, Enqueue
+// blocks until the sink catches up, pushing back on the clients sending
+// events.
+type EventBuffer struct {
+	Sink EventSink
+	// Written, when set, is called with every batch the sink has stored,
+	// from the goroutine writing batches.
+	Written func(records []*EventRecord)
+
+	batchSize     int
+	flushInterval time.Duration
+	queue         chan *pendingEvent
+	// Held for reading while enqueueing, and for writing to close the queue
+	mu      sync.RWMutex
+	closed  bool
+	flushed chan struct{}
+}
+
+func NewEventBuffer(sink EventSink, queueSize int, batchSize int, flushInterval time.Duration) *EventBuffer {
+	b := &EventBuffer{
+		Sink:          sink,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		queue:         make(chan *pendingEvent, queueSize),
+		flushed:       make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Enqueue queues the record, waiting for room in the queue until the
+// context is done. The returned channel receives the result of writing the
+// record's batch.
+func (b *EventBuffer) Enqueue(ctx context.Context, record *EventRecord) (<-chan error, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return nil, ErrBufferClosed
+	}
+	pending := &pendingEvent{record: record, done: make(chan error, 1)}
+	select {
+	case b.queue <- pending:
+		return pending.done, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Write queues the record and waits for it to be written.
+func (b *EventBuffer) Write(ctx context.Context, record *EventRecord) error {
+	done, err := b.Enqueue(ctx, record)
+	if err != nil {
+		return err
+	}
+	return <-done
+}
+
+func (b *EventBuffer) run() {
+	defer close(b.flushed)
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*pendingEvent, 0, b.batchSize)
+	for {
+		select {
+		case pending, ok := <-b.queue:
+			if !ok {
+				b.flush(batch)
+				return
+			}
+			batch = append(batch, pending)
+			if len(batch) < b.batchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+		b.flush(batch)
+		batch = batch[:0]
+	}
+}
+
+func (b *EventBuffer) flush(batch []*pendingEvent) {
+	if len(batch) == 0 {
+		return
+	}
+	records := make([]*EventRecord, len(batch))
+	for i, pending := range batch {
+		records[i] = pending.record
+	}
+	err := b.Sink.Write(records...)
+	if err == nil && b.Written != nil {
+		b.Written(records)
+	}
+	for _, pending := range batch {
+		pending.done <- err
+	}
+}
+
+// Close stops accepting events, writes out everything already queued and
+// closes the sink.
+func (b *EventBuffer) Close() error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+
+	<-b.flushed
+	return b.Sink.Close()
+}
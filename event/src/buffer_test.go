@@ -0,0 +1,137 @@
+/*
+// ----------------------------------------------------------------------------
+// buffer_test.go
+// Countertop Server Event Recording Write Buffer Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	event "github.com/theorangechefco/cts/event"
+	"golang.org/x/net/context"
+)
+
+// batchSink records the size of every batch written to it, failing batches
+// that contain the model in failModel.
+type batchSink struct {
+	mu        sync.Mutex
+	batches   []int
+	failModel string
+	closed    bool
+}
+
+func (s *batchSink) Write(records ...*event.EventRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, len(records))
+	for _, record := range records {
+		if record.Event.Model == s.failModel {
+			return errors.New("write failed")
+		}
+	}
+	return nil
+}
+
+func (s *batchSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func TestEventBufferBatches(t *testing.T) {
+	sink := &batchSink{}
+	buffer := event.NewEventBuffer(sink, 10, 3, time.Hour)
+
+	var pending []<-chan error
+	for i := 0; i < 7; i++ {
+		done, err := buffer.Enqueue(context.Background(), testRecord("Countertop"))
+		if err != nil {
+			t.Fatalf("Enqueue(_) = _, %v", err)
+		}
+		pending = append(pending, done)
+	}
+	for i, done := range pending[:6] {
+		if err := <-done; err != nil {
+			t.Errorf("event %d: write = %v", i, err)
+		}
+	}
+
+	if err := buffer.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if err := <-pending[6]; err != nil {
+		t.Errorf("event 6: write = %v, want flush on close", err)
+	}
+	if len(sink.batches) != 3 || sink.batches[0] != 3 || sink.batches[1] != 3 || sink.batches[2] != 1 {
+		t.Errorf("batches = %v, want [3 3 1]", sink.batches)
+	}
+	if !sink.closed {
+		t.Errorf("Close() did not close the sink")
+	}
+	if _, err := buffer.Enqueue(context.Background(), testRecord("Countertop")); err != event.ErrBufferClosed {
+		t.Errorf("Enqueue(_) after Close() = _, %v, want %v", err, event.ErrBufferClosed)
+	}
+}
+
+func TestEventBufferFlushInterval(t *testing.T) {
+	sink := &batchSink{failModel: "Broken"}
+	buffer := event.NewEventBuffer(sink, 10, 100, 10*time.Millisecond)
+	defer buffer.Close()
+
+	if err := buffer.Write(context.Background(), testRecord("Countertop")); err != nil {
+		t.Errorf("Write(_) = %v", err)
+	}
+	if err := buffer.Write(context.Background(), testRecord("Broken")); err == nil {
+		t.Errorf("Write(broken) = nil, want error")
+	}
+}
+
+// slowSink holds up every write until release is closed.
+type slowSink struct {
+	release chan struct{}
+}
+
+func (s *slowSink) Write(records ...*event.EventRecord) error {
+	<-s.release
+	return nil
+}
+
+func (s *slowSink) Close() error { return nil }
+
+func TestEventBufferBackpressure(t *testing.T) {
+	sink := &slowSink{release: make(chan struct{})}
+	buffer := event.NewEventBuffer(sink, 1, 1, time.Hour)
+
+	// The first event is stuck in the sink and the second fills the queue,
+	// so the third waits until its context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var pending []<-chan error
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		var done <-chan error
+		if done, err = buffer.Enqueue(ctx, testRecord("Countertop")); err == nil {
+			pending = append(pending, done)
+		}
+	}
+	if err != context.DeadlineExceeded {
+		t.Errorf("Enqueue(_) on a full queue = _, %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	close(sink.release)
+	buffer.Close()
+	for i, done := range pending {
+		if err := <-done; err != nil {
+			t.Errorf("event %d: write = %v", i, err)
+		}
+	}
+}
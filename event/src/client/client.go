@@ -13,6 +13,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"time"
 
 	"github.com/k0kubun/pp"
 	pb "github.com/theorangechefco/cts/go-protos"
@@ -38,6 +39,8 @@ func hustleAndFlow(client pb.EventServiceClient) {
 	fmt.Println("Created account")
 	event := pb.Event{
 		Version:         0,
+		Name:            "app_open",
+		Timestamp:       &pb.Timestamp{Seconds: time.Now().Unix()},
 		Apprelease:      "testrelease",
 		Appversion:      "testversion",
 		Carrier:         "Tmo",
@@ -11,15 +11,17 @@
 package event
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"strings"
 	"time"
 
 	"github.com/Sirupsen/logrus"
-	"github.com/gocql/gocql"
 	"github.com/golang/blog/content/context/userip"
 	"github.com/theorangechefco/cts/go-shared-libs/cts/logger"
+	"github.com/theorangechefco/cts/go-shared-libs/cts/util"
 
 	pb "github.com/theorangechefco/cts/go-protos"
 	"golang.org/x/net/context"
@@ -28,18 +30,44 @@ import (
 	"google.golang.org/grpc/metadata"
 )
 
-const insertTemplate string = `INSERT INTO events (id, version, apprelease, appversion,
-	 carrier, city, country, devicemodel, manufacturer, model , osversion , operatingsystem , radio,
-	 region, screenheight, screenwidth, wifi, createdat, payload) VALUES
-	 (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
-
 type Server struct {
 	IdentityClient pb.IdentityServiceClient
-	Session        *gocql.Session
+	// Verifier, if set, checks signed session tokens locally
+	Verifier *util.TokenVerifier
+	Buffer   *EventBuffer
+	// Reader is nil when the sink cannot be read back
+	Reader EventReader
+	// Analysts may query every user's events, other users only their own
+	Analysts map[string]bool
+	// Registry checks payloads against the schema for their event type
+	Registry *EventRegistry
+	// Quarantine takes events with invalid payloads, which are rejected
+	// when it is nil
+	Quarantine EventSink
+	// Stats keeps daily counters of recorded events, when set
+	Stats StatsStore
+	// StatsQueue, when set, adds written batches to Stats without holding
+	// up the buffer
+	StatsQueue *StatsQueue
+	// GeoIP locates clients by their address, when set
+	GeoIP *GeoIPDatabase
+	// TruncateIP keeps only the network part of client addresses, which is
+	// then all that is looked up and stored
+	TruncateIP bool
+	// TrustedProxies are the proxies whose x-forwarded-for metadata is
+	// believed when working out client addresses
+	TrustedProxies []*net.IPNet
 	Logger         *logger.CtsLogger
 }
 
-func (s *Server) WriteEvent(ctx context.Context, event *pb.Event) (*pb.EmptyRequest, error) {
+// Longest range of days GetEventStats returns at once.
+const maxStatsDays = 366
+
+var errQueryLimit = errors.New("query limit reached")
+
+// authenticate looks up the user ID for the session token in the request
+// metadata.
+func (s *Server) authenticate(ctx context.Context, rpc string) (string, error) {
 	md, _ := metadata.FromContext(ctx)
 	token, ok := md["token"]
 	available := false
@@ -50,54 +78,350 @@ func (s *Server) WriteEvent(ctx context.Context, event *pb.Event) (*pb.EmptyRequ
 			s.Logger.Error(logrus.Fields{
 				"phase": "authorization",
 				"event": "connection",
-				"tag":   "missingtoken"},
+				"tag":   "missingtoken",
+				"rpc":   rpc},
 				fmt.Sprintf("Token from %s not provided, access denied", ip.String()))
 		} else {
 			s.Logger.Error(logrus.Fields{
 				"phase": "authorization",
 				"event": "connection",
-				"tag":   "missingtoken"},
+				"tag":   "missingtoken",
+				"rpc":   rpc},
 				"Token from not provided, access denied")
 		}
 
-		return nil, grpc.Errorf(codes.Unauthenticated, "Valid session token not provided, access denied.")
+		return "", grpc.Errorf(codes.Unauthenticated, "Valid session token not provided, access denied.")
 	}
 
-	userID, err := s.IdentityClient.LookupSessionToken(ctx, &pb.SessionToken{Id: strings.Join(token, "")})
+	userID, err := util.LookupUserID(s.Verifier, s.IdentityClient, ctx, strings.Join(token, ""))
 	if err != nil {
 		if available {
 			s.Logger.Error(logrus.Fields{
 				"phase": "authorization",
 				"event": "connection",
-				"tag":   "validtoken"},
+				"tag":   "validtoken",
+				"rpc":   rpc},
 				fmt.Sprintf("Valid token from %s not provided, access denied", ip.String()))
 		} else {
 			s.Logger.Error(logrus.Fields{
 				"phase": "authorization",
 				"event": "connection",
-				"tag":   "validtoken"},
+				"tag":   "validtoken",
+				"rpc":   rpc},
 				"Valid token from not provided, access denied")
 		}
-		return nil, grpc.Errorf(codes.Unauthenticated, "Valid session token not provided, access denied.")
-	}
-	ID := gocql.TimeUUID()
-	err = s.Session.Query(insertTemplate, ID, event.Version, event.Apprelease,
-		event.Appversion, event.Carrier, event.City, event.Country,
-		event.Devicemodel, event.Manufacturer, event.Model, event.Osversion,
-		event.Operatingsystem, event.Radio, event.Region, event.Screenheight,
-		event.Screenwidth, event.Wifi, time.Now().Unix(), event.JsonPayload).Exec()
+		return "", grpc.Errorf(codes.Unauthenticated, "Valid session token not provided, access denied.")
+	}
+	return userID.Uuid, nil
+}
+
+func (s *Server) WriteEvent(ctx context.Context, event *pb.Event) (*pb.EmptyRequest, error) {
+	userID, err := s.authenticate(ctx, "WriteEvent")
 	if err != nil {
+		return nil, err
+	}
+	record := NewEventRecord(userID, event)
+	record.IP, record.Location = s.locate(ctx, "WriteEvent")
+	if admitted, err := s.admit(record, "WriteEvent"); err != nil {
+		return nil, err
+	} else if !admitted {
+		return &pb.EmptyRequest{}, nil
+	}
+	if err := s.Buffer.Write(ctx, record); err != nil {
 		s.Logger.Error(logrus.Fields{
 			"phase": "persist",
 			"event": "connection",
-			"tag":   "cassandra"},
-			fmt.Sprintf("Could not write to Cassandra, error %v", err))
-		return nil, grpc.Errorf(codes.Internal, "Unable to write to Cassandra %v", err)
+			"tag":   "sink",
+			"rpc":   "WriteEvent"},
+			fmt.Sprintf("Could not record event, error %v", err))
+		return nil, grpc.Errorf(codes.Internal, "Unable to record event %v", err)
 	}
 	s.Logger.Info(logrus.Fields{
 		"phase": "persist",
 		"event": "connection",
-		"tag":   "cassandra"},
-		fmt.Sprintf("Wrote entry into Cassandra, ID %s for user with ID %s", ID, userID))
+		"tag":   "sink",
+		"rpc":   "WriteEvent"},
+		fmt.Sprintf("Recorded event, ID %s for user with ID %s", record.ID, userID))
 	return &pb.EmptyRequest{}, nil
 }
+
+// WriteEvents records a stream of events from a single authenticated
+// client. Events are queued as they arrive and written in batches; once the
+// client closes the stream, the response counts the events that were
+// recorded and those that could not be.
+func (s *Server) WriteEvents(stream pb.EventService_WriteEventsServer) error {
+	ctx := stream.Context()
+	userID, err := s.authenticate(ctx, "WriteEvents")
+	if err != nil {
+		return err
+	}
+
+	// Every event on the stream comes from the same address
+	ip, location := s.locate(ctx, "WriteEvents")
+
+	var pending []<-chan error
+	var rejected, quarantined int32
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			s.Logger.Error(logrus.Fields{
+				"phase": "persist",
+				"event": "connection",
+				"tag":   "stream",
+				"rpc":   "WriteEvents"},
+				fmt.Sprintf("Event stream for user with ID %s failed after %d events, error %v", userID, len(pending)+int(rejected), err))
+			return err
+		}
+		record := NewEventRecord(userID, event)
+		record.IP, record.Location = ip, location
+		if admitted, err := s.admit(record, "WriteEvents"); err != nil {
+			rejected++
+			continue
+		} else if !admitted {
+			quarantined++
+			continue
+		}
+		done, err := s.Buffer.Enqueue(ctx, record)
+		if err != nil {
+			rejected++
+			continue
+		}
+		pending = append(pending, done)
+	}
+
+	// Quarantined events were stored, if not with the rest, and are reported
+	// apart from the accepted ones
+	var accepted int32
+	for _, done := range pending {
+		if err := <-done; err != nil {
+			rejected++
+		} else {
+			accepted++
+		}
+	}
+
+	fields := logrus.Fields{
+		"phase": "persist",
+		"event": "connection",
+		"tag":   "sink",
+		"rpc":   "WriteEvents"}
+	message := fmt.Sprintf("Recorded %d events, quarantined %d, rejected %d for user with ID %s",
+		accepted, quarantined, rejected, userID)
+	if rejected > 0 {
+		s.Logger.Error(fields, message)
+	} else {
+		s.Logger.Info(fields, message)
+	}
+	return stream.SendAndClose(&pb.WriteEventsResponse{Accepted: accepted, Quarantined: quarantined, Rejected: rejected})
+}
+
+// locate returns the client's address, truncated if the server is set to,
+// and where the address is, as far as the GeoIP database knows.
+func (s *Server) locate(ctx context.Context, rpc string) (string, Location) {
+	ip := ClientIP(ctx, s.TrustedProxies)
+	if ip == nil {
+		return "", Location{}
+	}
+	if s.TruncateIP {
+		ip = TruncateIP(ip)
+	}
+	if s.GeoIP == nil {
+		return ip.String(), Location{}
+	}
+
+	location, err := s.GeoIP.Lookup(ip)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "geoip",
+			"tag":   "lookup",
+			"rpc":   rpc},
+			fmt.Sprintf("Could not look up location of %s, error %v", ip, err))
+	}
+	if location == nil {
+		return ip.String(), Location{}
+	}
+	return ip.String(), *location
+}
+
+// admit checks the record's payload against the registry. It reports
+// whether the record should go on to the sink: records of unknown types are
+// counted by the registry and let through, invalid ones are quarantined or,
+// without a quarantine sink, rejected with an error.
+func (s *Server) admit(record *EventRecord, rpc string) (bool, error) {
+	err := s.Registry.Validate(record.Event)
+	if err == nil || err == ErrUnknownEventType {
+		return true, nil
+	}
+
+	if s.Quarantine == nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "validation",
+			"event": "payload",
+			"tag":   "rejected",
+			"rpc":   rpc},
+			fmt.Sprintf("Rejected %q event for user with ID %s, %v", record.Event.Name, record.UserID, err))
+		return false, grpc.Errorf(codes.InvalidArgument, "Invalid %q event payload: %v", record.Event.Name, err)
+	}
+	if qerr := s.Quarantine.Write(record); qerr != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "validation",
+			"event": "payload",
+			"tag":   "quarantine",
+			"rpc":   rpc},
+			fmt.Sprintf("Could not quarantine event, error %v", qerr))
+		return false, grpc.Errorf(codes.Internal, "Unable to record event %v", qerr)
+	}
+	s.Logger.Error(logrus.Fields{
+		"phase": "validation",
+		"event": "payload",
+		"tag":   "quarantine",
+		"rpc":   rpc},
+		fmt.Sprintf("Quarantined %q event, ID %s for user with ID %s, %v", record.Event.Name, record.ID, record.UserID, err))
+	return false, nil
+}
+
+// QueryEvents streams the recorded events matching the request, up to its
+// limit if it has one.
+func (s *Server) QueryEvents(request *pb.EventQuery, stream pb.EventService_QueryEventsServer) error {
+	userID, err := s.authenticate(stream.Context(), "QueryEvents")
+	if err != nil {
+		return err
+	}
+	if s.Reader == nil {
+		return grpc.Errorf(codes.Unimplemented, "Events cannot be queried with this sink")
+	}
+
+	query := eventQueryFromProto(request)
+	if !s.Analysts[userID] {
+		if query.UserID == "" {
+			query.UserID = userID
+		} else if query.UserID != userID {
+			s.Logger.Error(logrus.Fields{
+				"phase": "authorization",
+				"event": "query",
+				"tag":   "analyst",
+				"rpc":   "QueryEvents"},
+				fmt.Sprintf("User with ID %s may not query events of user with ID %s", userID, query.UserID))
+			return grpc.Errorf(codes.PermissionDenied, "Only analysts may query other users' events.")
+		}
+	}
+	if !query.Start.IsZero() && !query.End.IsZero() && !query.Start.Before(query.End) {
+		return grpc.Errorf(codes.InvalidArgument, "Query start must be before its end.")
+	}
+
+	var sent int32
+	err = s.Reader.Read(query, func(record *EventRecord) error {
+		if err := stream.Send(RecordedEventProto(record)); err != nil {
+			return err
+		}
+		sent++
+		if request.Limit > 0 && sent >= request.Limit {
+			return errQueryLimit
+		}
+		return nil
+	})
+	if err != nil && err != errQueryLimit {
+		s.Logger.Error(logrus.Fields{
+			"phase": "query",
+			"event": "query",
+			"tag":   "sink",
+			"rpc":   "QueryEvents"},
+			fmt.Sprintf("Could not query events after sending %d, error %v", sent, err))
+		return grpc.Errorf(codes.Internal, "Unable to query events %v", err)
+	}
+	s.Logger.Info(logrus.Fields{
+		"phase": "query",
+		"event": "query",
+		"tag":   "sink",
+		"rpc":   "QueryEvents"},
+		fmt.Sprintf("Sent %d events to user with ID %s", sent, userID))
+	return nil
+}
+
+// CountEvents queues recorded events to be added to the daily counters. It
+// is called with every batch the buffer writes, and drops the batch rather
+// than wait when the stats queue is full.
+func (s *Server) CountEvents(records []*EventRecord) {
+	if s.StatsQueue == nil {
+		return
+	}
+	if !s.StatsQueue.Add(records) {
+		s.Logger.Warn(logrus.Fields{
+			"phase": "process",
+			"event": "stats",
+			"tag":   "counters"},
+			fmt.Sprintf("Stats queue full, dropped %d events from the daily counters, %d batches dropped so far", len(records), s.StatsQueue.Dropped()))
+	}
+}
+
+// CountFailed logs a batch the stats store could not add to the daily
+// counters.
+func (s *Server) CountFailed(records []*EventRecord, err error) {
+	s.Logger.Error(logrus.Fields{
+		"phase": "process",
+		"event": "stats",
+		"tag":   "counters"},
+		fmt.Sprintf("Could not count %d events, error %v", len(records), err))
+}
+
+// GetEventStats returns the daily counters for each day in the request, for
+// analysts.
+func (s *Server) GetEventStats(ctx context.Context, request *pb.EventStatsRequest) (*pb.EventStatsResponse, error) {
+	userID, err := s.authenticate(ctx, "GetEventStats")
+	if err != nil {
+		return nil, err
+	}
+	if !s.Analysts[userID] {
+		s.Logger.Error(logrus.Fields{
+			"phase": "authorization",
+			"event": "stats",
+			"tag":   "analyst",
+			"rpc":   "GetEventStats"},
+			fmt.Sprintf("User with ID %s may not read event stats", userID))
+		return nil, grpc.Errorf(codes.PermissionDenied, "Only analysts may read event stats.")
+	}
+	if s.Stats == nil {
+		return nil, grpc.Errorf(codes.Unimplemented, "Event stats are not kept.")
+	}
+
+	first, err := time.Parse(dayFormat, request.Firstday)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "First day must be a date formatted as %s.", dayFormat)
+	}
+	last := first
+	if request.Lastday != "" {
+		if last, err = time.Parse(dayFormat, request.Lastday); err != nil {
+			return nil, grpc.Errorf(codes.InvalidArgument, "Last day must be a date formatted as %s.", dayFormat)
+		}
+	}
+	if last.Before(first) || last.Sub(first) >= maxStatsDays*24*time.Hour {
+		return nil, grpc.Errorf(codes.InvalidArgument, "Days must run forward, for at most %d days.", maxStatsDays)
+	}
+
+	days, err := s.Stats.Days(first, last)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "query",
+			"event": "stats",
+			"tag":   "counters",
+			"rpc":   "GetEventStats"},
+			fmt.Sprintf("Could not read event stats, error %v", err))
+		return nil, grpc.Errorf(codes.Internal, "Unable to read event stats %v", err)
+	}
+	response := &pb.EventStatsResponse{}
+	for _, day := range days {
+		response.Days = append(response.Days, &pb.DayStats{
+			Day:              day.Day,
+			Events:           day.Events,
+			Activeusers:      day.ActiveUsers,
+			Eventtypes:       day.EventTypes,
+			Appversions:      day.Appversions,
+			Operatingsystems: day.Operatingsystems,
+		})
+	}
+	return response, nil
+}
@@ -0,0 +1,78 @@
+/*
+// ----------------------------------------------------------------------------
+// export.go
+// Countertop Server Event Recording CSV & NDJSON Export
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Export formats.
+const (
+	ExportCSV    = "csv"
+	ExportNDJSON = "ndjson"
+)
+
+// EventExporter writes records as CSV, with a header row naming the columns,
+// or as newline delimited JSON. Both use the columns of the events table,
+// with times in seconds since the epoch.
+type EventExporter struct {
+	csv     *csv.Writer
+	json    *json.Encoder
+	started bool
+}
+
+func NewEventExporter(w io.Writer, format string) (*EventExporter, error) {
+	switch format {
+	case ExportCSV:
+		return &EventExporter{csv: csv.NewWriter(w)}, nil
+	case ExportNDJSON:
+		return &EventExporter{json: json.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("unknown export format %q, expected %q or %q", format, ExportCSV, ExportNDJSON)
+}
+
+func (e *EventExporter) Write(record *EventRecord) error {
+	row := newEventRow(record)
+	if e.json != nil {
+		return e.json.Encode(row)
+	}
+
+	if !e.started {
+		if err := e.csv.Write(eventColumns); err != nil {
+			return err
+		}
+		e.started = true
+	}
+	values := row.values()
+	fields := make([]string, len(values))
+	for i, value := range values {
+		fields[i] = fmt.Sprint(value)
+	}
+	return e.csv.Write(fields)
+}
+
+// Flush writes out anything buffered. A CSV export with no records still
+// gets its header row.
+func (e *EventExporter) Flush() error {
+	if e.json != nil {
+		return nil
+	}
+	if !e.started {
+		if err := e.csv.Write(eventColumns); err != nil {
+			return err
+		}
+		e.started = true
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
@@ -0,0 +1,173 @@
+/*
+// ----------------------------------------------------------------------------
+// filesink.go
+// Countertop Server Event Recording Local File Sink
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const rotatedSuffixFormat = "20060102-150405.000000000"
+
+// FileSink appends events as newline delimited JSON to a local file. Once
+// the file would grow past MaxBytes it is renamed with a timestamp suffix
+// and a new file is started.
+type FileSink struct {
+	Path string
+	// MaxBytes of zero never rotates the file.
+	MaxBytes int64
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func NewFileSink(path string, maxBytes int64) (*FileSink, error) {
+	sink := &FileSink{Path: path, MaxBytes: maxBytes}
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+func (f *FileSink) open() error {
+	file, err := os.OpenFile(f.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// A batch is written with a single write, and is never split across a
+// rotation.
+func (f *FileSink) Write(records ...*EventRecord) error {
+	var lines []byte
+	for _, record := range records {
+		line, err := json.Marshal(newEventRow(record))
+		if err != nil {
+			return err
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return fmt.Errorf("event file %s is closed", f.Path)
+	}
+	if f.MaxBytes > 0 && f.size > 0 && f.size+int64(len(lines)) > f.MaxBytes {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	written, err := f.file.Write(lines)
+	f.size += int64(written)
+	return err
+}
+
+// Callers must hold f.mu.
+func (f *FileSink) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	rotated := fmt.Sprintf("%s.%s", f.Path, time.Now().Format(rotatedSuffixFormat))
+	if err := os.Rename(f.Path, rotated); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// Read scans the rotated files, oldest first, and then the current file as
+// far as it had been written when Read was called.
+func (f *FileSink) Read(query *EventQuery, fn func(*EventRecord) error) error {
+	f.mu.Lock()
+	rotated, err := filepath.Glob(f.Path + ".*")
+	if err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	current, err := os.Open(f.Path)
+	size := f.size
+	f.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	defer current.Close()
+
+	// The timestamp suffixes sort in the order the files were rotated
+	sort.Strings(rotated)
+	for _, path := range rotated {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		err = readEvents(path, file, query, fn)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return readEvents(f.Path, io.LimitReader(current, size), query, fn)
+}
+
+func readEvents(path string, r io.Reader, query *EventQuery, fn func(*EventRecord) error) error {
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err == io.EOF && len(data) == 0 {
+			return nil
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		row := &eventRow{}
+		if err := json.Unmarshal(data, row); err != nil {
+			return fmt.Errorf("%s:%d: invalid event: %v", path, line, err)
+		}
+		record, err := row.record()
+		if err != nil {
+			return fmt.Errorf("%s:%d: invalid event: %v", path, line, err)
+		}
+		if !query.Matches(record) {
+			continue
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+func (f *FileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
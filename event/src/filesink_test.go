@@ -0,0 +1,141 @@
+/*
+// ----------------------------------------------------------------------------
+// filesink_test.go
+// Countertop Server Event Recording Local File Sink Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	event "github.com/theorangechefco/cts/event"
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+const testUserID = "d17eaf65-244a-4913-83d8-1583bb3cbbfd"
+
+func testRecord(model string) *event.EventRecord {
+	return &event.EventRecord{
+		ID:         gocql.TimeUUID(),
+		UserID:     testUserID,
+		Occurredat: time.Date(2015, 12, 9, 11, 59, 0, 0, time.UTC),
+		Createdat:  time.Date(2015, 12, 9, 12, 0, 0, 0, time.UTC),
+		Event: &pb.Event{
+			Version:     1,
+			Name:        "recipe_viewed",
+			Model:       model,
+			Wifi:        true,
+			JsonPayload: `{"screen": "recipes"}`,
+		},
+	}
+}
+
+func readLines(t *testing.T, path string) []map[string]interface{} {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := map[string]interface{}{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("%s: invalid line %q: %v", path, scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestFileSinkAppends(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventsink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.ndjson")
+
+	// Reopening the sink appends to the existing file
+	for _, model := range []string{"iPhone7,2", "Nexus 5"} {
+		sink, err := event.NewFileSink(path, 0)
+		if err != nil {
+			t.Fatalf("NewFileSink(_) = _, %v", err)
+		}
+		if err := sink.Write(testRecord(model)); err != nil {
+			t.Errorf("Write(_) = %v", err)
+		}
+		sink.Close()
+	}
+
+	lines := readLines(t, path)
+	if len(lines) != 2 {
+		t.Fatalf("%d events written, want 2", len(lines))
+	}
+	if lines[1]["model"] != "Nexus 5" || lines[1]["payload"] != `{"screen": "recipes"}` || lines[1]["createdat"] != float64(1449662400) {
+		t.Errorf("second event = %v", lines[1])
+	}
+	if lines[1]["userid"] != testUserID || lines[1]["name"] != "recipe_viewed" || lines[1]["occurredat"] != float64(1449662340) {
+		t.Errorf("second event = %v, want user, name and occurrence time", lines[1])
+	}
+}
+
+func TestNewEventRecord(t *testing.T) {
+	reported := &pb.Event{Name: "recipe_viewed", Timestamp: &pb.Timestamp{Seconds: 1449662340}}
+	record := event.NewEventRecord(testUserID, reported)
+	if record.UserID != testUserID || record.Event != reported {
+		t.Errorf("NewEventRecord(_) = %+v", record)
+	}
+	if record.Occurredat.Unix() != 1449662340 {
+		t.Errorf("Occurredat = %v, want the reported time", record.Occurredat)
+	}
+	if record.ID.Time().Unix() != record.Createdat.Unix() {
+		t.Errorf("ID time %v, want %v", record.ID.Time(), record.Createdat)
+	}
+
+	// Without a reported time the event happened when it was received
+	record = event.NewEventRecord(testUserID, &pb.Event{Name: "app_open"})
+	if !record.Occurredat.Equal(record.Createdat) {
+		t.Errorf("Occurredat = %v, want %v", record.Occurredat, record.Createdat)
+	}
+}
+
+func TestFileSinkRotates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventsink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.ndjson")
+
+	sink, err := event.NewFileSink(path, 1)
+	if err != nil {
+		t.Fatalf("NewFileSink(_) = _, %v", err)
+	}
+	defer sink.Close()
+	for _, model := range []string{"first", "second", "third"} {
+		if err := sink.Write(testRecord(model)); err != nil {
+			t.Errorf("Write(_) = %v", err)
+		}
+	}
+
+	rotated, err := filepath.Glob(path + ".*")
+	if err != nil || len(rotated) != 2 {
+		t.Errorf("rotated files = %v, %v, want 2", rotated, err)
+	}
+	if lines := readLines(t, path); len(lines) != 1 || lines[0]["model"] != "third" {
+		t.Errorf("current file = %v, want only the third event", lines)
+	}
+}
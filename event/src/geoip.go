@@ -0,0 +1,94 @@
+/*
+// ----------------------------------------------------------------------------
+// geoip.go
+// Countertop Server Event Recording GeoIP Lookups
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Location is where an IP address is, in English. Region is the ISO 3166-2
+// subdivision code, Country the ISO 3166-1 country code.
+type Location struct {
+	City    string
+	Region  string
+	Country string
+}
+
+// GeoIPDatabase looks up IP addresses in a MaxMind DB file, such as
+// GeoLite2 City. Lookups are safe for concurrent use.
+type GeoIPDatabase struct {
+	DatabaseType string
+
+	reader *maxminddb.Reader
+}
+
+// The parts of a GeoIP2 or GeoLite2 City record that are kept.
+type geoIPRecord struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+}
+
+// OpenGeoIPDatabase maps the file into memory and reads its metadata. Records
+// are only decoded when looked up, so corrupt ones fail their lookups.
+func OpenGeoIPDatabase(path string) (*GeoIPDatabase, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &GeoIPDatabase{DatabaseType: reader.Metadata.DatabaseType, reader: reader}, nil
+}
+
+func (db *GeoIPDatabase) Close() error {
+	return db.reader.Close()
+}
+
+// Lookup returns the location of the address, or nil if the database does
+// not know where it is.
+func (db *GeoIPDatabase) Lookup(ip net.IP) (*Location, error) {
+	if ip.To4() == nil && db.reader.Metadata.IPVersion == 4 {
+		return nil, nil
+	}
+	offset, err := db.reader.LookupOffset(ip)
+	if err != nil {
+		return nil, err
+	}
+	if offset == maxminddb.NotFound {
+		return nil, nil
+	}
+
+	var record geoIPRecord
+	if err := db.reader.Decode(offset, &record); err != nil {
+		return nil, err
+	}
+	location := &Location{City: record.City.Names["en"], Country: record.Country.IsoCode}
+	if len(record.Subdivisions) > 0 {
+		location.Region = record.Subdivisions[0].IsoCode
+	}
+	return location, nil
+}
+
+// TruncateIP zeroes the host part of an address, leaving its /24 network
+// for IPv4 and its /48 network for IPv6.
+func TruncateIP(ip net.IP) net.IP {
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32))
+	}
+	return ip.Mask(net.CIDRMask(48, 128))
+}
@@ -0,0 +1,284 @@
+/*
+// ----------------------------------------------------------------------------
+// geoip_test.go
+// Countertop Server Event Recording GeoIP Lookup Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+	"testing"
+
+	event "github.com/theorangechefco/cts/event"
+)
+
+// mmdbPointer encodes as a pointer to data already written.
+type mmdbPointer int
+
+// mmdbWriter builds a minimal MaxMind DB for tests.
+type mmdbWriter struct {
+	data bytes.Buffer
+}
+
+func (w *mmdbWriter) control(kind int, size int) {
+	if kind <= 7 {
+		w.data.WriteByte(byte(kind<<5 | size))
+	} else {
+		w.data.WriteByte(byte(size))
+		w.data.WriteByte(byte(kind - 7))
+	}
+}
+
+// encode appends a value and returns its offset in the data section.
+func (w *mmdbWriter) encode(value interface{}) int {
+	offset := w.data.Len()
+	switch v := value.(type) {
+	case mmdbPointer:
+		w.data.WriteByte(byte(1<<5 | (int(v)>>8)&0x7))
+		w.data.WriteByte(byte(v))
+	case string:
+		w.control(2, len(v))
+		w.data.WriteString(v)
+	case int:
+		w.control(6, 4)
+		w.data.Write([]byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)})
+	case []interface{}:
+		w.control(11, len(v))
+		for _, item := range v {
+			w.encode(item)
+		}
+	case map[string]interface{}:
+		w.control(7, len(v))
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			w.encode(key)
+			w.encode(v[key])
+		}
+	}
+	return offset
+}
+
+type trieNode struct {
+	children [2]*trieNode
+	data     int
+	leaf     bool
+}
+
+// build writes the database with each network pointing at its data.
+func (w *mmdbWriter) build(t *testing.T, recordSize int, networks map[string]int) []byte {
+	root := &trieNode{}
+	for cidr, offset := range networks {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// IPv4 networks sit under ::/96 in an IPv6 tree
+		address := network.IP.To16()
+		ones, _ := network.Mask.Size()
+		if v4 := network.IP.To4(); v4 != nil {
+			address = append(make(net.IP, 12), v4...)
+			ones += 96
+		}
+		node := root
+		for i := 0; i < ones; i++ {
+			bit := address[i/8] >> uint(7-i%8) & 1
+			if node.children[bit] == nil {
+				node.children[bit] = &trieNode{}
+			}
+			node = node.children[bit]
+		}
+		node.leaf, node.data = true, offset
+	}
+
+	var nodes []*trieNode
+	numbers := make(map[*trieNode]int)
+	var number func(*trieNode)
+	number = func(node *trieNode) {
+		numbers[node] = len(nodes)
+		nodes = append(nodes, node)
+		for _, child := range node.children {
+			if child != nil && !child.leaf {
+				number(child)
+			}
+		}
+	}
+	number(root)
+
+	var file bytes.Buffer
+	for _, node := range nodes {
+		var records [2]int
+		for bit, child := range node.children {
+			switch {
+			case child == nil:
+				records[bit] = len(nodes)
+			case child.leaf:
+				records[bit] = len(nodes) + 16 + child.data
+			default:
+				records[bit] = numbers[child]
+			}
+		}
+		left, right := records[0], records[1]
+		switch recordSize {
+		case 24:
+			file.Write([]byte{byte(left >> 16), byte(left >> 8), byte(left), byte(right >> 16), byte(right >> 8), byte(right)})
+		case 28:
+			file.Write([]byte{byte(left >> 16), byte(left >> 8), byte(left), byte(left>>24<<4 | right>>24),
+				byte(right >> 16), byte(right >> 8), byte(right)})
+		case 32:
+			file.Write([]byte{byte(left >> 24), byte(left >> 16), byte(left >> 8), byte(left),
+				byte(right >> 24), byte(right >> 16), byte(right >> 8), byte(right)})
+		}
+	}
+	file.Write(make([]byte, 16))
+	file.Write(w.data.Bytes())
+	file.WriteString("\xab\xcd\xefMaxMind.com")
+
+	metadata := &mmdbWriter{}
+	metadata.encode(map[string]interface{}{
+		"database_type": "Test-City",
+		"ip_version":    6,
+		"node_count":    len(nodes),
+		"record_size":   recordSize,
+	})
+	file.Write(metadata.data.Bytes())
+	return file.Bytes()
+}
+
+func writeGeoIPDatabase(t *testing.T, recordSize int) string {
+	w := &mmdbWriter{}
+	london := w.encode(map[string]interface{}{
+		"city":         map[string]interface{}{"names": map[string]interface{}{"en": "London", "de": "London"}},
+		"country":      map[string]interface{}{"iso_code": "GB", "geoname_id": 2635167},
+		"subdivisions": []interface{}{map[string]interface{}{"iso_code": "ENG"}},
+	})
+	// Shares the country map with London by pointer, as real databases do
+	manchester := w.encode(map[string]interface{}{
+		"city":    map[string]interface{}{"names": map[string]interface{}{"en": "Manchester"}},
+		"country": mmdbPointer(countryOffset(t, w, london)),
+	})
+	sanFrancisco := w.encode(map[string]interface{}{
+		"city":         map[string]interface{}{"names": map[string]interface{}{"en": "San Francisco"}},
+		"country":      map[string]interface{}{"iso_code": "US"},
+		"subdivisions": []interface{}{map[string]interface{}{"iso_code": "CA"}},
+	})
+
+	return writeTempFile(t, w.build(t, recordSize, map[string]int{
+		"81.2.69.0/24":    london,
+		"2.24.0.0/16":     manchester,
+		"2001:db8::/32":   sanFrancisco,
+		"216.160.83.0/24": sanFrancisco,
+	}))
+}
+
+func writeTempFile(t *testing.T, contents []byte) string {
+	file, err := ioutil.TempFile("", "geoip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	file.Write(contents)
+	return file.Name()
+}
+
+// countryOffset finds the country map written as part of the record at the
+// offset.
+func countryOffset(t *testing.T, w *mmdbWriter, record int) int {
+	offset := bytes.Index(w.data.Bytes()[record:], []byte("\x47country")) + record + len("\x47country")
+	if w.data.Bytes()[offset]>>5 != 7 {
+		t.Fatalf("no country map at %d", offset)
+	}
+	return offset
+}
+
+func TestGeoIPDatabaseLookup(t *testing.T) {
+	for _, recordSize := range []int{24, 28, 32} {
+		path := writeGeoIPDatabase(t, recordSize)
+		defer os.Remove(path)
+		db, err := event.OpenGeoIPDatabase(path)
+		if err != nil {
+			t.Fatalf("%d bit records: OpenGeoIPDatabase(_) = _, %v", recordSize, err)
+		}
+		defer db.Close()
+		if db.DatabaseType != "Test-City" {
+			t.Errorf("DatabaseType = %q, want Test-City", db.DatabaseType)
+		}
+
+		lookups := map[string]*event.Location{
+			"81.2.69.160":      {City: "London", Region: "ENG", Country: "GB"},
+			"2.24.1.1":         {City: "Manchester", Country: "GB"},
+			"216.160.83.56":    {City: "San Francisco", Region: "CA", Country: "US"},
+			"2001:db8::1":      {City: "San Francisco", Region: "CA", Country: "US"},
+			"::ffff:81.2.69.1": {City: "London", Region: "ENG", Country: "GB"},
+			"10.0.0.1":         nil,
+			"2001:db9::1":      nil,
+		}
+		for address, want := range lookups {
+			location, err := db.Lookup(net.ParseIP(address))
+			if err != nil {
+				t.Errorf("%d bit records: Lookup(%s) = _, %v", recordSize, address, err)
+				continue
+			}
+			if (location == nil) != (want == nil) || (want != nil && *location != *want) {
+				t.Errorf("%d bit records: Lookup(%s) = %+v, want %+v", recordSize, address, location, want)
+			}
+		}
+	}
+}
+
+func TestOpenGeoIPDatabaseRejectsOtherFiles(t *testing.T) {
+	file, err := ioutil.TempFile("", "geoip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("country,city\nGB,London\n")
+	file.Close()
+	if _, err := event.OpenGeoIPDatabase(file.Name()); err == nil {
+		t.Errorf("OpenGeoIPDatabase(csv) = _, nil, want error")
+	}
+}
+
+func TestGeoIPDatabaseLookupRejectsPointerCycles(t *testing.T) {
+	w := &mmdbWriter{}
+	// The city's names point back at the city, which follows the record's
+	// map control byte and "city" key
+	record := w.data.Len()
+	looped := w.encode(map[string]interface{}{
+		"city": map[string]interface{}{"names": mmdbPointer(record + 1 + 1 + len("city"))},
+	})
+	path := writeTempFile(t, w.build(t, 24, map[string]int{"81.2.69.0/24": looped}))
+	defer os.Remove(path)
+	db, err := event.OpenGeoIPDatabase(path)
+	if err != nil {
+		t.Fatalf("OpenGeoIPDatabase(_) = _, %v", err)
+	}
+	defer db.Close()
+	if location, err := db.Lookup(net.ParseIP("81.2.69.160")); err == nil {
+		t.Errorf("Lookup(_) = %+v, nil in a pointer cycle, want error", location)
+	}
+}
+
+func TestTruncateIP(t *testing.T) {
+	addresses := map[string]string{
+		"81.2.69.160":           "81.2.69.0",
+		"::ffff:81.2.69.160":    "81.2.69.0",
+		"2001:db8:1234:5678::1": "2001:db8:1234::",
+	}
+	for address, want := range addresses {
+		if truncated := event.TruncateIP(net.ParseIP(address)).String(); truncated != want {
+			t.Errorf("TruncateIP(%s) = %s, want %s", address, truncated, want)
+		}
+	}
+}
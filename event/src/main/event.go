@@ -14,6 +14,11 @@ import (
 	"flag"
 	"fmt"
 	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"google.golang.org/grpc"
 
@@ -21,6 +26,7 @@ import (
 	eventutil "github.com/theorangechefco/cts/event"
 	pb "github.com/theorangechefco/cts/go-protos"
 	"github.com/theorangechefco/cts/go-shared-libs/cts/logger"
+	"github.com/theorangechefco/cts/go-shared-libs/cts/util"
 
 	"github.com/gocql/gocql"
 )
@@ -28,12 +34,37 @@ import (
 var (
 	port               = flag.Int("port", 50056, "Event service server port.")
 	identityServerAddr = flag.String("identity_server_addr", "127.0.0.1:50052", "The identity server address in the format of host:port")
+	sink               = flag.String("sink", "cassandra", "Where events are recorded: cassandra, file or sql")
+	sinkFile           = flag.String("sink_file", "events.ndjson", "Newline delimited JSON file events are appended to by the file sink")
+	sinkFileMaxBytes   = flag.Int64("sink_file_max_bytes", 100<<20, "Size at which the file sink rotates its file, 0 never rotates")
+	sqlDriver          = flag.String("sql_driver", "mysql", "Driver used by the sql sink: mysql or postgres")
+	sqlDataSource      = flag.String("sql_data_source", "eventsrv:abc@tcp(127.0.0.1:3306)/events", "Data source name used by the sql sink")
+	queueSize          = flag.Int("queue_size", 10000, "Events queued for writing before clients are made to wait")
+	batchSize          = flag.Int("batch_size", 100, "Most events written to the sink at once")
+	flushInterval      = flag.Duration("flush_interval", time.Second, "Longest time an event is queued before it is written")
+	analysts           = flag.String("analysts", "", "Comma separated UUIDs of users who may query every user's events")
+	eventTypes         = flag.String("event_types", "", "Directory of JSON Schemas for event payloads, one <event name>.json per type. If left blank, payloads are not checked")
+	invalidEvents      = flag.String("invalid_events", "reject", "What happens to events with invalid payloads: reject or quarantine")
+	quarantineFile     = flag.String("quarantine_file", "quarantine.ndjson", "Newline delimited JSON file quarantined events are appended to")
+	registryStats      = flag.Duration("registry_stats_interval", 5*time.Minute, "How often event type statistics are logged")
+	statsStore         = flag.String("stats_store", "memory", "Where daily event counters are kept: redis, memory, or none")
+	statsRetention     = flag.Duration("stats_retention", 90*24*time.Hour, "How long daily event counters are kept")
+	statsQueueSize     = flag.Int("stats_queue_size", 100, "Written batches queued for the daily counters before further batches go uncounted")
+	statsTimeout       = flag.Duration("stats_timeout", 2*time.Second, "Longest a Redis stats store call may take before it fails")
+	geoIPDatabase      = flag.String("geoip_database", "", "MaxMind DB file, such as GeoLite2-City.mmdb, used to locate clients. If left blank, clients are not located")
+	truncateIP         = flag.Bool("truncate_ip", true, "Keep only the /24 (IPv4) or /48 (IPv6) network of client addresses, for lookups and storage")
+	trustedProxies     = flag.String("trusted_proxies", "", "Comma separated addresses or CIDR networks of proxies whose x-forwarded-for metadata gives the client address. If left blank, the connection's address is used")
+	redisHost          = flag.String("redis_host", "127.0.0.1:6379", "Hostname of Redis server")
+	redisPass          = flag.String("redis_pass", "abc", "Redis password (optional)")
+	redisPoolSize      = flag.Int("redis_pool_size", 10, "Redis pool size")
 	cassandraHost      = flag.String("cassandra_host", "0.0.0.0", "Cassandra hostname")
 	cassandraUser      = flag.String("cassandra_user", "eventsrv", "Cassandra username")
 	cassandraPass      = flag.String("cassandra_pass", "abc", "Cassandra password")
 	stdErrLog          = flag.Bool("stderr_log", true, "Log to STDERR")
 	fluentdHost        = flag.String("fluentd_host", "", "Fluentd agent hostname. If left blank, fluentd logging disabled")
 	fluentdPort        = flag.Int("fluentd_port", 24224, "Fluentd agent port")
+	verifyTokens       = flag.Bool("verify_tokens", true, "Verify signed session tokens locally instead of looking them up through the identity service")
+	tokenSyncInterval  = flag.Duration("token_sync_interval", 30*time.Second, "How often signing keys and closed sessions are fetched from the identity service")
 )
 
 func main() {
@@ -42,28 +73,93 @@ func main() {
 	eventServerInstance := new(eventutil.Server)
 	eventServerInstance.Logger = logger.NewLogger("eventsrv", *fluentdHost, *fluentdPort, *stdErrLog, logrus.DebugLevel)
 
-	cluster := gocql.NewCluster(*cassandraHost)
-	if *cassandraUser != "" {
-		cluster.Authenticator = gocql.PasswordAuthenticator{
-			Username: *cassandraUser,
-			Password: *cassandraPass,
+	var eventSink eventutil.EventSink
+	var err error
+	switch *sink {
+	case "cassandra":
+		eventSink, err = newCassandraSink()
+	case "file":
+		eventSink, err = eventutil.NewFileSink(*sinkFile, *sinkFileMaxBytes)
+	case "sql":
+		eventSink, err = eventutil.NewSQLSink(*sqlDriver, *sqlDataSource)
+	default:
+		err = fmt.Errorf("unknown sink %q, expected cassandra, file or sql", *sink)
+	}
+	if err != nil {
+		eventServerInstance.Logger.Fatal(logrus.Fields{
+			"phase": "startup",
+			"event": "connection",
+			"tag":   *sink},
+			fmt.Sprintf("Cannot set up %s event sink: %v", *sink, err))
+	}
+	eventServerInstance.Buffer = eventutil.NewEventBuffer(eventSink, *queueSize, *batchSize, *flushInterval)
+	if reader, ok := eventSink.(eventutil.EventReader); ok {
+		eventServerInstance.Reader = reader
+	}
+	eventServerInstance.Analysts = make(map[string]bool)
+	for _, analyst := range strings.Split(*analysts, ",") {
+		if analyst = strings.TrimSpace(analyst); analyst != "" {
+			eventServerInstance.Analysts[analyst] = true
 		}
 	}
-	cluster.Keyspace = "eventks"
-	session, err := cluster.CreateSession()
+
+	if *eventTypes != "" {
+		setupRegistry(eventServerInstance)
+	}
+
+	eventServerInstance.TruncateIP = *truncateIP
+	eventServerInstance.TrustedProxies, err = eventutil.ParseNetworks(*trustedProxies)
 	if err != nil {
+		eventServerInstance.Logger.Fatal(logrus.Fields{
+			"phase": "startup",
+			"event": "proxies"},
+			fmt.Sprintf("Cannot parse trusted proxies: %v", err))
+	}
+	if *geoIPDatabase != "" {
+		eventServerInstance.GeoIP, err = eventutil.OpenGeoIPDatabase(*geoIPDatabase)
+		if err != nil {
+			eventServerInstance.Logger.Fatal(logrus.Fields{
+				"phase": "startup",
+				"event": "geoip"},
+				fmt.Sprintf("Cannot open GeoIP database: %v", err))
+		}
+		eventServerInstance.Logger.Info(logrus.Fields{
+			"phase": "startup",
+			"event": "geoip"},
+			fmt.Sprintf("Locating clients with %s database %s", eventServerInstance.GeoIP.DatabaseType, *geoIPDatabase))
+	}
+
+	switch *statsStore {
+	case "redis":
+		pool, err := util.NewPoolTimeout(eventServerInstance.Logger, *redisHost, *redisPass, *redisPoolSize, 3, 500, *statsTimeout)
+		if err != nil {
+			eventServerInstance.Logger.Fatal(logrus.Fields{
+				"phase": "startup",
+				"event": "connection",
+				"tag":   "redis"},
+				fmt.Sprintf("Unable to set up Redis pool: %v", err))
+		}
+		eventServerInstance.Stats = &eventutil.RedisStatsStore{Pool: pool, Retention: *statsRetention}
+	case "memory":
+		eventServerInstance.Stats = eventutil.NewMemoryStatsStore(*statsRetention)
+	case "none":
+	default:
 		eventServerInstance.Logger.Fatal(logrus.Fields{
 			"phase": "startup",
 			"event": "connection",
-			"tag":   "cassandra"},
-			fmt.Sprintf("Cannot connect to Cassandra: %v", err))
+			"tag":   "stats"},
+			fmt.Sprintf("Unknown stats store %q, expected redis, memory or none", *statsStore))
+	}
+	if eventServerInstance.Stats != nil {
+		eventServerInstance.StatsQueue = eventutil.NewStatsQueue(eventServerInstance.Stats, *statsQueueSize)
+		eventServerInstance.StatsQueue.Failed = eventServerInstance.CountFailed
 	}
-	defer session.Close()
+	eventServerInstance.Buffer.Written = eventServerInstance.CountEvents
 	eventServerInstance.Logger.Info(logrus.Fields{
 		"phase": "startup",
 		"event": "connection",
-		"tag":   "cassandra"},
-		fmt.Sprintf("Connected to Cassandra server at: %s", *cassandraHost))
+		"tag":   *sink},
+		fmt.Sprintf("Recording events with the %s sink", *sink))
 
 	identityConn, err := grpc.Dial(*identityServerAddr, []grpc.DialOption{grpc.WithInsecure()}...)
 	if err != nil {
@@ -87,17 +183,114 @@ func main() {
 			"event": "bind"},
 			fmt.Sprintf("Failed to listen: %v", err))
 	}
-	var serverOpts []grpc.ServerOption
+	// Connections are tagged with their address, which clients are located by
+	serverOpts := []grpc.ServerOption{grpc.Creds(&eventutil.PeerCredentials{})}
 	grpcServer := grpc.NewServer(serverOpts...)
 
 	pb.RegisterEventServiceServer(grpcServer, eventServerInstance)
 
-	eventServerInstance.Session = session
 	eventServerInstance.IdentityClient = pb.NewIdentityServiceClient(identityConn)
+	if *verifyTokens {
+		verifier := util.NewTokenVerifier()
+		go verifier.Poll(eventServerInstance.Logger, *tokenSyncInterval, func() error {
+			return verifier.Sync(eventServerInstance.IdentityClient)
+		})
+		eventServerInstance.Verifier = verifier
+	}
 
 	eventServerInstance.Logger.Info(logrus.Fields{
 		"phase": "startup",
 		"event": "bind"},
 		fmt.Sprintf("Countertop Event service listening on port: %d", *port))
+
+	// Stop taking requests on SIGINT or SIGTERM, then write out any events
+	// still queued before exiting
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		eventServerInstance.Logger.Info(logrus.Fields{
+			"phase": "shutdown",
+			"event": "signal"},
+			fmt.Sprintf("Received %v, stopping", sig))
+		grpcServer.Stop()
+	}()
+
 	grpcServer.Serve(lis)
+
+	if eventServerInstance.Quarantine != nil {
+		eventServerInstance.Quarantine.Close()
+	}
+	if err := eventServerInstance.Buffer.Close(); err != nil {
+		eventServerInstance.Logger.Error(logrus.Fields{
+			"phase": "shutdown",
+			"event": "connection",
+			"tag":   *sink},
+			fmt.Sprintf("Cannot flush queued events to the %s sink: %v", *sink, err))
+	}
+	if eventServerInstance.StatsQueue != nil {
+		eventServerInstance.StatsQueue.Close()
+	}
+	if eventServerInstance.GeoIP != nil {
+		eventServerInstance.GeoIP.Close()
+	}
+}
+
+func setupRegistry(eventServerInstance *eventutil.Server) {
+	registry, err := eventutil.LoadEventRegistry(*eventTypes)
+	if err != nil {
+		eventServerInstance.Logger.Fatal(logrus.Fields{
+			"phase": "startup",
+			"event": "registry"},
+			fmt.Sprintf("Cannot load event types from %s: %v", *eventTypes, err))
+	}
+	eventServerInstance.Registry = registry
+	eventServerInstance.Logger.Info(logrus.Fields{
+		"phase": "startup",
+		"event": "registry"},
+		fmt.Sprintf("Checking payloads of event types %v, %s invalid events", registry.Types(), *invalidEvents))
+
+	switch *invalidEvents {
+	case "reject":
+	case "quarantine":
+		eventServerInstance.Quarantine, err = eventutil.NewFileSink(*quarantineFile, *sinkFileMaxBytes)
+		if err != nil {
+			eventServerInstance.Logger.Fatal(logrus.Fields{
+				"phase": "startup",
+				"event": "registry",
+				"tag":   "quarantine"},
+				fmt.Sprintf("Cannot open quarantine file %s: %v", *quarantineFile, err))
+		}
+	default:
+		eventServerInstance.Logger.Fatal(logrus.Fields{
+			"phase": "startup",
+			"event": "registry"},
+			fmt.Sprintf("Unknown --invalid_events %q, expected reject or quarantine", *invalidEvents))
+	}
+
+	go func() {
+		for range time.Tick(*registryStats) {
+			eventServerInstance.Logger.Info(logrus.Fields{
+				"phase": "process",
+				"event": "stats",
+				"tag":   "registry"},
+				fmt.Sprintf("Event types: %v", registry.Stats()))
+		}
+	}()
+}
+
+func newCassandraSink() (*eventutil.CassandraSink, error) {
+	cluster := gocql.NewCluster(*cassandraHost)
+	if *cassandraUser != "" {
+		cluster.Authenticator = gocql.PasswordAuthenticator{
+			Username: *cassandraUser,
+			Password: *cassandraPass,
+		}
+	}
+	cluster.Keyspace = "eventks"
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return nil, err
+	}
+	return &eventutil.CassandraSink{Session: session}, nil
 }
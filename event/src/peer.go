@@ -0,0 +1,149 @@
+/*
+// ----------------------------------------------------------------------------
+// peer.go
+// Countertop Server Event Recording Client Addresses
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+// PeerCredentials are server credentials that tag each connection with the
+// address it came from, so that ClientIP can find it in request contexts.
+type PeerCredentials struct {
+	// Inner secures connections before they are tagged. If nil, they are
+	// not secured.
+	Inner credentials.TransportAuthenticator
+}
+
+// PeerInfo is the auth information of connections accepted with
+// PeerCredentials. AuthInfo is the inner credentials', if any.
+type PeerInfo struct {
+	Addr     net.Addr
+	AuthInfo credentials.AuthInfo
+}
+
+func (p PeerInfo) AuthType() string {
+	if p.AuthInfo != nil {
+		return p.AuthInfo.AuthType()
+	}
+	return "peer"
+}
+
+func (c *PeerCredentials) ServerHandshake(rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	conn, info := rawConn, credentials.AuthInfo(nil)
+	if c.Inner != nil {
+		var err error
+		if conn, info, err = c.Inner.ServerHandshake(rawConn); err != nil {
+			return nil, nil, err
+		}
+	}
+	return conn, PeerInfo{Addr: rawConn.RemoteAddr(), AuthInfo: info}, nil
+}
+
+func (c *PeerCredentials) ClientHandshake(addr string, rawConn net.Conn, timeout time.Duration) (net.Conn, credentials.AuthInfo, error) {
+	if c.Inner != nil {
+		return c.Inner.ClientHandshake(addr, rawConn, timeout)
+	}
+	return rawConn, nil, nil
+}
+
+func (c *PeerCredentials) Info() credentials.ProtocolInfo {
+	if c.Inner != nil {
+		return c.Inner.Info()
+	}
+	return credentials.ProtocolInfo{}
+}
+
+func (c *PeerCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	if c.Inner != nil {
+		return c.Inner.GetRequestMetadata(ctx, uri...)
+	}
+	return nil, nil
+}
+
+func (c *PeerCredentials) RequireTransportSecurity() bool {
+	return c.Inner != nil && c.Inner.RequireTransportSecurity()
+}
+
+// ParseNetworks reads a comma separated list of addresses and CIDR networks.
+func ParseNetworks(list string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * net.IPv6len
+			if v4 := ip.To4(); v4 != nil {
+				ip, bits = v4, 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an address or CIDR network", entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// ClientIP is the address of the client the request's connection came from,
+// as tagged by PeerCredentials. If that is one of the trusted proxies, the
+// client is instead the last address in the x-forwarded-for metadata that is
+// not a trusted proxy itself. Clients cannot choose their address this way,
+// since only the proxies' own additions are believed.
+func ClientIP(ctx context.Context, proxies []*net.IPNet) net.IP {
+	info, ok := credentials.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	peer, ok := info.(PeerInfo)
+	if !ok || peer.Addr == nil {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(peer.Addr.String())
+	if err != nil {
+		return nil
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !contains(proxies, ip) {
+		return ip
+	}
+
+	md, _ := metadata.FromContext(ctx)
+	var hops []string
+	for _, forwarded := range md["x-forwarded-for"] {
+		hops = append(hops, strings.Split(forwarded, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0 && contains(proxies, ip); i-- {
+		if ip = net.ParseIP(strings.TrimSpace(hops[i])); ip == nil {
+			return nil
+		}
+	}
+	return ip
+}
+
+func contains(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+/*
+// ----------------------------------------------------------------------------
+// peer_test.go
+// Countertop Server Event Recording Client Address Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event_test
+
+import (
+	"net"
+	"testing"
+
+	event "github.com/theorangechefco/cts/event"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestPeerCredentialsTagsConnections(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	client, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	server, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	conn, info, err := (&event.PeerCredentials{}).ServerHandshake(server)
+	if err != nil || conn != server {
+		t.Fatalf("ServerHandshake(_) = %v, _, %v, want the connection", conn, err)
+	}
+	peer, ok := info.(event.PeerInfo)
+	if !ok || peer.Addr.String() != client.LocalAddr().String() {
+		t.Errorf("ServerHandshake(_) auth info = %v, want the address %s", info, client.LocalAddr())
+	}
+	if ip := event.ClientIP(credentials.NewContext(context.Background(), info), nil); !ip.Equal(net.ParseIP("127.0.0.1")) {
+		t.Errorf("ClientIP(_) = %v, want 127.0.0.1", ip)
+	}
+}
+
+// peerContext is the context of a request on a connection from the address,
+// with the metadata.
+func peerContext(address string, md metadata.MD) context.Context {
+	ctx := metadata.NewContext(context.Background(), md)
+	if address == "" {
+		return ctx
+	}
+	addr, _ := net.ResolveTCPAddr("tcp", address)
+	return credentials.NewContext(ctx, event.PeerInfo{Addr: addr})
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := event.ParseNetworks("10.0.0.0/8, 192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"direct", peerContext("81.2.69.160:4000", nil), "81.2.69.160"},
+		{"direct with forged header", peerContext("81.2.69.160:4000", metadata.Pairs("x-forwarded-for", "1.2.3.4")), "81.2.69.160"},
+		{"direct with forged userip", peerContext("81.2.69.160:4000", metadata.Pairs("userip", "1.2.3.4")), "81.2.69.160"},
+		{"direct IPv6", peerContext("[2001:db8::1]:4000", nil), "2001:db8::1"},
+		{"through proxy", peerContext("10.1.2.3:4000", metadata.Pairs("x-forwarded-for", "81.2.69.160")), "81.2.69.160"},
+		{"through proxy with forged header", peerContext("10.1.2.3:4000", metadata.Pairs("x-forwarded-for", "1.2.3.4, 81.2.69.160")), "81.2.69.160"},
+		{"through proxies", peerContext("192.0.2.1:4000", metadata.Pairs("x-forwarded-for", "81.2.69.160", "x-forwarded-for", "10.9.9.9")), "81.2.69.160"},
+		{"proxy without header", peerContext("10.1.2.3:4000", nil), "10.1.2.3"},
+		{"proxy with garbled header", peerContext("10.1.2.3:4000", metadata.Pairs("x-forwarded-for", "not an address")), ""},
+		{"untagged connection", peerContext("", metadata.Pairs("x-forwarded-for", "81.2.69.160", "userip", "81.2.69.160")), ""},
+	}
+	for _, test := range tests {
+		ip := event.ClientIP(test.ctx, proxies)
+		if (test.want == "" && ip != nil) || (test.want != "" && !ip.Equal(net.ParseIP(test.want))) {
+			t.Errorf("%s: ClientIP(_) = %v, want %q", test.name, ip, test.want)
+		}
+	}
+}
+
+func TestParseNetworks(t *testing.T) {
+	networks, err := event.ParseNetworks(" 10.0.0.0/8,192.0.2.1, 2001:db8::1,")
+	if err != nil || len(networks) != 3 {
+		t.Fatalf("ParseNetworks(_) = %v, %v, want 3 networks", networks, err)
+	}
+	contains := map[string]bool{
+		"10.255.0.1":  true,
+		"192.0.2.1":   true,
+		"192.0.2.2":   false,
+		"2001:db8::1": true,
+		"2001:db8::2": false,
+		"81.2.69.160": false,
+	}
+	for address, want := range contains {
+		found := false
+		for _, network := range networks {
+			found = found || network.Contains(net.ParseIP(address))
+		}
+		if found != want {
+			t.Errorf("ParseNetworks(_) contains %s = %v, want %v", address, found, want)
+		}
+	}
+
+	if networks, err := event.ParseNetworks(""); err != nil || len(networks) != 0 {
+		t.Errorf("ParseNetworks(\"\") = %v, %v, want none", networks, err)
+	}
+	if _, err := event.ParseNetworks("10.0.0.0/8, proxy.example.com"); err == nil {
+		t.Errorf("ParseNetworks(hostname) = _, nil, want error")
+	}
+}
@@ -0,0 +1,110 @@
+/*
+// ----------------------------------------------------------------------------
+// query.go
+// Countertop Server Event Recording Queries
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+// EventQuery selects recorded events. Empty fields match every event. The
+// time range applies to when events occurred, Start is inclusive and End
+// exclusive.
+type EventQuery struct {
+	UserID     string
+	Name       string
+	Appversion string
+	Start      time.Time
+	End        time.Time
+}
+
+func (q *EventQuery) Matches(record *EventRecord) bool {
+	switch {
+	case q.UserID != "" && record.UserID != q.UserID:
+		return false
+	case q.Name != "" && record.Event.Name != q.Name:
+		return false
+	case q.Appversion != "" && record.Event.Appversion != q.Appversion:
+		return false
+	case !q.Start.IsZero() && record.Occurredat.Before(q.Start):
+		return false
+	case !q.End.IsZero() && !record.Occurredat.Before(q.End):
+		return false
+	}
+	return true
+}
+
+// EventReader reads back recorded events. Read calls fn with every event
+// matching the query, in whatever order the backend stores them, and stops
+// at the first error fn returns, which Read then returns.
+type EventReader interface {
+	Read(query *EventQuery, fn func(*EventRecord) error) error
+}
+
+func eventQueryFromProto(request *pb.EventQuery) *EventQuery {
+	query := &EventQuery{
+		UserID:     request.Userid,
+		Name:       request.Name,
+		Appversion: request.Appversion,
+	}
+	if request.Start != nil && request.Start.Seconds > 0 {
+		query.Start = time.Unix(request.Start.Seconds, 0)
+	}
+	if request.End != nil && request.End.Seconds > 0 {
+		query.End = time.Unix(request.End.Seconds, 0)
+	}
+	return query
+}
+
+// RecordedEventProto is the record as it is returned by QueryEvents.
+func RecordedEventProto(record *EventRecord) *pb.RecordedEvent {
+	return &pb.RecordedEvent{
+		Id:         record.ID.String(),
+		Userid:     record.UserID,
+		Occurredat: &pb.Timestamp{Seconds: record.Occurredat.Unix()},
+		Createdat:  &pb.Timestamp{Seconds: record.Createdat.Unix()},
+		Ip:         record.IP,
+		Location: &pb.Location{
+			City:    record.Location.City,
+			Region:  record.Location.Region,
+			Country: record.Location.Country,
+		},
+		Event: record.Event,
+	}
+}
+
+// RecordFromProto turns an event returned by QueryEvents back into a record.
+func RecordFromProto(recorded *pb.RecordedEvent) (*EventRecord, error) {
+	id, err := gocql.ParseUUID(recorded.Id)
+	if err != nil {
+		return nil, err
+	}
+	record := &EventRecord{ID: id, UserID: recorded.Userid, IP: recorded.Ip, Event: recorded.Event}
+	if recorded.Location != nil {
+		record.Location = Location{
+			City:    recorded.Location.City,
+			Region:  recorded.Location.Region,
+			Country: recorded.Location.Country,
+		}
+	}
+	if recorded.Occurredat != nil {
+		record.Occurredat = time.Unix(recorded.Occurredat.Seconds, 0)
+	}
+	if recorded.Createdat != nil {
+		record.Createdat = time.Unix(recorded.Createdat.Seconds, 0)
+	}
+	if record.Event == nil {
+		record.Event = &pb.Event{}
+	}
+	return record, nil
+}
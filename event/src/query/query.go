@@ -0,0 +1,130 @@
+/*
+// ----------------------------------------------------------------------------
+// query.go
+// Countertop Server Event Query & Export Tool
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	eventutil "github.com/theorangechefco/cts/event"
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+var (
+	eventServerAddr = flag.String("server_addr", "localhost:50056", "The server address in the format of host:port")
+	token           = flag.String("token", "", "Session token to query with")
+	userID          = flag.String("user", "", "Only events of the user with this UUID, defaults to your own unless you are an analyst")
+	name            = flag.String("name", "", "Only events with this name")
+	appVersion      = flag.String("app_version", "", "Only events from this app version")
+	start           = flag.String("start", "", "Only events that occurred at or after this date (2006-01-02) or time (RFC 3339)")
+	end             = flag.String("end", "", "Only events that occurred before this date (2006-01-02) or time (RFC 3339)")
+	limit           = flag.Int("limit", 0, "Most events to return, 0 for no limit")
+	exportFile      = flag.String("export", "", "File to export the events to, - for STDOUT. Requires --start and --end")
+	format          = flag.String("format", "ndjson", "Output format: csv or ndjson")
+)
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(-1)
+}
+
+func parseTime(flagName string, value string) *pb.Timestamp {
+	if value == "" {
+		return nil
+	}
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &pb.Timestamp{Seconds: t.Unix()}
+		}
+	}
+	fail("--%s %q is neither a date (2006-01-02) nor a time (RFC 3339)", flagName, value)
+	return nil
+}
+
+func openOutput(name string) (io.WriteCloser, error) {
+	if name == "" || name == "-" {
+		return os.Stdout, nil
+	}
+	return os.Create(name)
+}
+
+func main() {
+	flag.Parse()
+	if *token == "" {
+		fail("--token is required")
+	}
+	if *exportFile != "" && (*start == "" || *end == "") {
+		fail("--export requires a date range, set --start and --end")
+	}
+
+	request := &pb.EventQuery{
+		Userid:     *userID,
+		Name:       *name,
+		Appversion: *appVersion,
+		Start:      parseTime("start", *start),
+		End:        parseTime("end", *end),
+		Limit:      int32(*limit),
+	}
+
+	out, err := openOutput(*exportFile)
+	if err != nil {
+		fail("cannot create %s: %v", *exportFile, err)
+	}
+	defer out.Close()
+	exporter, err := eventutil.NewEventExporter(out, *format)
+	if err != nil {
+		fail("%v", err)
+	}
+
+	conn, err := grpc.Dial(*eventServerAddr, []grpc.DialOption{grpc.WithInsecure()}...)
+	if err != nil {
+		fail("cannot dial %s: %v", *eventServerAddr, err)
+	}
+	defer conn.Close()
+	client := pb.NewEventServiceClient(conn)
+
+	ctx := metadata.NewContext(context.Background(), metadata.New(map[string]string{"token": *token}))
+	stream, err := client.QueryEvents(ctx, request)
+	if err != nil {
+		fail("cannot query events: %v", err)
+	}
+
+	count := 0
+	for {
+		recorded, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fail("query failed after %d events: %v", count, err)
+		}
+		record, err := eventutil.RecordFromProto(recorded)
+		if err != nil {
+			fail("invalid event %v: %v", recorded, err)
+		}
+		if err := exporter.Write(record); err != nil {
+			fail("cannot write event %d: %v", count+1, err)
+		}
+		count++
+	}
+	if err := exporter.Flush(); err != nil {
+		fail("cannot write events: %v", err)
+	}
+	if *exportFile != "" && *exportFile != "-" {
+		fmt.Fprintf(os.Stderr, "Exported %d events to %s\n", count, *exportFile)
+	}
+}
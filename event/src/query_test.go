@@ -0,0 +1,151 @@
+/*
+// ----------------------------------------------------------------------------
+// query_test.go
+// Countertop Server Event Recording Query & Export Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	event "github.com/theorangechefco/cts/event"
+)
+
+func TestEventQueryMatches(t *testing.T) {
+	record := testRecord("Nexus 5")
+	record.Event.Appversion = "1.2"
+	occurred := record.Occurredat
+
+	queries := map[string]struct {
+		query   event.EventQuery
+		matches bool
+	}{
+		"everything":     {event.EventQuery{}, true},
+		"user":           {event.EventQuery{UserID: testUserID}, true},
+		"other user":     {event.EventQuery{UserID: "0b5cc1b0-a6ba-4b0b-9f7b-1a1b2f7f0e6a"}, false},
+		"name":           {event.EventQuery{Name: "recipe_viewed"}, true},
+		"other name":     {event.EventQuery{Name: "app_open"}, false},
+		"app version":    {event.EventQuery{Appversion: "1.2"}, true},
+		"other version":  {event.EventQuery{Appversion: "1.3"}, false},
+		"start at":       {event.EventQuery{Start: occurred}, true},
+		"start after":    {event.EventQuery{Start: occurred.Add(time.Second)}, false},
+		"end after":      {event.EventQuery{End: occurred.Add(time.Second)}, true},
+		"end at":         {event.EventQuery{End: occurred}, false},
+		"range":          {event.EventQuery{Start: occurred.Add(-time.Hour), End: occurred.Add(time.Hour)}, true},
+		"range and name": {event.EventQuery{Start: occurred.Add(-time.Hour), Name: "app_open"}, false},
+	}
+	for name, test := range queries {
+		if matches := test.query.Matches(record); matches != test.matches {
+			t.Errorf("%s: Matches(_) = %t, want %t", name, matches, test.matches)
+		}
+	}
+}
+
+func TestFileSinkRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventsink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sink, err := event.NewFileSink(filepath.Join(dir, "events.ndjson"), 1)
+	if err != nil {
+		t.Fatalf("NewFileSink(_) = _, %v", err)
+	}
+	defer sink.Close()
+	written := []*event.EventRecord{testRecord("first"), testRecord("second"), testRecord("third")}
+	written[1].Event.Name = "app_open"
+	for _, record := range written {
+		if err := sink.Write(record); err != nil {
+			t.Fatalf("Write(_) = %v", err)
+		}
+	}
+
+	var models []string
+	err = sink.Read(&event.EventQuery{Name: "recipe_viewed"}, func(record *event.EventRecord) error {
+		if record.UserID != testUserID || !record.Occurredat.Equal(written[0].Occurredat) {
+			t.Errorf("Read(_) record = %+v", record)
+		}
+		models = append(models, record.Event.Model)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Read(_) = %v", err)
+	}
+	if len(models) != 2 || models[0] != "first" || models[1] != "third" {
+		t.Errorf("Read(_) returned %v, want [first third] across rotated files", models)
+	}
+
+	// An error from the callback stops the read
+	calls := 0
+	err = sink.Read(&event.EventQuery{}, func(*event.EventRecord) error {
+		calls++
+		return os.ErrInvalid
+	})
+	if err != os.ErrInvalid || calls != 1 {
+		t.Errorf("Read(_) = %v after %d calls, want %v after 1", err, calls, os.ErrInvalid)
+	}
+}
+
+func TestRecordedEventProto(t *testing.T) {
+	record := testRecord("Nexus 5")
+	recorded := event.RecordedEventProto(record)
+	back, err := event.RecordFromProto(recorded)
+	if err != nil {
+		t.Fatalf("RecordFromProto(_) = _, %v", err)
+	}
+	if back.ID != record.ID || back.UserID != record.UserID || !back.Occurredat.Equal(record.Occurredat) ||
+		!back.Createdat.Equal(record.Createdat) || back.Event != record.Event {
+		t.Errorf("RecordFromProto(RecordedEventProto(%+v)) = %+v", record, back)
+	}
+
+	recorded.Id = "not a uuid"
+	if _, err := event.RecordFromProto(recorded); err == nil {
+		t.Errorf("RecordFromProto(_) with an invalid ID = _, nil, want error")
+	}
+}
+
+func TestEventExporter(t *testing.T) {
+	var out bytes.Buffer
+	exporter, err := event.NewEventExporter(&out, event.ExportCSV)
+	if err != nil {
+		t.Fatalf("NewEventExporter(_, csv) = _, %v", err)
+	}
+	for _, model := range []string{"iPhone7,2", "Nexus 5"} {
+		if err := exporter.Write(testRecord(model)); err != nil {
+			t.Errorf("Write(_) = %v", err)
+		}
+	}
+	if err := exporter.Flush(); err != nil {
+		t.Errorf("Flush() = %v", err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("exported CSV is invalid: %v", err)
+	}
+	if len(rows) != 3 || rows[0][1] != "userid" || rows[1][1] != testUserID || rows[2][12] != "Nexus 5" {
+		t.Errorf("exported CSV = %v", rows)
+	}
+
+	out.Reset()
+	exporter, _ = event.NewEventExporter(&out, event.ExportNDJSON)
+	exporter.Write(testRecord("Nexus 5"))
+	exporter.Flush()
+	if !bytes.Contains(out.Bytes(), []byte(`"model":"Nexus 5"`)) || bytes.Count(out.Bytes(), []byte("\n")) != 1 {
+		t.Errorf("exported NDJSON = %q", out.String())
+	}
+
+	if _, err := event.NewEventExporter(&out, "xml"); err == nil {
+		t.Errorf("NewEventExporter(_, xml) = _, nil, want error")
+	}
+}
@@ -0,0 +1,122 @@
+/*
+// ----------------------------------------------------------------------------
+// redisstats.go
+// Countertop Server Event Recording Redis Daily Counters
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/theorangechefco/cts/go-shared-libs/cts/util"
+)
+
+// A day's counters are a hash, eventstats_<day>, keyed by counter name.
+// Active users are counted by a HyperLogLog, eventusers_<day>, so the count
+// is approximate, to within about 1%, but takes little memory however many
+// users there are. Both keys expire once the retention period is over.
+const (
+	// KEYS: stats key, users key
+	// ARGV: TTL in seconds, number of counters n, n counter name and
+	// increment pairs, user IDs
+	addStatsScript = `
+local n = tonumber(ARGV[2])
+for i = 0, n - 1 do
+	redis.call('HINCRBY', KEYS[1], ARGV[3 + 2 * i], ARGV[4 + 2 * i])
+end
+local users = {}
+for i = 3 + 2 * n, #ARGV do
+	users[#users + 1] = ARGV[i]
+end
+if #users > 0 then
+	redis.call('PFADD', KEYS[2], unpack(users))
+end
+redis.call('EXPIRE', KEYS[1], ARGV[1])
+redis.call('EXPIRE', KEYS[2], ARGV[1])
+return n`
+
+	// KEYS: stats key, users key
+	// Returns the counter name and value pairs, followed by the active user
+	// count.
+	dayStatsScript = `
+local stats = redis.call('HGETALL', KEYS[1])
+stats[#stats + 1] = tostring(redis.call('PFCOUNT', KEYS[2]))
+return stats`
+)
+
+type RedisStatsStore struct {
+	Pool *util.RedisHandler
+	// Retention is how long a day's counters are kept after it ends.
+	Retention time.Duration
+}
+
+func statsKey(day string) string {
+	return strings.Join([]string{"eventstats", day}, "_")
+}
+
+func usersKey(day string) string {
+	return strings.Join([]string{"eventusers", day}, "_")
+}
+
+func (r *RedisStatsStore) Add(records ...*EventRecord) error {
+	for day, t := range tally(records) {
+		// The keys outlive the day itself by the retention period
+		expires, err := time.Parse(dayFormat, day)
+		if err != nil {
+			return err
+		}
+		ttl := int(expires.AddDate(0, 0, 1).Add(r.Retention).Sub(time.Now()) / time.Second)
+		if ttl <= 0 {
+			continue
+		}
+
+		args := []interface{}{ttl, len(t.counts)}
+		for name, count := range t.counts {
+			args = append(args, name, count)
+		}
+		for user := range t.users {
+			args = append(args, user)
+		}
+		if _, err := r.Pool.Eval(addStatsScript, []string{statsKey(day), usersKey(day)}, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RedisStatsStore) Days(first time.Time, last time.Time) ([]*DayStats, error) {
+	var days []*DayStats
+	for _, day := range daysBetween(first, last) {
+		reply, err := r.Pool.Eval(dayStatsScript, []string{statsKey(day), usersKey(day)})
+		if err != nil {
+			return nil, err
+		}
+		values, err := reply.List()
+		if err != nil {
+			return nil, err
+		}
+		if len(values)%2 != 1 {
+			return nil, fmt.Errorf("unexpected reply from stats script for %s", day)
+		}
+
+		counts := make(map[string]int64, len(values)/2)
+		for i := 0; i+1 < len(values); i += 2 {
+			if counts[values[i]], err = strconv.ParseInt(values[i+1], 10, 64); err != nil {
+				return nil, err
+			}
+		}
+		users, err := strconv.ParseInt(values[len(values)-1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, newDayStats(day, counts, users))
+	}
+	return days, nil
+}
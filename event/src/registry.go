@@ -0,0 +1,171 @@
+/*
+// ----------------------------------------------------------------------------
+// registry.go
+// Countertop Server Event Recording Event Type Registry
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+const schemaExtension = ".json"
+
+var ErrUnknownEventType = errors.New("unknown event type")
+
+// EventRegistry holds a JSON Schema for the payload of every known event
+// type, and counts the events checked against it. A nil registry is valid,
+// it knows no types and checks nothing.
+type EventRegistry struct {
+	schemas map[string]*jsonSchema
+
+	mu    sync.Mutex
+	stats RegistryStats
+}
+
+// RegistryStats counts checked events by type.
+type RegistryStats struct {
+	Valid   map[string]uint64
+	Invalid map[string]uint64
+	Unknown map[string]uint64
+}
+
+func (s RegistryStats) String() string {
+	return fmt.Sprintf("%d valid, %d invalid (%s), %d of unknown types (%s)",
+		total(s.Valid), total(s.Invalid), countsByType(s.Invalid),
+		total(s.Unknown), countsByType(s.Unknown))
+}
+
+func total(counts map[string]uint64) uint64 {
+	var sum uint64
+	for _, count := range counts {
+		sum += count
+	}
+	return sum
+}
+
+func countsByType(counts map[string]uint64) string {
+	types := make([]string, 0, len(counts))
+	for eventType, count := range counts {
+		types = append(types, fmt.Sprintf("%q: %d", eventType, count))
+	}
+	sort.Strings(types)
+	return strings.Join(types, ", ")
+}
+
+// LoadEventRegistry reads one schema per event type from the directory. The
+// file name, less its .json extension, is the event name it applies to.
+func LoadEventRegistry(dir string) (*EventRegistry, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+schemaExtension))
+	if err != nil {
+		return nil, err
+	}
+	schemas := make(map[string]*jsonSchema, len(paths))
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := parseSchema(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		schemas[strings.TrimSuffix(filepath.Base(path), schemaExtension)] = schema
+	}
+	return newEventRegistry(schemas), nil
+}
+
+func newEventRegistry(schemas map[string]*jsonSchema) *EventRegistry {
+	return &EventRegistry{
+		schemas: schemas,
+		stats: RegistryStats{
+			Valid:   make(map[string]uint64),
+			Invalid: make(map[string]uint64),
+			Unknown: make(map[string]uint64),
+		},
+	}
+}
+
+// Types lists the registered event types.
+func (r *EventRegistry) Types() []string {
+	if r == nil {
+		return nil
+	}
+	types := make([]string, 0, len(r.schemas))
+	for eventType := range r.schemas {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Validate checks the event's payload against the schema for its name. It
+// returns ErrUnknownEventType for names without a schema, and describes the
+// first problem found with an invalid payload.
+func (r *EventRegistry) Validate(event *pb.Event) error {
+	if r == nil {
+		return nil
+	}
+	schema, ok := r.schemas[event.Name]
+	if !ok {
+		r.count(r.stats.Unknown, event.Name)
+		return ErrUnknownEventType
+	}
+
+	var payload interface{}
+	err := json.Unmarshal([]byte(event.JsonPayload), &payload)
+	if err != nil {
+		err = fmt.Errorf("payload is not valid JSON: %v", err)
+	} else {
+		err = schema.validate(payload, "payload")
+	}
+	if err != nil {
+		r.count(r.stats.Invalid, event.Name)
+		return err
+	}
+	r.count(r.stats.Valid, event.Name)
+	return nil
+}
+
+func (r *EventRegistry) count(counts map[string]uint64, eventType string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts[eventType]++
+}
+
+func (r *EventRegistry) Stats() RegistryStats {
+	stats := RegistryStats{
+		Valid:   make(map[string]uint64),
+		Invalid: make(map[string]uint64),
+		Unknown: make(map[string]uint64),
+	}
+	if r == nil {
+		return stats
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, counts := range []struct{ from, to map[string]uint64 }{
+		{r.stats.Valid, stats.Valid},
+		{r.stats.Invalid, stats.Invalid},
+		{r.stats.Unknown, stats.Unknown},
+	} {
+		for eventType, count := range counts.from {
+			counts.to[eventType] = count
+		}
+	}
+	return stats
+}
@@ -0,0 +1,131 @@
+/*
+// ----------------------------------------------------------------------------
+// registry_test.go
+// Countertop Server Event Recording Event Type Registry Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	event "github.com/theorangechefco/cts/event"
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+const recipeViewedSchema = `{
+	"$schema": "http://json-schema.org/draft-04/schema#",
+	"description": "A recipe was opened",
+	"type": "object",
+	"required": ["recipe", "source"],
+	"additionalProperties": false,
+	"properties": {
+		"recipe": {"type": "string", "minLength": 1},
+		"source": {"enum": ["search", "pack", "plan"]},
+		"seconds": {"type": "integer", "minimum": 0},
+		"tags": {"type": "array", "maxItems": 3, "items": {"type": "string", "pattern": "^[a-z]+$"}},
+		"scale": {"type": ["number", "null"], "maximum": 10}
+	}
+}`
+
+func writeSchemas(t *testing.T, schemas map[string]string) string {
+	dir, err := ioutil.TempDir("", "eventtypes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, schema := range schemas {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(schema), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestEventRegistryValidate(t *testing.T) {
+	dir := writeSchemas(t, map[string]string{"recipe_viewed.json": recipeViewedSchema, "README": "not a schema"})
+	defer os.RemoveAll(dir)
+	registry, err := event.LoadEventRegistry(dir)
+	if err != nil {
+		t.Fatalf("LoadEventRegistry(_) = _, %v", err)
+	}
+	if types := registry.Types(); !reflect.DeepEqual(types, []string{"recipe_viewed"}) {
+		t.Errorf("Types() = %v, want [recipe_viewed]", types)
+	}
+
+	payloads := map[string]bool{
+		`{"recipe": "41", "source": "pack"}`:                                  true,
+		`{"recipe": "41", "source": "plan", "seconds": 90, "tags": ["soup"]}`: true,
+		`{"recipe": "41", "source": "plan", "scale": 1.5}`:                    true,
+		`{"recipe": "41", "source": "plan", "scale": null}`:                   true,
+		`{"recipe": "41"}`:                                                 false,
+		`{"recipe": "", "source": "pack"}`:                                 false,
+		`{"recipe": 41, "source": "pack"}`:                                 false,
+		`{"recipe": "41", "source": "email"}`:                              false,
+		`{"recipe": "41", "source": "pack", "seconds": 1.5}`:               false,
+		`{"recipe": "41", "source": "pack", "seconds": -1}`:                false,
+		`{"recipe": "41", "source": "pack", "tags": ["Soup"]}`:             false,
+		`{"recipe": "41", "source": "pack", "tags": ["a", "b", "c", "d"]}`: false,
+		`{"recipe": "41", "source": "pack", "scale": 11}`:                  false,
+		`{"recipe": "41", "source": "pack", "extra": true}`:                false,
+		`["recipe", "41"]`:                                                 false,
+		`{"recipe": "41", "source": "pack"`:                                false,
+	}
+	valid, invalid := 0, 0
+	for payload, want := range payloads {
+		err := registry.Validate(&pb.Event{Name: "recipe_viewed", JsonPayload: payload})
+		if (err == nil) != want {
+			t.Errorf("Validate(%s) = %v, want valid %t", payload, err, want)
+		}
+		if want {
+			valid++
+		} else {
+			invalid++
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := registry.Validate(&pb.Event{Name: "recipe_viewd"}); err != event.ErrUnknownEventType {
+			t.Errorf("Validate(recipe_viewd) = %v, want %v", err, event.ErrUnknownEventType)
+		}
+	}
+
+	stats := registry.Stats()
+	if stats.Valid["recipe_viewed"] != uint64(valid) || stats.Invalid["recipe_viewed"] != uint64(invalid) ||
+		stats.Unknown["recipe_viewd"] != 2 {
+		t.Errorf("Stats() = %v", stats)
+	}
+}
+
+func TestLoadEventRegistryRejectsBadSchemas(t *testing.T) {
+	schemas := map[string]string{
+		"malformed":           `{"type": "object"`,
+		"unknown type":        `{"type": "map"}`,
+		"unsupported keyword": `{"type": "object", "patternProperties": {}}`,
+		"nested":              `{"properties": {"recipe": {"type": "text"}}}`,
+		"bad pattern":         `{"pattern": "("}`,
+	}
+	for name, schema := range schemas {
+		dir := writeSchemas(t, map[string]string{"recipe_viewed.json": schema})
+		if _, err := event.LoadEventRegistry(dir); err == nil {
+			t.Errorf("%s: LoadEventRegistry(_) = _, nil, want error", name)
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+func TestNilEventRegistry(t *testing.T) {
+	var registry *event.EventRegistry
+	if err := registry.Validate(&pb.Event{Name: "recipe_viewed", JsonPayload: "not json"}); err != nil {
+		t.Errorf("nil Validate(_) = %v, want nil", err)
+	}
+	if stats := registry.Stats(); len(stats.Unknown) != 0 {
+		t.Errorf("nil Stats() = %v", stats)
+	}
+}
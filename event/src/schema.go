@@ -0,0 +1,239 @@
+/*
+// ----------------------------------------------------------------------------
+// schema.go
+// Countertop Server Event Recording Payload Schemas
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// jsonSchema is the subset of JSON Schema draft 4 used to describe event
+// payloads. Schemas using any other validation keyword are refused when
+// they are loaded, rather than silently accepting everything.
+type jsonSchema struct {
+	types                []string
+	properties           map[string]*jsonSchema
+	required             []string
+	additionalProperties *jsonSchema
+	noAdditional         bool
+	items                *jsonSchema
+	enum                 []interface{}
+	minimum, maximum     *float64
+	minLength, maxLength *int
+	minItems, maxItems   *int
+	pattern              *regexp.Regexp
+}
+
+var schemaTypes = map[string]bool{"object": true, "array": true, "string": true,
+	"number": true, "integer": true, "boolean": true, "null": true}
+
+// Keywords that only describe a schema and are ignored when validating.
+var schemaAnnotations = map[string]bool{"$schema": true, "id": true, "title": true,
+	"description": true, "default": true}
+
+func parseSchema(data []byte) (*jsonSchema, error) {
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return nil, fmt.Errorf("schema must be a JSON object: %v", err)
+	}
+
+	schema := &jsonSchema{}
+	for keyword, value := range keywords {
+		var err error
+		switch keyword {
+		case "type":
+			err = schema.parseType(value)
+		case "properties":
+			var properties map[string]json.RawMessage
+			if err = json.Unmarshal(value, &properties); err != nil {
+				break
+			}
+			schema.properties = make(map[string]*jsonSchema, len(properties))
+			for name, property := range properties {
+				if schema.properties[name], err = parseSchema(property); err != nil {
+					err = fmt.Errorf("property %q: %v", name, err)
+					break
+				}
+			}
+		case "required":
+			err = json.Unmarshal(value, &schema.required)
+		case "additionalProperties":
+			var allowed bool
+			if json.Unmarshal(value, &allowed) == nil {
+				schema.noAdditional = !allowed
+			} else {
+				schema.additionalProperties, err = parseSchema(value)
+			}
+		case "items":
+			schema.items, err = parseSchema(value)
+		case "enum":
+			err = json.Unmarshal(value, &schema.enum)
+		case "minimum":
+			err = json.Unmarshal(value, &schema.minimum)
+		case "maximum":
+			err = json.Unmarshal(value, &schema.maximum)
+		case "minLength":
+			err = json.Unmarshal(value, &schema.minLength)
+		case "maxLength":
+			err = json.Unmarshal(value, &schema.maxLength)
+		case "minItems":
+			err = json.Unmarshal(value, &schema.minItems)
+		case "maxItems":
+			err = json.Unmarshal(value, &schema.maxItems)
+		case "pattern":
+			var pattern string
+			if err = json.Unmarshal(value, &pattern); err == nil {
+				schema.pattern, err = regexp.Compile(pattern)
+			}
+		default:
+			if !schemaAnnotations[keyword] {
+				err = fmt.Errorf("unsupported keyword")
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", keyword, err)
+		}
+	}
+	return schema, nil
+}
+
+func (s *jsonSchema) parseType(value json.RawMessage) error {
+	var name string
+	if json.Unmarshal(value, &name) == nil {
+		s.types = []string{name}
+	} else if err := json.Unmarshal(value, &s.types); err != nil {
+		return err
+	}
+	for _, name := range s.types {
+		if !schemaTypes[name] {
+			return fmt.Errorf("unknown type %q", name)
+		}
+	}
+	return nil
+}
+
+// validate checks a value decoded by encoding/json against the schema. path
+// names the value in errors.
+func (s *jsonSchema) validate(value interface{}, path string) error {
+	if len(s.types) > 0 && !s.hasType(value) {
+		return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(s.types, " or "), jsonType(value))
+	}
+	if s.enum != nil {
+		found := false
+		for _, allowed := range s.enum {
+			if reflect.DeepEqual(value, allowed) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of the allowed values", path, value)
+		}
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if s.minimum != nil && v < *s.minimum {
+			return fmt.Errorf("%s: %v is less than %v", path, v, *s.minimum)
+		}
+		if s.maximum != nil && v > *s.maximum {
+			return fmt.Errorf("%s: %v is greater than %v", path, v, *s.maximum)
+		}
+	case string:
+		length := len([]rune(v))
+		if s.minLength != nil && length < *s.minLength {
+			return fmt.Errorf("%s: shorter than %d characters", path, *s.minLength)
+		}
+		if s.maxLength != nil && length > *s.maxLength {
+			return fmt.Errorf("%s: longer than %d characters", path, *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			return fmt.Errorf("%s: %q does not match %s", path, v, s.pattern)
+		}
+	case []interface{}:
+		if s.minItems != nil && len(v) < *s.minItems {
+			return fmt.Errorf("%s: fewer than %d items", path, *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			return fmt.Errorf("%s: more than %d items", path, *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				if err := s.items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		// Sorted so the same payload always reports the same error
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := s.properties[name]
+			if !ok {
+				if s.noAdditional {
+					return fmt.Errorf("%s: unexpected property %q", path, name)
+				}
+				property = s.additionalProperties
+			}
+			if property == nil {
+				continue
+			}
+			if err := property.validate(v[name], path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) hasType(value interface{}) bool {
+	actual := jsonType(value)
+	for _, name := range s.types {
+		if name == actual || (name == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -47,8 +47,13 @@ func main() {
 	// if err := session.Query("INSERT INTO users (lastname, age, city, email, firstname) VALUES ('Jones', 35, 'Austin', 'bob@example.com', 'Bob')").Exec(); err != nil {
 	session.Query("DROP TABLE events;").Exec()
 
+	// Events are partitioned by user and ordered newest first within the
+	// partition, so a user's events can be read back by time range
 	createString := `CREATE TABLE events (
-                      id timeuuid PRIMARY KEY,
+                      userid uuid,
+                      occurredat timestamp,
+                      id timeuuid,
+                      name varchar,
                       version int,
                       apprelease ascii,
                       appversion ascii,
@@ -65,9 +70,14 @@ func main() {
                       screenheight int,
                       screenwidth int,
                       wifi boolean,
+                      ip inet,
+                      geocity varchar,
+                      georegion ascii,
+                      geocountry ascii,
                       createdat timestamp,
-                      payload text
-                    );`
+                      payload text,
+                      PRIMARY KEY ((userid), occurredat, id)
+                    ) WITH CLUSTERING ORDER BY (occurredat DESC, id DESC);`
 	if err := session.Query(createString).Exec(); err != nil {
 		logger.Fatal(err)
 	}
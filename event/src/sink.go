@@ -0,0 +1,277 @@
+/*
+// ----------------------------------------------------------------------------
+// sink.go
+// Countertop Server Event Recording Sinks
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+// EventRecord is an event as it is recorded, with the ID and time assigned
+// when it was received and the UUID of the user whose session token came
+// with it. Occurredat is the time the client reported for the event, or the
+// time it was received if the client did not report one. IP is the address
+// the event came from, truncated if the server is set to, and Location is
+// where that address is, if known.
+type EventRecord struct {
+	ID         gocql.UUID
+	UserID     string
+	Occurredat time.Time
+	Createdat  time.Time
+	IP         string
+	Location   Location
+	Event      *pb.Event
+}
+
+// NewEventRecord assigns an ID to an event received now from the user.
+func NewEventRecord(userID string, event *pb.Event) *EventRecord {
+	now := time.Now()
+	record := &EventRecord{
+		ID:         gocql.UUIDFromTime(now),
+		UserID:     userID,
+		Occurredat: now,
+		Createdat:  now,
+		Event:      event,
+	}
+	if event.Timestamp != nil && event.Timestamp.Seconds > 0 {
+		record.Occurredat = time.Unix(event.Timestamp.Seconds, 0)
+	}
+	return record
+}
+
+// Rows fetched per page when reading events back.
+const readPageSize = 1000
+
+// EventSink persists events. Write stores a batch of records as a unit where
+// the backend allows it. Implementations must be safe for concurrent use.
+type EventSink interface {
+	Write(records ...*EventRecord) error
+	Close() error
+}
+
+// Columns of the events table, in the order eventRow lists them.
+var eventColumns = []string{"id", "userid", "name", "occurredat", "version", "apprelease", "appversion",
+	"carrier", "city", "country", "devicemodel", "manufacturer", "model",
+	"osversion", "operatingsystem", "radio", "region", "screenheight",
+	"screenwidth", "wifi", "ip", "geocity", "georegion", "geocountry",
+	"createdat", "payload"}
+
+// eventRow flattens a record into the columns of the events table, which
+// the file and SQL sinks share.
+type eventRow struct {
+	ID              string `json:"id"`
+	UserID          string `json:"userid"`
+	Name            string `json:"name"`
+	Occurredat      int64  `json:"occurredat"`
+	Version         int32  `json:"version"`
+	Apprelease      string `json:"apprelease"`
+	Appversion      string `json:"appversion"`
+	Carrier         string `json:"carrier"`
+	City            string `json:"city"`
+	Country         string `json:"country"`
+	Devicemodel     string `json:"devicemodel"`
+	Manufacturer    string `json:"manufacturer"`
+	Model           string `json:"model"`
+	Osversion       string `json:"osversion"`
+	Operatingsystem string `json:"operatingsystem"`
+	Radio           string `json:"radio"`
+	Region          string `json:"region"`
+	Screenheight    int32  `json:"screenheight"`
+	Screenwidth     int32  `json:"screenwidth"`
+	Wifi            bool   `json:"wifi"`
+	IP              string `json:"ip"`
+	Geocity         string `json:"geocity"`
+	Georegion       string `json:"georegion"`
+	Geocountry      string `json:"geocountry"`
+	Createdat       int64  `json:"createdat"`
+	Payload         string `json:"payload"`
+}
+
+func newEventRow(record *EventRecord) *eventRow {
+	event := record.Event
+	return &eventRow{
+		ID:              record.ID.String(),
+		UserID:          record.UserID,
+		Name:            event.Name,
+		Occurredat:      record.Occurredat.Unix(),
+		Version:         event.Version,
+		Apprelease:      event.Apprelease,
+		Appversion:      event.Appversion,
+		Carrier:         event.Carrier,
+		City:            event.City,
+		Country:         event.Country,
+		Devicemodel:     event.Devicemodel,
+		Manufacturer:    event.Manufacturer,
+		Model:           event.Model,
+		Osversion:       event.Osversion,
+		Operatingsystem: event.Operatingsystem,
+		Radio:           event.Radio,
+		Region:          event.Region,
+		Screenheight:    event.Screenheight,
+		Screenwidth:     event.Screenwidth,
+		Wifi:            event.Wifi,
+		IP:              record.IP,
+		Geocity:         record.Location.City,
+		Georegion:       record.Location.Region,
+		Geocountry:      record.Location.Country,
+		Createdat:       record.Createdat.Unix(),
+		Payload:         event.JsonPayload,
+	}
+}
+
+// record rebuilds the record a row was written from.
+func (r *eventRow) record() (*EventRecord, error) {
+	id, err := gocql.ParseUUID(r.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &EventRecord{
+		ID:         id,
+		UserID:     r.UserID,
+		Occurredat: time.Unix(r.Occurredat, 0),
+		Createdat:  time.Unix(r.Createdat, 0),
+		IP:         r.IP,
+		Location:   Location{City: r.Geocity, Region: r.Georegion, Country: r.Geocountry},
+		Event: &pb.Event{
+			Version:         r.Version,
+			Name:            r.Name,
+			Apprelease:      r.Apprelease,
+			Appversion:      r.Appversion,
+			Carrier:         r.Carrier,
+			City:            r.City,
+			Country:         r.Country,
+			Devicemodel:     r.Devicemodel,
+			Manufacturer:    r.Manufacturer,
+			Model:           r.Model,
+			Osversion:       r.Osversion,
+			Operatingsystem: r.Operatingsystem,
+			Radio:           r.Radio,
+			Region:          r.Region,
+			Screenheight:    r.Screenheight,
+			Screenwidth:     r.Screenwidth,
+			Wifi:            r.Wifi,
+			JsonPayload:     r.Payload,
+		},
+	}, nil
+}
+
+// values lists the row in column order.
+func (r *eventRow) values() []interface{} {
+	return []interface{}{r.ID, r.UserID, r.Name, r.Occurredat, r.Version, r.Apprelease, r.Appversion,
+		r.Carrier, r.City, r.Country, r.Devicemodel, r.Manufacturer, r.Model,
+		r.Osversion, r.Operatingsystem, r.Radio, r.Region, r.Screenheight,
+		r.Screenwidth, r.Wifi, r.IP, r.Geocity, r.Georegion, r.Geocountry,
+		r.Createdat, r.Payload}
+}
+
+// pointers lists pointers to the row's fields in column order, for scanning.
+func (r *eventRow) pointers() []interface{} {
+	return []interface{}{&r.ID, &r.UserID, &r.Name, &r.Occurredat, &r.Version, &r.Apprelease, &r.Appversion,
+		&r.Carrier, &r.City, &r.Country, &r.Devicemodel, &r.Manufacturer, &r.Model,
+		&r.Osversion, &r.Operatingsystem, &r.Radio, &r.Region, &r.Screenheight,
+		&r.Screenwidth, &r.Wifi, &r.IP, &r.Geocity, &r.Georegion, &r.Geocountry,
+		&r.Createdat, &r.Payload}
+}
+
+//
+// Cassandra sink
+//
+
+const insertTemplate string = `INSERT INTO events (id, userid, name, occurredat, version, apprelease, appversion,
+	 carrier, city, country, devicemodel, manufacturer, model , osversion , operatingsystem , radio,
+	 region, screenheight, screenwidth, wifi, ip, geocity, georegion, geocountry, createdat, payload) VALUES
+	 (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+
+const selectTemplate string = `SELECT id, userid, name, occurredat, version, apprelease, appversion,
+	 carrier, city, country, devicemodel, manufacturer, model , osversion , operatingsystem , radio,
+	 region, screenheight, screenwidth, wifi, ip, geocity, georegion, geocountry, createdat, payload FROM events`
+
+type CassandraSink struct {
+	Session *gocql.Session
+}
+
+// Batches are unlogged, events are independent of each other.
+func (c *CassandraSink) Write(records ...*EventRecord) error {
+	batch := c.Session.NewBatch(gocql.UnloggedBatch)
+	for _, record := range records {
+		event := record.Event
+		batch.Query(insertTemplate, record.ID, record.UserID, event.Name, record.Occurredat, event.Version, event.Apprelease,
+			event.Appversion, event.Carrier, event.City, event.Country,
+			event.Devicemodel, event.Manufacturer, event.Model, event.Osversion,
+			event.Operatingsystem, event.Radio, event.Region, event.Screenheight,
+			event.Screenwidth, event.Wifi, inetValue(record.IP), record.Location.City,
+			record.Location.Region, record.Location.Country, record.Createdat, event.JsonPayload)
+	}
+	return c.Session.ExecuteBatch(batch)
+}
+
+// inetValue stores unknown addresses as null, which an inet column takes
+// where it would refuse an empty string.
+func inetValue(ip string) interface{} {
+	if ip == "" {
+		return nil
+	}
+	return ip
+}
+
+// Read restricts the user and time range in the query itself, the rest of
+// the query is matched as rows are read. A user's events come back newest
+// first. Queries without a user scan the whole table.
+func (c *CassandraSink) Read(query *EventQuery, fn func(*EventRecord) error) error {
+	var where []string
+	var args []interface{}
+	if query.UserID != "" {
+		where, args = append(where, "userid = ?"), append(args, query.UserID)
+	}
+	if !query.Start.IsZero() {
+		where, args = append(where, "occurredat >= ?"), append(args, query.Start)
+	}
+	if !query.End.IsZero() {
+		where, args = append(where, "occurredat < ?"), append(args, query.End)
+	}
+	statement := selectTemplate
+	if len(where) > 0 {
+		statement += " WHERE " + strings.Join(where, " AND ")
+		if query.UserID == "" {
+			statement += " ALLOW FILTERING"
+		}
+	}
+
+	iter := c.Session.Query(statement, args...).PageSize(readPageSize).Iter()
+	for {
+		record := &EventRecord{Event: &pb.Event{}}
+		event := record.Event
+		if !iter.Scan(&record.ID, &record.UserID, &event.Name, &record.Occurredat, &event.Version,
+			&event.Apprelease, &event.Appversion, &event.Carrier, &event.City, &event.Country,
+			&event.Devicemodel, &event.Manufacturer, &event.Model, &event.Osversion,
+			&event.Operatingsystem, &event.Radio, &event.Region, &event.Screenheight,
+			&event.Screenwidth, &event.Wifi, &record.IP, &record.Location.City,
+			&record.Location.Region, &record.Location.Country, &record.Createdat, &event.JsonPayload) {
+			break
+		}
+		if !query.Matches(record) {
+			continue
+		}
+		if err := fn(record); err != nil {
+			iter.Close()
+			return err
+		}
+	}
+	return iter.Close()
+}
+
+func (c *CassandraSink) Close() error {
+	c.Session.Close()
+	return nil
+}
@@ -0,0 +1,242 @@
+/*
+// ----------------------------------------------------------------------------
+// sqlsink.go
+// Countertop Server Event Recording SQL Sink
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+)
+
+// Column types valid for both MySQL and PostgreSQL. Timestamps are stored in
+// seconds since the epoch.
+const createTableTemplate string = `CREATE TABLE IF NOT EXISTS events (
+	id VARCHAR(36) PRIMARY KEY,
+	userid VARCHAR(36) NOT NULL,
+	name VARCHAR(255),
+	occurredat BIGINT,
+	version INTEGER,
+	apprelease VARCHAR(255),
+	appversion VARCHAR(255),
+	carrier VARCHAR(255),
+	city VARCHAR(255),
+	country VARCHAR(255),
+	devicemodel VARCHAR(255),
+	manufacturer VARCHAR(255),
+	model VARCHAR(255),
+	osversion VARCHAR(255),
+	operatingsystem VARCHAR(255),
+	radio VARCHAR(255),
+	region VARCHAR(255),
+	screenheight INTEGER,
+	screenwidth INTEGER,
+	wifi BOOLEAN,
+	ip VARCHAR(45),
+	geocity VARCHAR(255),
+	georegion VARCHAR(255),
+	geocountry VARCHAR(2),
+	createdat BIGINT,
+	payload TEXT
+);`
+
+// Columns added to the events table since it was first created, with the
+// definitions that add them to an existing table. Existing rows get the
+// default, so reading them back does not meet NULLs.
+var addedColumns = []struct {
+	name       string
+	definition string
+}{
+	{"userid", "VARCHAR(36) NOT NULL DEFAULT ''"},
+	{"name", "VARCHAR(255) NOT NULL DEFAULT ''"},
+	{"occurredat", "BIGINT NOT NULL DEFAULT 0"},
+	{"ip", "VARCHAR(45) NOT NULL DEFAULT ''"},
+	{"geocity", "VARCHAR(255) NOT NULL DEFAULT ''"},
+	{"georegion", "VARCHAR(255) NOT NULL DEFAULT ''"},
+	{"geocountry", "VARCHAR(2) NOT NULL DEFAULT ''"},
+}
+
+// Serves queries for a user's events over a time range.
+const userTimeIndex = "events_userid_occurredat"
+
+// SQLSink inserts events into an events table in MySQL or PostgreSQL.
+type SQLSink struct {
+	DB     *sql.DB
+	driver string
+	insert string
+}
+
+// NewSQLSink connects using a mysql or postgres driver, and creates the
+// events table if it does not exist or migrates it to the current columns
+// and indexes if it does.
+func NewSQLSink(driver string, dataSource string) (*SQLSink, error) {
+	if driver != "mysql" && driver != "postgres" {
+		return nil, fmt.Errorf("unsupported SQL driver %q, expected mysql or postgres", driver)
+	}
+	db, err := sql.Open(driver, dataSource)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(createTableTemplate); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cannot create events table: %v", err)
+	}
+
+	sink := &SQLSink{DB: db, driver: driver}
+	if err := sink.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cannot migrate events table: %v", err)
+	}
+	placeholders := make([]string, len(eventColumns))
+	for i := range placeholders {
+		placeholders[i] = sink.placeholder(i + 1)
+	}
+	sink.insert = fmt.Sprintf("INSERT INTO events (%s) VALUES (%s)",
+		strings.Join(eventColumns, ", "), strings.Join(placeholders, ", "))
+	return sink, nil
+}
+
+// migrate adds the columns and indexes that an events table created by an
+// earlier version lacks. Events stored before occurredat was added are taken
+// to have occurred when they were received.
+func (s *SQLSink) migrate() error {
+	rows, err := s.DB.Query("SELECT * FROM events WHERE 1 = 0")
+	if err != nil {
+		return err
+	}
+	columns, err := rows.Columns()
+	rows.Close()
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		existing[strings.ToLower(column)] = true
+	}
+
+	for _, column := range addedColumns {
+		if existing[column.name] {
+			continue
+		}
+		if _, err := s.DB.Exec(fmt.Sprintf("ALTER TABLE events ADD COLUMN %s %s", column.name, column.definition)); err != nil {
+			return fmt.Errorf("cannot add column %s: %v", column.name, err)
+		}
+		if column.name == "occurredat" {
+			if _, err := s.DB.Exec("UPDATE events SET occurredat = createdat"); err != nil {
+				return fmt.Errorf("cannot fill in column occurredat: %v", err)
+			}
+		}
+	}
+
+	// Neither database supports CREATE INDEX IF NOT EXISTS in every version
+	// in use, so the catalog is checked first
+	lookup := "SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = 'events' AND index_name = ?"
+	if s.driver == "postgres" {
+		lookup = "SELECT COUNT(*) FROM pg_indexes WHERE schemaname = current_schema() AND tablename = 'events' AND indexname = $1"
+	}
+	var indexes int
+	if err := s.DB.QueryRow(lookup, userTimeIndex).Scan(&indexes); err != nil {
+		return fmt.Errorf("cannot look up index %s: %v", userTimeIndex, err)
+	}
+	if indexes == 0 {
+		if _, err := s.DB.Exec(fmt.Sprintf("CREATE INDEX %s ON events (userid, occurredat)", userTimeIndex)); err != nil {
+			return fmt.Errorf("cannot create index %s: %v", userTimeIndex, err)
+		}
+	}
+	return nil
+}
+
+// placeholder is the driver's bind parameter for the nth argument, counting
+// from 1.
+func (s *SQLSink) placeholder(n int) string {
+	if s.driver == "postgres" {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// Each batch is inserted in one transaction.
+func (s *SQLSink) Write(records ...*EventRecord) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	statement, err := tx.Prepare(s.insert)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer statement.Close()
+
+	for _, record := range records {
+		if _, err := statement.Exec(newEventRow(record).values()...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Read matches the whole query in SQL and returns events in the order they
+// occurred.
+func (s *SQLSink) Read(query *EventQuery, fn func(*EventRecord) error) error {
+	var where []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(condition, s.placeholder(len(args))))
+	}
+	if query.UserID != "" {
+		add("userid = %s", query.UserID)
+	}
+	if query.Name != "" {
+		add("name = %s", query.Name)
+	}
+	if query.Appversion != "" {
+		add("appversion = %s", query.Appversion)
+	}
+	if !query.Start.IsZero() {
+		add("occurredat >= %s", query.Start.Unix())
+	}
+	if !query.End.IsZero() {
+		add("occurredat < %s", query.End.Unix())
+	}
+	statement := fmt.Sprintf("SELECT %s FROM events", strings.Join(eventColumns, ", "))
+	if len(where) > 0 {
+		statement += " WHERE " + strings.Join(where, " AND ")
+	}
+	statement += " ORDER BY occurredat, id"
+
+	rows, err := s.DB.Query(statement, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		row := &eventRow{}
+		if err := rows.Scan(row.pointers()...); err != nil {
+			return err
+		}
+		record, err := row.record()
+		if err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *SQLSink) Close() error {
+	return s.DB.Close()
+}
@@ -0,0 +1,251 @@
+/*
+// ----------------------------------------------------------------------------
+// stats.go
+// Countertop Server Event Recording Daily Counters
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Days are UTC dates in this format.
+const dayFormat = "2006-01-02"
+
+// Counter names, most followed by the value counted, as stored by the stats
+// stores.
+const (
+	eventsCounter     = "events"
+	typeCounter       = "type:"
+	appversionCounter = "appversion:"
+	osCounter         = "os:"
+)
+
+// DayStats counts the events received on a day, in total and by event type,
+// app version and operating system, and the distinct users who sent them.
+type DayStats struct {
+	Day              string
+	Events           int64
+	ActiveUsers      int64
+	EventTypes       map[string]int64
+	Appversions      map[string]int64
+	Operatingsystems map[string]int64
+}
+
+// StatsStore keeps daily counters of recorded events for as long as its
+// retention period. Events count toward the day they were received.
+type StatsStore interface {
+	// Add counts the records. Implementations must be safe for concurrent
+	// use.
+	Add(records ...*EventRecord) error
+	// Days returns the counters for every day from first to last,
+	// inclusive, with empty counters for days without events.
+	Days(first time.Time, last time.Time) ([]*DayStats, error)
+}
+
+func dayOf(t time.Time) string {
+	return t.UTC().Format(dayFormat)
+}
+
+// daysBetween lists the days from first to last, inclusive.
+func daysBetween(first time.Time, last time.Time) []string {
+	var days []string
+	end := dayOf(last)
+	for day := first.UTC(); ; day = day.AddDate(0, 0, 1) {
+		days = append(days, dayOf(day))
+		if dayOf(day) >= end {
+			return days
+		}
+	}
+}
+
+// dayTally holds the counters for a day, keyed by counter name.
+type dayTally struct {
+	counts map[string]int64
+	users  map[string]bool
+}
+
+func newDayTally() *dayTally {
+	return &dayTally{counts: make(map[string]int64), users: make(map[string]bool)}
+}
+
+// tally counts a batch of records by day.
+func tally(records []*EventRecord) map[string]*dayTally {
+	days := make(map[string]*dayTally)
+	for _, record := range records {
+		day := dayOf(record.Createdat)
+		t, ok := days[day]
+		if !ok {
+			t = newDayTally()
+			days[day] = t
+		}
+		t.counts[eventsCounter]++
+		t.counts[typeCounter+record.Event.Name]++
+		t.counts[appversionCounter+record.Event.Appversion]++
+		t.counts[osCounter+record.Event.Operatingsystem]++
+		if record.UserID != "" {
+			t.users[record.UserID] = true
+		}
+	}
+	return days
+}
+
+// newDayStats sorts named counters into their groups.
+func newDayStats(day string, counts map[string]int64, activeUsers int64) *DayStats {
+	stats := &DayStats{
+		Day:              day,
+		ActiveUsers:      activeUsers,
+		EventTypes:       make(map[string]int64),
+		Appversions:      make(map[string]int64),
+		Operatingsystems: make(map[string]int64),
+	}
+	for name, count := range counts {
+		switch {
+		case name == eventsCounter:
+			stats.Events = count
+		case strings.HasPrefix(name, typeCounter):
+			stats.EventTypes[strings.TrimPrefix(name, typeCounter)] = count
+		case strings.HasPrefix(name, appversionCounter):
+			stats.Appversions[strings.TrimPrefix(name, appversionCounter)] = count
+		case strings.HasPrefix(name, osCounter):
+			stats.Operatingsystems[strings.TrimPrefix(name, osCounter)] = count
+		}
+	}
+	return stats
+}
+
+// StatsQueue adds batches to a StatsStore from its own goroutine, so that a
+// slow store never holds up the buffer writing events. The queue holds up to
+// size batches; batches that arrive while it is full are dropped, and their
+// events go uncounted.
+type StatsQueue struct {
+	// Counted by Dropped, first for 64 bit alignment
+	dropped int64
+
+	Store StatsStore
+	// Failed, when set, is called with the batches the store could not add,
+	// from the queue's goroutine.
+	Failed func(records []*EventRecord, err error)
+
+	queue chan []*EventRecord
+	done  chan struct{}
+}
+
+func NewStatsQueue(store StatsStore, size int) *StatsQueue {
+	q := &StatsQueue{
+		Store: store,
+		queue: make(chan []*EventRecord, size),
+		done:  make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// Add queues the batch without waiting, and returns false if the queue is
+// full and the batch was dropped.
+func (q *StatsQueue) Add(records []*EventRecord) bool {
+	select {
+	case q.queue <- records:
+		return true
+	default:
+		atomic.AddInt64(&q.dropped, 1)
+		return false
+	}
+}
+
+// Dropped returns the number of batches dropped so far.
+func (q *StatsQueue) Dropped() int64 {
+	return atomic.LoadInt64(&q.dropped)
+}
+
+func (q *StatsQueue) run() {
+	defer close(q.done)
+	for records := range q.queue {
+		if err := q.Store.Add(records...); err != nil && q.Failed != nil {
+			q.Failed(records, err)
+		}
+	}
+}
+
+// Close adds the batches still queued and stops the queue. Add must not be
+// called after Close.
+func (q *StatsQueue) Close() {
+	close(q.queue)
+	<-q.done
+}
+
+//
+// In-process stats store, for tests and single node development setups
+//
+
+type MemoryStatsStore struct {
+	// Now returns the current time, and can be replaced in tests.
+	Now func() time.Time
+	// Retention is how long a day's counters are kept after it ends.
+	Retention time.Duration
+
+	mu   sync.Mutex
+	days map[string]*dayTally
+}
+
+func NewMemoryStatsStore(retention time.Duration) *MemoryStatsStore {
+	return &MemoryStatsStore{
+		Now:       time.Now,
+		Retention: retention,
+		days:      make(map[string]*dayTally),
+	}
+}
+
+func (m *MemoryStatsStore) Add(records ...*EventRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for day, batch := range tally(records) {
+		t, ok := m.days[day]
+		if !ok {
+			t = newDayTally()
+			m.days[day] = t
+		}
+		for name, count := range batch.counts {
+			t.counts[name] += count
+		}
+		for user := range batch.users {
+			t.users[user] = true
+		}
+	}
+	m.sweep()
+	return nil
+}
+
+func (m *MemoryStatsStore) Days(first time.Time, last time.Time) ([]*DayStats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep()
+	var days []*DayStats
+	for _, day := range daysBetween(first, last) {
+		t, ok := m.days[day]
+		if !ok {
+			t = newDayTally()
+		}
+		days = append(days, newDayStats(day, t.counts, int64(len(t.users))))
+	}
+	return days, nil
+}
+
+// sweep drops days past their retention. Callers must hold m.mu.
+func (m *MemoryStatsStore) sweep() {
+	oldest := dayOf(m.Now().Add(-m.Retention))
+	for day := range m.days {
+		if day < oldest {
+			delete(m.days, day)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+/*
+// ----------------------------------------------------------------------------
+// stats_test.go
+// Countertop Server Event Recording Daily Counter Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package event_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	event "github.com/theorangechefco/cts/event"
+	"golang.org/x/net/context"
+)
+
+func statsRecord(userID string, name string, appversion string, received time.Time) *event.EventRecord {
+	record := testRecord("Nexus 5")
+	record.UserID, record.Createdat = userID, received
+	record.Event.Name, record.Event.Appversion, record.Event.Operatingsystem = name, appversion, "android"
+	return record
+}
+
+func TestMemoryStatsStore(t *testing.T) {
+	day := time.Date(2015, 12, 9, 0, 0, 0, 0, time.UTC)
+	store := event.NewMemoryStatsStore(30 * 24 * time.Hour)
+	store.Now = func() time.Time { return day.Add(36 * time.Hour) }
+
+	otherUser := "0b5cc1b0-a6ba-4b0b-9f7b-1a1b2f7f0e6a"
+	store.Add(
+		statsRecord(testUserID, "app_open", "1.2", day.Add(time.Hour)),
+		statsRecord(testUserID, "recipe_viewed", "1.2", day.Add(2*time.Hour)),
+		statsRecord(otherUser, "app_open", "1.3", day.Add(23*time.Hour)))
+	store.Add(statsRecord(testUserID, "app_open", "1.3", day.Add(25*time.Hour)))
+
+	days, err := store.Days(day.Add(-24*time.Hour), day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("Days(_) = _, %v", err)
+	}
+	if len(days) != 3 || days[0].Day != "2015-12-08" || days[1].Day != "2015-12-09" || days[2].Day != "2015-12-10" {
+		t.Fatalf("Days(_) = %v, want 2015-12-08 to 2015-12-10", days)
+	}
+	if days[0].Events != 0 || days[0].ActiveUsers != 0 {
+		t.Errorf("Days(_)[0] = %+v, want no events", days[0])
+	}
+	busy := days[1]
+	if busy.Events != 3 || busy.ActiveUsers != 2 {
+		t.Errorf("Days(_)[1] = %+v, want 3 events from 2 users", busy)
+	}
+	if busy.EventTypes["app_open"] != 2 || busy.EventTypes["recipe_viewed"] != 1 ||
+		busy.Appversions["1.2"] != 2 || busy.Appversions["1.3"] != 1 || busy.Operatingsystems["android"] != 3 {
+		t.Errorf("Days(_)[1] = %+v", busy)
+	}
+	if days[2].Events != 1 || days[2].ActiveUsers != 1 {
+		t.Errorf("Days(_)[2] = %+v, want 1 event from 1 user", days[2])
+	}
+
+	// Counters are dropped once the retention period is over
+	store.Now = func() time.Time { return day.Add(45 * 24 * time.Hour) }
+	if days, _ := store.Days(day, day); days[0].Events != 0 {
+		t.Errorf("Days(_) past retention = %+v, want no events", days[0])
+	}
+}
+
+func TestEventBufferWritten(t *testing.T) {
+	buffer := event.NewEventBuffer(&batchSink{failModel: "Broken"}, 10, 1, time.Hour)
+	counted := make(chan int, 2)
+	buffer.Written = func(records []*event.EventRecord) { counted <- len(records) }
+
+	buffer.Write(context.Background(), testRecord("Countertop"))
+	buffer.Write(context.Background(), testRecord("Broken"))
+	buffer.Close()
+	close(counted)
+
+	total := 0
+	for count := range counted {
+		total += count
+	}
+	if total != 1 {
+		t.Errorf("Written saw %d records, want only the 1 stored", total)
+	}
+}
+
+// blockingStatsStore holds up each Add until released, and fails batches of
+// records from failUser.
+type blockingStatsStore struct {
+	started  chan int
+	release  chan bool
+	failUser string
+}
+
+func (b *blockingStatsStore) Add(records ...*event.EventRecord) error {
+	b.started <- len(records)
+	<-b.release
+	if records[0].UserID == b.failUser {
+		return errors.New("stats store unavailable")
+	}
+	return nil
+}
+
+func (b *blockingStatsStore) Days(first time.Time, last time.Time) ([]*event.DayStats, error) {
+	return nil, nil
+}
+
+func TestStatsQueue(t *testing.T) {
+	day := time.Date(2015, 12, 9, 0, 0, 0, 0, time.UTC)
+	store := event.NewMemoryStatsStore(30 * 24 * time.Hour)
+	store.Now = func() time.Time { return day }
+	queue := event.NewStatsQueue(store, 10)
+
+	queue.Add([]*event.EventRecord{statsRecord(testUserID, "app_open", "1.2", day)})
+	queue.Add([]*event.EventRecord{statsRecord(testUserID, "app_open", "1.2", day), statsRecord(testUserID, "app_close", "1.2", day)})
+	queue.Close()
+
+	if days, err := store.Days(day, day); err != nil || days[0].Events != 3 {
+		t.Errorf("Days(_) = %v, %v after Close, want 3 events", days, err)
+	}
+}
+
+func TestStatsQueueDropsWhenFull(t *testing.T) {
+	day := time.Date(2015, 12, 9, 0, 0, 0, 0, time.UTC)
+	store := &blockingStatsStore{started: make(chan int, 3), release: make(chan bool, 3), failUser: "failing"}
+	queue := event.NewStatsQueue(store, 1)
+	failed := 0
+	queue.Failed = func(records []*event.EventRecord, err error) { failed += len(records) }
+
+	if !queue.Add([]*event.EventRecord{statsRecord("failing", "app_open", "1.2", day)}) {
+		t.Fatalf("Add(first) = false, want true")
+	}
+	// The first batch is being added, so the queue has room for one more
+	<-store.started
+	if !queue.Add([]*event.EventRecord{statsRecord(testUserID, "app_open", "1.2", day)}) {
+		t.Errorf("Add(second) = false, want true")
+	}
+	if queue.Add([]*event.EventRecord{statsRecord(testUserID, "app_open", "1.2", day)}) {
+		t.Errorf("Add(third) = true with the queue full, want false")
+	}
+	if dropped := queue.Dropped(); dropped != 1 {
+		t.Errorf("Dropped() = %d, want 1", dropped)
+	}
+
+	store.release <- true
+	store.release <- true
+	queue.Close()
+	if added := len(store.started); added != 1 {
+		t.Errorf("store added %d more batches, want 1", added)
+	}
+	if failed != 1 {
+		t.Errorf("Failed saw %d records, want 1", failed)
+	}
+}
@@ -26,6 +26,14 @@ import (
 )
 
 func Authenticate(log *logger.CtsLogger, identityClient pb.IdentityServiceClient, ctx context.Context, rpcName string, lookupUser bool) (string, *pb.UserId, error) {
+	return AuthenticateWithVerifier(log, nil, identityClient, ctx, rpcName, lookupUser)
+}
+
+// AuthenticateWithVerifier is Authenticate, except that signed tokens are
+// verified locally when the verifier is not nil. Opaque tokens, and tokens
+// signed with a key the verifier has not synced yet, are looked up through
+// identity.
+func AuthenticateWithVerifier(log *logger.CtsLogger, verifier *TokenVerifier, identityClient pb.IdentityServiceClient, ctx context.Context, rpcName string, lookupUser bool) (string, *pb.UserId, error) {
 	md, _ := metadata.FromContext(ctx)
 	token, ok := md["token"]
 	available := false
@@ -70,7 +78,7 @@ func Authenticate(log *logger.CtsLogger, identityClient pb.IdentityServiceClient
 	}
 
 	if lookupUser {
-		userID, err := identityClient.LookupSessionToken(ctx, &pb.SessionToken{Id: tokenString})
+		userID, err := LookupUserID(verifier, identityClient, ctx, tokenString)
 		if err != nil {
 			// TODO(ppietkiewicz): diferentiate between returned error types and retry on timeouts.
 			if available {
@@ -110,3 +118,18 @@ func Authenticate(log *logger.CtsLogger, identityClient pb.IdentityServiceClient
 	}
 	return tokenString, nil, nil
 }
+
+// LookupUserID returns the user a session token belongs to, verifying
+// signed tokens locally when the verifier is not nil.
+func LookupUserID(verifier *TokenVerifier, identityClient pb.IdentityServiceClient, ctx context.Context, token string) (*pb.UserId, error) {
+	if verifier != nil && IsSignedToken(token) {
+		claims, err := verifier.Verify(token)
+		if err == nil {
+			return &pb.UserId{Uuid: claims.UserID}, nil
+		}
+		if err != ErrUnknownTokenKey {
+			return nil, err
+		}
+	}
+	return identityClient.LookupSessionToken(ctx, &pb.SessionToken{Id: token})
+}
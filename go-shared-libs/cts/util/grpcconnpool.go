@@ -80,6 +80,7 @@ func NewConnPool(loggerObj *logger.CtsLogger, serviceName string, host string, p
 	hostConnPool.tls = tls
 	hostConnPool.certFile = certFile
 	hostConnPool.poolSize = maxSize
+	hostConnPool.pool = make(chan *grpc.ClientConn, maxSize)
 
 	pool := make([]*grpc.ClientConn, 0, maxSize)
 
@@ -0,0 +1,293 @@
+/*
+// ----------------------------------------------------------------------------
+// oidc.go
+// Countertop Server OpenID Connect ID Token Utility Library
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package util
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ID tokens are RS256 JSON Web Tokens issued by OpenID Connect providers.
+// They are verified against the JSON Web Key Set each configured issuer
+// publishes, which is fetched on first use and cached.
+
+var (
+	ErrInvalidIDToken  = errors.New("invalid ID token")
+	ErrUnknownIssuer   = errors.New("ID token from an unknown issuer")
+	ErrIDTokenExpired  = errors.New("ID token expired or not valid yet")
+	ErrIDTokenAudience = errors.New("ID token issued to another client")
+)
+
+const (
+	// Allowed difference between our clock and the issuer's
+	idTokenLeeway = time.Minute
+	// Tokens signed with a key missing from the cached set make it be
+	// fetched again, but no more often than this. A failed fetch is not
+	// retried any sooner either.
+	keySetRefetchInterval = time.Minute
+)
+
+// KeySetFetchError is returned when an issuer's key set cannot be fetched
+// and none is cached.
+type KeySetFetchError struct {
+	Issuer string
+	Err    error
+}
+
+func (e *KeySetFetchError) Error() string {
+	return fmt.Sprintf("cannot fetch key set of %s: %v", e.Issuer, e.Err)
+}
+
+// OIDCIssuer is an identity provider users can sign in with.
+type OIDCIssuer struct {
+	// Issuer is matched against the iss claim
+	Issuer string `json:"issuer"`
+	// JWKSURL serves the keys the issuer signs ID tokens with
+	JWKSURL string `json:"jwks_url"`
+	// ClientIDs are the app's client IDs at the issuer. ID tokens must be
+	// issued to one of them.
+	ClientIDs []string `json:"client_ids"`
+}
+
+// LoadOIDCIssuers reads a JSON array of issuers from a file.
+func LoadOIDCIssuers(path string) ([]OIDCIssuer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var issuers []OIDCIssuer
+	if err := json.Unmarshal(data, &issuers); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	for _, issuer := range issuers {
+		if issuer.Issuer == "" || issuer.JWKSURL == "" || len(issuer.ClientIDs) == 0 {
+			return nil, fmt.Errorf("%s: issuers need an issuer, jwks_url and client_ids", path)
+		}
+	}
+	return issuers, nil
+}
+
+type IDTokenClaims struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      Audience `json:"aud"`
+	Expires       int64    `json:"exp"`
+	IssuedAt      int64    `json:"iat"`
+	NotBefore     int64    `json:"nbf,omitempty"`
+	Email         string   `json:"email,omitempty"`
+	EmailVerified bool     `json:"email_verified,omitempty"`
+	Name          string   `json:"name,omitempty"`
+}
+
+// Audience is the aud claim, which is either a single client ID or a list.
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = Audience(list)
+	return nil
+}
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// IDTokenVerifier checks ID tokens from the configured issuers.
+type IDTokenVerifier struct {
+	// Client fetches key sets
+	Client *http.Client
+	// Now returns the current time, and can be replaced in tests.
+	Now func() time.Time
+	// How long a fetched key set is used before it is fetched again
+	CacheTTL time.Duration
+
+	issuers map[string]OIDCIssuer
+	keySets map[string]*issuerKeySet
+}
+
+// issuerKeySet caches the keys of one issuer. Its lock is not held while
+// fetching, so that slow issuers only hold up their own sign ins.
+type issuerKeySet struct {
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+	// When the keys were last fetched, and when that was last attempted
+	fetched   time.Time
+	attempted time.Time
+	err       error
+	// Closed when the fetch in progress, if any, ends
+	fetching chan struct{}
+}
+
+func NewIDTokenVerifier(issuers []OIDCIssuer, cacheTTL time.Duration) *IDTokenVerifier {
+	v := &IDTokenVerifier{
+		Client:   &http.Client{Timeout: 10 * time.Second},
+		Now:      time.Now,
+		CacheTTL: cacheTTL,
+		issuers:  make(map[string]OIDCIssuer, len(issuers)),
+		keySets:  make(map[string]*issuerKeySet, len(issuers)),
+	}
+	for _, issuer := range issuers {
+		v.issuers[issuer.Issuer] = issuer
+		v.keySets[issuer.Issuer] = &issuerKeySet{}
+	}
+	return v
+}
+
+// Verify checks an ID token's signature, issuer, audience and lifetime, and
+// returns its claims.
+func (v *IDTokenVerifier) Verify(token string) (*IDTokenClaims, error) {
+	// The issuer is read before the signature is checked, to know which keys
+	// to check it with. It is only trusted once the signature matches.
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return nil, ErrInvalidIDToken
+	}
+	var unverified IDTokenClaims
+	if err := decodeJSONSegment(segments[1], &unverified); err != nil {
+		return nil, ErrInvalidIDToken
+	}
+	issuer, ok := v.issuers[unverified.Issuer]
+	if !ok {
+		return nil, ErrUnknownIssuer
+	}
+
+	keys, err := v.keySet(issuer, false)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := verifySignature(token, keys)
+	if err == ErrUnknownTokenKey {
+		// The issuer may have rotated its keys since they were fetched
+		if keys, err = v.keySet(issuer, true); err != nil {
+			return nil, err
+		}
+		payload, err = verifySignature(token, keys)
+	}
+	if err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	var claims IDTokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Subject == "" || claims.Issuer != issuer.Issuer {
+		return nil, ErrInvalidIDToken
+	}
+	now := v.Now()
+	if !now.Before(time.Unix(claims.Expires, 0).Add(idTokenLeeway)) ||
+		now.Add(idTokenLeeway).Before(time.Unix(claims.NotBefore, 0)) ||
+		now.Add(idTokenLeeway).Before(time.Unix(claims.IssuedAt, 0)) {
+		return nil, ErrIDTokenExpired
+	}
+	if !claims.Audience.contains(issuer.ClientIDs) {
+		return nil, ErrIDTokenAudience
+	}
+	return &claims, nil
+}
+
+func (a Audience) contains(clientIDs []string) bool {
+	for _, audience := range a {
+		for _, clientID := range clientIDs {
+			if audience == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keySet returns the issuer's cached keys, fetching them when the cache is
+// empty or stale. With refetch, keys older than keySetRefetchInterval are
+// fetched again. If fetching fails, stale keys are used until it succeeds.
+// Concurrent callers wait for a fetch already in progress rather than start
+// another.
+func (v *IDTokenVerifier) keySet(issuer OIDCIssuer, refetch bool) (map[string]*rsa.PublicKey, error) {
+	set := v.keySets[issuer.Issuer]
+	set.mu.Lock()
+	for set.fetching != nil {
+		fetching := set.fetching
+		set.mu.Unlock()
+		<-fetching
+		set.mu.Lock()
+	}
+
+	now := v.Now()
+	age := now.Sub(set.fetched)
+	fresh := set.keys != nil && age < v.CacheTTL && !(refetch && age >= keySetRefetchInterval)
+	if fresh || (!set.attempted.IsZero() && now.Sub(set.attempted) < keySetRefetchInterval) {
+		defer set.mu.Unlock()
+		return set.result(issuer)
+	}
+
+	fetching := make(chan struct{})
+	set.fetching, set.attempted = fetching, now
+	set.mu.Unlock()
+
+	keys, err := v.fetch(issuer)
+
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	if err == nil {
+		set.keys, set.fetched = keys, now
+	}
+	set.err, set.fetching = err, nil
+	close(fetching)
+	return set.result(issuer)
+}
+
+// result returns the cached keys, or the error of the last fetch if there
+// are none.
+func (set *issuerKeySet) result(issuer OIDCIssuer) (map[string]*rsa.PublicKey, error) {
+	if set.keys == nil {
+		return nil, &KeySetFetchError{Issuer: issuer.Issuer, Err: set.err}
+	}
+	return set.keys, nil
+}
+
+// fetch downloads the issuer's key set. Keys that are not RSA signing keys
+// are skipped.
+func (v *IDTokenVerifier) fetch(issuer OIDCIssuer) (map[string]*rsa.PublicKey, error) {
+	response, err := v.Client.Get(issuer.JWKSURL)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", issuer.JWKSURL, response.Status)
+	}
+
+	var published KeySet
+	if err := json.NewDecoder(response.Body).Decode(&published); err != nil {
+		return nil, fmt.Errorf("cannot decode key set: %v", err)
+	}
+	signing := &KeySet{}
+	for _, key := range published.Keys {
+		if key.KeyType == "RSA" && (key.Use == "" || key.Use == "sig") {
+			signing.Keys = append(signing.Keys, key)
+		}
+	}
+	return signing.publicKeys()
+}
@@ -0,0 +1,316 @@
+/*
+// ----------------------------------------------------------------------------
+// oidc_test.go
+// Countertop Server OpenID Connect ID Token Utility Library Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package util_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/theorangechefco/cts/go-shared-libs/cts/util"
+)
+
+const testClientID = "countertop-ios"
+
+// fakeIssuer serves the JSON Web Key Set of an identity provider, and signs
+// ID tokens with its keys.
+type fakeIssuer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	signer  *util.TokenSigner
+	fetches int
+	down    bool
+	// If set, key set requests wait until it is closed
+	stall chan struct{}
+}
+
+func newFakeIssuer(t *testing.T, keyIDs ...string) *fakeIssuer {
+	issuer := &fakeIssuer{signer: &util.TokenSigner{Keys: make(map[string]*rsa.PrivateKey)}}
+	for _, id := range keyIDs {
+		issuer.addKey(t, id)
+	}
+	issuer.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issuer.mu.Lock()
+		issuer.fetches++
+		stall := issuer.stall
+		issuer.mu.Unlock()
+		if stall != nil {
+			<-stall
+		}
+
+		issuer.mu.Lock()
+		defer issuer.mu.Unlock()
+		if issuer.down {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(issuer.signer.KeySet())
+	}))
+	return issuer
+}
+
+func (f *fakeIssuer) addKey(t *testing.T, id string) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey(_) = _, %v", err)
+	}
+	f.mu.Lock()
+	f.signer.Keys[id] = key
+	f.mu.Unlock()
+}
+
+func (f *fakeIssuer) config() util.OIDCIssuer {
+	return util.OIDCIssuer{Issuer: f.URL, JWKSURL: f.URL + "/jwks", ClientIDs: []string{"countertop-android", testClientID}}
+}
+
+// sign returns an ID token with the claims, signed with the key.
+func (f *fakeIssuer) sign(t *testing.T, keyID string, claims interface{}) string {
+	f.mu.Lock()
+	key := f.signer.Keys[keyID]
+	f.mu.Unlock()
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": keyID})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("Marshal(%v) = _, %v", claims, err)
+	}
+	signed := segment(header) + "." + segment(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("SignPKCS1v15(_) = _, %v", err)
+	}
+	return signed + "." + segment(signature)
+}
+
+func (f *fakeIssuer) fetchCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fetches
+}
+
+func segment(data []byte) string {
+	return strings.TrimRight(base64.URLEncoding.EncodeToString(data), "=")
+}
+
+var testNow = time.Date(2015, 12, 16, 12, 0, 0, 0, time.UTC)
+
+func testClaims(issuer string) *util.IDTokenClaims {
+	return &util.IDTokenClaims{
+		Issuer:        issuer,
+		Subject:       "108915432543254",
+		Audience:      util.Audience{testClientID},
+		IssuedAt:      testNow.Add(-time.Minute).Unix(),
+		Expires:       testNow.Add(time.Hour).Unix(),
+		Email:         "john@example.com",
+		EmailVerified: true,
+	}
+}
+
+func newTestIDTokenVerifier(issuers ...*fakeIssuer) *util.IDTokenVerifier {
+	var configs []util.OIDCIssuer
+	for _, issuer := range issuers {
+		configs = append(configs, issuer.config())
+	}
+	verifier := util.NewIDTokenVerifier(configs, time.Hour)
+	verifier.Now = func() time.Time { return testNow }
+	return verifier
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	issuer := newFakeIssuer(t, "key1")
+	defer issuer.Close()
+	verifier := newTestIDTokenVerifier(issuer)
+
+	claims, err := verifier.Verify(issuer.sign(t, "key1", testClaims(issuer.URL)))
+	if err != nil {
+		t.Fatalf("Verify(_) = _, %v", err)
+	}
+	if claims.Subject != "108915432543254" || claims.Email != "john@example.com" || !claims.EmailVerified {
+		t.Errorf("Verify(_) = %v, want the signed claims", claims)
+	}
+
+	// Audiences can be lists
+	listed := testClaims(issuer.URL)
+	listed.Audience = util.Audience{"someone-else", testClientID}
+	if _, err := verifier.Verify(issuer.sign(t, "key1", listed)); err != nil {
+		t.Errorf("Verify(audience list) = _, %v", err)
+	}
+
+	otherIssuer := newFakeIssuer(t, "key1")
+	defer otherIssuer.Close()
+	wrongAudience := testClaims(issuer.URL)
+	wrongAudience.Audience = util.Audience{"someone-else"}
+	expired := testClaims(issuer.URL)
+	expired.Expires = testNow.Add(-2 * time.Minute).Unix()
+	early := testClaims(issuer.URL)
+	early.NotBefore = testNow.Add(10 * time.Minute).Unix()
+	valid := issuer.sign(t, "key1", testClaims(issuer.URL))
+	tampered := valid[:strings.LastIndex(valid, ".")] + "." + segment([]byte("not a signature"))
+	noSubject := testClaims(issuer.URL)
+	noSubject.Subject = ""
+
+	for _, test := range []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"wrong audience", issuer.sign(t, "key1", wrongAudience), util.ErrIDTokenAudience},
+		{"expired", issuer.sign(t, "key1", expired), util.ErrIDTokenExpired},
+		{"not valid yet", issuer.sign(t, "key1", early), util.ErrIDTokenExpired},
+		{"unknown issuer", otherIssuer.sign(t, "key1", testClaims(otherIssuer.URL)), util.ErrUnknownIssuer},
+		// Signed by another issuer, claiming to be the configured one
+		{"forged", otherIssuer.sign(t, "key1", testClaims(issuer.URL)), util.ErrInvalidIDToken},
+		{"tampered", tampered, util.ErrInvalidIDToken},
+		{"no subject", issuer.sign(t, "key1", noSubject), util.ErrInvalidIDToken},
+		{"garbage", "not.a.token", util.ErrInvalidIDToken},
+	} {
+		if _, err := verifier.Verify(test.token); err != test.want {
+			t.Errorf("Verify(%s) = _, %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+func TestIDTokenKeySetCache(t *testing.T) {
+	issuer := newFakeIssuer(t, "key1")
+	defer issuer.Close()
+	verifier := newTestIDTokenVerifier(issuer)
+	now := testNow
+	verifier.Now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := verifier.Verify(issuer.sign(t, "key1", testClaims(issuer.URL))); err != nil {
+			t.Fatalf("Verify(_) = _, %v", err)
+		}
+	}
+	if fetches := issuer.fetchCount(); fetches != 1 {
+		t.Errorf("key set fetched %d times, want 1", fetches)
+	}
+
+	// A new key is only picked up once the cached set is old enough to be
+	// fetched again
+	issuer.addKey(t, "key2")
+	rotated := issuer.sign(t, "key2", testClaims(issuer.URL))
+	if _, err := verifier.Verify(rotated); err != util.ErrInvalidIDToken {
+		t.Errorf("Verify(new key) = _, %v right after fetching, want %v", err, util.ErrInvalidIDToken)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, err := verifier.Verify(rotated); err != nil {
+		t.Errorf("Verify(new key) = _, %v", err)
+	}
+	if fetches := issuer.fetchCount(); fetches != 2 {
+		t.Errorf("key set fetched %d times, want 2", fetches)
+	}
+
+	// Stale keys keep working while the issuer is down
+	issuer.mu.Lock()
+	issuer.down = true
+	issuer.mu.Unlock()
+	now = now.Add(2 * time.Hour)
+	claims := testClaims(issuer.URL)
+	claims.IssuedAt, claims.Expires = now.Unix(), now.Add(time.Hour).Unix()
+	if _, err := verifier.Verify(issuer.sign(t, "key1", claims)); err != nil {
+		t.Errorf("Verify(_) = _, %v while the issuer is down", err)
+	}
+	if fetches := issuer.fetchCount(); fetches != 3 {
+		t.Errorf("key set fetched %d times, want 3", fetches)
+	}
+
+	// With nothing cached, the failure is reported, and the key set is not
+	// fetched again until the refetch interval has passed
+	fresh := newTestIDTokenVerifier(issuer)
+	fresh.Now = func() time.Time { return now }
+	for i := 0; i < 2; i++ {
+		if _, err := fresh.Verify(issuer.sign(t, "key1", claims)); err == nil {
+			t.Errorf("Verify(_) succeeded without a key set")
+		} else if _, ok := err.(*util.KeySetFetchError); !ok {
+			t.Errorf("Verify(_) = _, %v, want a KeySetFetchError", err)
+		}
+	}
+	if fetches := issuer.fetchCount(); fetches != 4 {
+		t.Errorf("key set fetched %d times, want 4", fetches)
+	}
+	issuer.mu.Lock()
+	issuer.down = false
+	issuer.mu.Unlock()
+	now = now.Add(2 * time.Minute)
+	claims.IssuedAt = now.Unix()
+	if _, err := fresh.Verify(issuer.sign(t, "key1", claims)); err != nil {
+		t.Errorf("Verify(_) = _, %v once the issuer is back", err)
+	}
+	if fetches := issuer.fetchCount(); fetches != 5 {
+		t.Errorf("key set fetched %d times, want 5", fetches)
+	}
+}
+
+func TestIDTokenKeySetFetchesDoNotBlockOtherIssuers(t *testing.T) {
+	slow := newFakeIssuer(t, "key1")
+	defer slow.Close()
+	fast := newFakeIssuer(t, "key1")
+	defer fast.Close()
+	verifier := newTestIDTokenVerifier(slow, fast)
+
+	stall := make(chan struct{})
+	slow.mu.Lock()
+	slow.stall = stall
+	slow.mu.Unlock()
+
+	slowToken := slow.sign(t, "key1", testClaims(slow.URL))
+	results := make(chan error, 2)
+	verify := func() {
+		_, err := verifier.Verify(slowToken)
+		results <- err
+	}
+	go verify()
+	for slow.fetchCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	go verify()
+
+	fastResult := make(chan error, 1)
+	fastToken := fast.sign(t, "key1", testClaims(fast.URL))
+	go func() {
+		_, err := verifier.Verify(fastToken)
+		fastResult <- err
+	}()
+	select {
+	case err := <-fastResult:
+		if err != nil {
+			t.Errorf("Verify(_) = _, %v while another issuer is slow", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Verify(_) waited for another issuer's key set")
+	}
+
+	close(stall)
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-results:
+			if err != nil {
+				t.Errorf("Verify(_) = _, %v from the slow issuer", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Verify(_) did not return after the slow issuer answered")
+		}
+	}
+	if fetches := slow.fetchCount(); fetches != 1 {
+		t.Errorf("slow key set fetched %d times, want 1", fetches)
+	}
+}
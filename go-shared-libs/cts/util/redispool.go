@@ -37,6 +37,12 @@ type RedisHandler struct {
 }
 
 func NewPool(loggerObject *logger.CtsLogger, host string, pass string, size int, retryTimes int, retrySleep int) (*RedisHandler, error) {
+	return NewPoolTimeout(loggerObject, host, pass, size, retryTimes, retrySleep, 0)
+}
+
+// NewPoolTimeout creates a pool whose connections give up on connecting,
+// reading or writing after timeout. A timeout of 0 waits indefinitely.
+func NewPoolTimeout(loggerObject *logger.CtsLogger, host string, pass string, size int, retryTimes int, retrySleep int, timeout time.Duration) (*RedisHandler, error) {
 	redisHandler := new(RedisHandler)
 	redisHandler.Logger = loggerObject
 	redisHandler.retryTimes = retryTimes
@@ -46,7 +52,7 @@ func NewPool(loggerObject *logger.CtsLogger, host string, pass string, size int,
 	var err error
 
 	df := func(network, addr string) (*redis.Client, error) {
-		client, err := redis.Dial(network, addr)
+		client, err := redis.DialTimeout(network, addr, timeout)
 		if err != nil {
 			redisHandler.Logger.Error(logrus.Fields{
 				"phase": "connection",
@@ -348,6 +354,14 @@ func (c *RedisHandler) Get(keys ...string) (*redis.Reply, error) {
 	return c.runCommand("MGET", interfaceList...)
 }
 
+func (c *RedisHandler) HGet(key string, field string) (*redis.Reply, error) {
+	return c.runCommand("HGET", key, field)
+}
+
+func (c *RedisHandler) HGetAll(key string) (*redis.Reply, error) {
+	return c.runCommand("HGETALL", key)
+}
+
 func (c *RedisHandler) SetMany(ttl int, tuples ...[]string) ([]*redis.Reply, error) {
 	var commandList []string
 	for _, tuple := range tuples {
@@ -360,3 +374,12 @@ func (c *RedisHandler) SetMany(ttl int, tuples ...[]string) ([]*redis.Reply, err
 func (c *RedisHandler) Set(ttl int, key string, value interface{}) (*redis.Reply, error) {
 	return c.runCommand("SET", key, value, "EX", ttl)
 }
+
+// Eval runs a Lua script, which Redis executes atomically.
+func (c *RedisHandler) Eval(script string, keys []string, args ...interface{}) (*redis.Reply, error) {
+	interfaceList := []interface{}{script, len(keys)}
+	for _, key := range keys {
+		interfaceList = append(interfaceList, key)
+	}
+	return c.runCommand("EVAL", append(interfaceList, args...)...)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	//"github.com/fzzy/radix/extra/sentinel"
 	"github.com/Sirupsen/logrus"
@@ -38,17 +39,28 @@ type User struct {
 	Lowsodium     bool `sql:"default: 0"`
 }
 
+// SetupRedisAccount seeds a session for the test user in the layout the
+// identity service's Redis session store uses: a family_<id> hash, access_
+// and refresh_ keys holding the family ID, and a user_<uuid> hash of device
+// IDs to families.
 func SetupRedisAccount(redisHost string, redisPass string) (*pb.SessionToken, error) {
 	token := pb.SessionToken{
-		Id: "goXycIUTGQyKJXSxbOJY",
+		Id:           "goXycIUTGQyKJXSxbOJY",
+		Refreshtoken: "SxbOJYgoXycIUTGQyKJX",
 	}
 
 	userID := pb.UserId{
 		Uuid: "d17eaf65-244a-4913-83d8-1583bb3cbbfd",
 	}
 
+	family := "bOJYgoXycIUT"
+	deviceID := "ecb7381b-566c-4e53-b3ea-add8cd372d6a"
+	ttl := 86400
+
 	userKey := strings.Join([]string{"user", userID.Uuid}, "_")
-	sessionTokenKey := strings.Join([]string{"token", token.Id}, "_")
+	familyKey := strings.Join([]string{"family", family}, "_")
+	accessKey := strings.Join([]string{"access", token.Id}, "_")
+	refreshKey := strings.Join([]string{"refresh", token.Refreshtoken}, "_")
 	logger := loglib.NewLogger("setup", "", 8080, true, logrus.DebugLevel)
 
 	redisClient, err := NewClient(logger, redisHost, redisPass)
@@ -57,23 +69,36 @@ func SetupRedisAccount(redisHost string, redisPass string) (*pb.SessionToken, er
 		os.Exit(-1)
 	}
 
-	exists, err := CheckForRedisKey(redisClient, token.Id)
+	exists, err := CheckForRedisKey(redisClient, accessKey)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(-1)
 	}
 
 	if !exists {
-		err = CreateRedisEntry(redisClient, userKey, token.Id)
+		now := time.Now().Unix()
+		err = redisClient.Cmd("HMSET", familyKey, "user", userID.Uuid, "refresh", token.Refreshtoken,
+			"device", deviceID, "appversion", "", "created", now, "lastseen", now, "accessexpires", now+int64(ttl)).Err
+		if err == nil {
+			err = UpdateTTL(redisClient, familyKey, ttl)
+		}
+		if err == nil {
+			err = redisClient.Cmd("HSET", userKey, deviceID, family).Err
+		}
+		if err == nil {
+			err = UpdateTTL(redisClient, userKey, ttl)
+		}
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(-1)
 		}
 
-		err = CreateRedisEntry(redisClient, sessionTokenKey, userID.Uuid)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(-1)
+		for _, key := range []string{accessKey, refreshKey} {
+			err = CreateRedisEntry(redisClient, key, family)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(-1)
+			}
 		}
 	}
 
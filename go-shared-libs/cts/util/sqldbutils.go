@@ -31,9 +31,11 @@ func ClearUserDB(hostAddress string, port int32, username string, password strin
 
 	connStr := fmt.Sprintf(hostTemplate, username, password, hostAddress, port, dbName)
 	db, err := sql.Open("mysql", connStr)
-	_, err = db.Exec("DELETE FROM user")
-	if err != nil {
-		fmt.Printf("Could not delete table. Error: %v", err)
-		os.Exit(-1)
+	for _, table := range []string{"federated_identity", "credential", "user"} {
+		_, err = db.Exec("DELETE FROM " + table)
+		if err != nil {
+			fmt.Printf("Could not delete table. Error: %v", err)
+			os.Exit(-1)
+		}
 	}
 }
@@ -0,0 +1,372 @@
+/*
+// ----------------------------------------------------------------------------
+// tokens.go
+// Countertop Server Signed Session Token Utility Library
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package util
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	pb "github.com/theorangechefco/cts/go-protos"
+	"github.com/theorangechefco/cts/go-shared-libs/cts/logger"
+	"golang.org/x/net/context"
+)
+
+// Signed session tokens are RS256 JSON Web Tokens. The kid header names the
+// key they were signed with, so that keys can be rotated: identity publishes
+// every key it holds as a JSON Web Key Set, and signs with one of them.
+
+var (
+	ErrMalformedToken    = errors.New("malformed session token")
+	ErrUnknownTokenKey   = errors.New("session token signed with an unknown key")
+	ErrBadTokenSignature = errors.New("session token signature does not match")
+	ErrTokenExpired      = errors.New("session token expired")
+	ErrTokenRevoked      = errors.New("session token revoked")
+)
+
+const tokenAlgorithm = "RS256"
+
+type TokenClaims struct {
+	UserID    string `json:"sub"`
+	SessionID string `json:"sid"`
+	IssuedAt  int64  `json:"iat"`
+	Expires   int64  `json:"exp"`
+}
+
+type tokenHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid"`
+}
+
+// IsSignedToken tells signed tokens apart from opaque ones, which are
+// base64 and never contain a dot.
+func IsSignedToken(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+//
+// Signing
+//
+
+// TokenSigner holds identity's private keys by key ID. Tokens are signed
+// with the Active key; the others are still published so that tokens they
+// signed keep verifying until they expire.
+type TokenSigner struct {
+	Keys   map[string]*rsa.PrivateKey
+	Active string
+}
+
+// LoadTokenSigner reads PEM encoded RSA private keys from <key ID>.pem files
+// in the directory.
+func LoadTokenSigner(dir string, active string) (*TokenSigner, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	signer := &TokenSigner{Keys: make(map[string]*rsa.PrivateKey), Active: active}
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("%s: no PEM data", path)
+		}
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		signer.Keys[strings.TrimSuffix(filepath.Base(path), ".pem")] = key
+	}
+	if _, ok := signer.Keys[active]; !ok {
+		return nil, fmt.Errorf("no key %s.pem in %s", active, dir)
+	}
+	return signer, nil
+}
+
+func (s *TokenSigner) Sign(claims *TokenClaims) (string, error) {
+	key, ok := s.Keys[s.Active]
+	if !ok {
+		return "", fmt.Errorf("no signing key %s", s.Active)
+	}
+	header, err := json.Marshal(tokenHeader{Algorithm: tokenAlgorithm, Type: "JWT", KeyID: s.Active})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := encodeSegment(header) + "." + encodeSegment(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + encodeSegment(signature), nil
+}
+
+// Verify checks a token signed with any of the signer's keys. Revocation is
+// left to the caller.
+func (s *TokenSigner) Verify(token string) (*TokenClaims, error) {
+	keys := make(map[string]*rsa.PublicKey, len(s.Keys))
+	for id, key := range s.Keys {
+		keys[id] = &key.PublicKey
+	}
+	return verifyToken(token, keys, time.Now())
+}
+
+// KeySet returns the public half of every key, ordered by key ID.
+func (s *TokenSigner) KeySet() *KeySet {
+	set := &KeySet{Keys: []JSONWebKey{}}
+	for id, key := range s.Keys {
+		set.Keys = append(set.Keys, JSONWebKey{
+			KeyType:   "RSA",
+			KeyID:     id,
+			Use:       "sig",
+			Algorithm: tokenAlgorithm,
+			Modulus:   encodeSegment(key.N.Bytes()),
+			Exponent:  encodeSegment(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	sort.Sort(byKeyID(set.Keys))
+	return set
+}
+
+//
+// Key sets
+//
+
+// KeySet is a JSON Web Key Set of RSA public keys.
+type KeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+type byKeyID []JSONWebKey
+
+func (k byKeyID) Len() int           { return len(k) }
+func (k byKeyID) Swap(i, j int)      { k[i], k[j] = k[j], k[i] }
+func (k byKeyID) Less(i, j int) bool { return k[i].KeyID < k[j].KeyID }
+
+func (s *KeySet) publicKeys() (map[string]*rsa.PublicKey, error) {
+	keys := make(map[string]*rsa.PublicKey, len(s.Keys))
+	for _, key := range s.Keys {
+		if key.KeyType != "RSA" {
+			return nil, fmt.Errorf("key %s has unsupported type %s", key.KeyID, key.KeyType)
+		}
+		modulus, err := decodeSegment(key.Modulus)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %v", key.KeyID, err)
+		}
+		exponent, err := decodeSegment(key.Exponent)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %v", key.KeyID, err)
+		}
+		keys[key.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(modulus),
+			E: int(new(big.Int).SetBytes(exponent).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+//
+// Verification
+//
+
+// TokenVerifier checks signed session tokens without a round trip to
+// identity. It holds the key set identity publishes, and the sessions that
+// were closed while tokens issued for them may still be unexpired. Both are
+// refreshed by Sync.
+type TokenVerifier struct {
+	// Now returns the current time, and can be replaced in tests.
+	Now func() time.Time
+
+	mu      sync.RWMutex
+	keys    map[string]*rsa.PublicKey
+	revoked map[string]time.Time
+}
+
+func NewTokenVerifier() *TokenVerifier {
+	return &TokenVerifier{
+		Now:     time.Now,
+		keys:    make(map[string]*rsa.PublicKey),
+		revoked: make(map[string]time.Time),
+	}
+}
+
+func (v *TokenVerifier) SetKeySet(set *KeySet) error {
+	keys, err := set.publicKeys()
+	if err != nil {
+		return err
+	}
+	v.mu.Lock()
+	v.keys = keys
+	v.mu.Unlock()
+	return nil
+}
+
+// SetRevoked replaces the revocation list with session IDs and the time
+// the last token issued for each expires.
+func (v *TokenVerifier) SetRevoked(revoked map[string]time.Time) {
+	v.mu.Lock()
+	v.revoked = revoked
+	v.mu.Unlock()
+}
+
+func (v *TokenVerifier) Verify(token string) (*TokenClaims, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	now := v.Now()
+	claims, err := verifyToken(token, v.keys, now)
+	if err != nil {
+		return nil, err
+	}
+	if expires, ok := v.revoked[claims.SessionID]; ok && now.Before(expires) {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+// Sync fetches the key set and the revocation list from identity.
+func (v *TokenVerifier) Sync(identityClient pb.IdentityServiceClient) error {
+	published, err := identityClient.GetKeySet(context.Background(), &pb.EmptyRequest{})
+	if err != nil {
+		return fmt.Errorf("cannot fetch key set: %v", err)
+	}
+	var set KeySet
+	if err := json.Unmarshal([]byte(published.Jwks), &set); err != nil {
+		return fmt.Errorf("cannot decode key set: %v", err)
+	}
+	if err := v.SetKeySet(&set); err != nil {
+		return err
+	}
+
+	sessions, err := identityClient.GetRevokedSessions(context.Background(), &pb.EmptyRequest{})
+	if err != nil {
+		return fmt.Errorf("cannot fetch revoked sessions: %v", err)
+	}
+	revoked := make(map[string]time.Time, len(sessions.Sessions))
+	for _, session := range sessions.Sessions {
+		revoked[session.Id] = time.Unix(session.Expires.Seconds, 0)
+	}
+	v.SetRevoked(revoked)
+	return nil
+}
+
+// Poll runs sync every interval, logging failures. Until the first sync
+// succeeds, tokens cannot be verified locally.
+func (v *TokenVerifier) Poll(log *logger.CtsLogger, interval time.Duration, sync func() error) {
+	for {
+		if err := sync(); err != nil {
+			log.Error(logrus.Fields{
+				"phase": "process",
+				"event": "sync",
+				"tag":   "tokenverifier"},
+				fmt.Sprintf("Cannot sync session token keys and revocations: %v", err))
+		}
+		time.Sleep(interval)
+	}
+}
+
+func verifyToken(token string, keys map[string]*rsa.PublicKey, now time.Time) (*TokenClaims, error) {
+	payload, err := verifySignature(token, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims TokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.UserID == "" || claims.SessionID == "" {
+		return nil, ErrMalformedToken
+	}
+	if !now.Before(time.Unix(claims.Expires, 0)) {
+		return nil, ErrTokenExpired
+	}
+	return &claims, nil
+}
+
+// verifySignature checks an RS256 JSON Web Token against the key its kid
+// header names, and returns its decoded payload.
+func verifySignature(token string, keys map[string]*rsa.PublicKey) ([]byte, error) {
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var header tokenHeader
+	if err := decodeJSONSegment(segments[0], &header); err != nil || header.Algorithm != tokenAlgorithm {
+		return nil, ErrMalformedToken
+	}
+	key, ok := keys[header.KeyID]
+	if !ok {
+		return nil, ErrUnknownTokenKey
+	}
+	signature, err := decodeSegment(segments[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	digest := sha256.Sum256([]byte(segments[0] + "." + segments[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, ErrBadTokenSignature
+	}
+
+	payload, err := decodeSegment(segments[1])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	return payload, nil
+}
+
+// Token segments are unpadded base64url.
+func encodeSegment(data []byte) string {
+	return strings.TrimRight(base64.URLEncoding.EncodeToString(data), "=")
+}
+
+func decodeSegment(segment string) ([]byte, error) {
+	if padding := len(segment) % 4; padding > 0 {
+		segment += strings.Repeat("=", 4-padding)
+	}
+	return base64.URLEncoding.DecodeString(segment)
+}
+
+func decodeJSONSegment(segment string, v interface{}) error {
+	data, err := decodeSegment(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
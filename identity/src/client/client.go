@@ -37,9 +37,9 @@ func lookupToken(client pb.IdentityServiceClient, token *pb.SessionToken) (*pb.U
 	return userid, nil
 }
 
-func createToken(client pb.IdentityServiceClient, userId *pb.UserId) (*pb.SessionToken, error) {
-	grpclog.Printf("Getting session token for user with id (%v)", userId)
-	token, err := client.GenerateSessionToken(context.Background(), userId)
+func createToken(client pb.IdentityServiceClient, request *pb.SessionRequest) (*pb.SessionToken, error) {
+	grpclog.Printf("Getting session token for user with id (%v)", request)
+	token, err := client.GenerateSessionToken(context.Background(), request)
 	if err != nil {
 		grpclog.Printf("%v.RecipeServiceClient(_) = _, %v: ", client, err)
 		return nil, err
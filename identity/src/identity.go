@@ -11,6 +11,9 @@
 package identity
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -18,140 +21,227 @@ import (
 	"github.com/theorangechefco/cts/go-shared-libs/cts/logger"
 	"github.com/theorangechefco/cts/go-shared-libs/cts/util"
 
-	"github.com/fzzy/radix/redis"
-	misc "github.com/theorangechefco/cts/identity/misc"
-
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 
-	"strings"
-
 	pb "github.com/theorangechefco/cts/go-protos"
 )
 
 type Server struct {
-	Logger *logger.CtsLogger
-	Pool   *util.RedisHandler
-	TTL    int
+	Logger   *logger.CtsLogger
+	Sessions SessionStore
+	// Access tokens are short lived. Refresh tokens are exchanged for a new
+	// pair through RefreshSession, and expire if not used for RefreshTTL.
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	// Signer, if set, makes access tokens signed tokens carrying the user
+	// UUID, session ID and expiry, which other services verify without
+	// calling identity. Otherwise access tokens are opaque.
+	Signer *util.TokenSigner
 }
 
-func (s *Server) GenerateSessionToken(ctx context.Context, userID *pb.UserId) (*pb.SessionToken, error) {
-	var replies []*redis.Reply
-	var reply *redis.Reply
-	var err error
+func (s *Server) sessionToken(session *Session) (*pb.SessionToken, error) {
+	token := &pb.SessionToken{
+		Id:           session.AccessToken,
+		Ttl:          &pb.Timestamp{Seconds: session.AccessExpires.Unix()},
+		Refreshtoken: session.RefreshToken,
+		Refreshttl:   &pb.Timestamp{Seconds: session.RefreshExpires.Unix()},
+	}
+	if s.Signer != nil {
+		signed, err := s.Signer.Sign(&util.TokenClaims{
+			UserID:    session.UserID,
+			SessionID: session.Family,
+			IssuedAt:  time.Now().Unix(),
+			Expires:   session.AccessExpires.Unix(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		token.Id = signed
+	}
+	return token, nil
+}
 
-	userKey := strings.Join([]string{"user", userID.Uuid}, "_")
-	reply, err = s.Pool.Get(userKey)
-	token, tokenErr := reply.Str()
-	if tokenErr != nil || err != nil {
-		s.Logger.Info(logrus.Fields{
+func sessionInfo(session *Session, current string) *pb.SessionInfo {
+	return &pb.SessionInfo{
+		Id:         session.Family,
+		Deviceid:   session.Device.ID,
+		Appversion: session.Device.Appversion,
+		Created:    &pb.Timestamp{Seconds: session.Created.Unix()},
+		Lastseen:   &pb.Timestamp{Seconds: session.LastSeen.Unix()},
+		Current:    session.Family == current,
+	}
+}
+
+// tokenDigest identifies a bearer token in logs and errors without revealing
+// it.
+func tokenDigest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:6])
+}
+
+// GenerateSessionToken starts a session for the user on the device in the
+// request. A device has one session at a time, so signing in again on the
+// same device replaces its session without affecting other devices.
+func (s *Server) GenerateSessionToken(ctx context.Context, request *pb.SessionRequest) (*pb.SessionToken, error) {
+	if request.Uuid == "" {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "GenerateSessionToken"},
+			"User ID not specified.")
+		return nil, grpc.Errorf(codes.InvalidArgument, "User ID not specified.")
+	}
+
+	device := Device{ID: request.Deviceid, Appversion: request.Appversion}
+	session, err := s.Sessions.Create(request.Uuid, device, s.AccessTTL, s.RefreshTTL)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
 			"phase": "process",
 			"event": "update",
-			"tag":   "redis",
+			"tag":   "sessionstore",
 			"rpc":   "GenerateSessionToken"},
-			fmt.Sprintf("User key %s does not exist in redis.", userKey))
+			fmt.Sprintf("Could not create session for user %s. Error: %v", request.Uuid, err))
+		return nil, grpc.Errorf(codes.Internal, "Session store problem, could not create session for user %s.", request.Uuid)
 	}
 
-	if token != "" {
-		sessionTTL := time.Now().Add(time.Duration(s.TTL) * time.Second).Unix()
-		replies, err = s.Pool.Expire(s.TTL, userKey, token)
-		if err != nil {
-			s.Logger.Error(logrus.Fields{
-				"phase": "process",
-				"event": "update",
-				"tag":   "redis",
-				"rpc":   "GenerateSessionToken"},
-				fmt.Sprintf("Cannot update TTL for keys %s, %s. Error: %v", userKey, token, err))
-			return nil, grpc.Errorf(codes.Internal, "Redis connection problem, cannot update TTL user %s.", userID.Uuid)
-		}
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "respond",
+		"tag":   "identity",
+		"rpc":   "GenerateSessionToken"},
+		fmt.Sprintf("Token with digest %s successfully generated for user %s on device %q in token family %s", tokenDigest(session.AccessToken), request.Uuid, request.Deviceid, session.Family))
 
-		return &pb.SessionToken{Id: token, Ttl: &pb.Timestamp{Seconds: sessionTTL}}, nil
-	}
-
-	// Token does not exist, lets generate a new one
-	sessionTokenValue, _ := misc.GenerateSessionKey()
-	sessionTokenKey := strings.Join([]string{"token", sessionTokenValue}, "_")
-
-	// Ensure token is unique
-	for {
-		existsReply, existsErr := s.Pool.Exists(sessionTokenKey)
-		exists, existsReplyErr := existsReply.Bool()
-		if existsReplyErr != nil || existsErr != nil {
-			s.Logger.Error(logrus.Fields{
-				"phase": "process",
-				"event": "fetch",
-				"tag":   "redis",
-				"rpc":   "GenerateSessionToken"},
-				fmt.Sprintf("Cannot check if key session token %s exists. Error: %v, %v", sessionTokenKey, existsErr, existsReplyErr))
-			return nil, grpc.Errorf(codes.Internal, "Redis connection problem, cannot check session token candidate for user %s.", userID.Uuid)
-		}
-		if exists {
-			sessionTokenValue, _ = misc.GenerateSessionKey()
-			sessionTokenKey = strings.Join([]string{"token_", sessionTokenValue}, "_")
-		} else {
-			break
-		}
+	token, err := s.sessionToken(session)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "sign",
+			"tag":   "tokensigner",
+			"rpc":   "GenerateSessionToken"},
+			fmt.Sprintf("Could not sign token for user %s. Error: %v", request.Uuid, err))
+		return nil, grpc.Errorf(codes.Internal, "Could not sign session token for user %s.", request.Uuid)
 	}
+	return token, nil
+}
 
-	sessionTTL := time.Now().Add(time.Duration(s.TTL) * time.Second).Unix()
-	replies, setErr := s.Pool.SetMany(s.TTL, []string{userKey, sessionTokenValue}, []string{sessionTokenKey, userID.Uuid})
-	if setErr != nil {
+// RefreshSession exchanges a refresh token for a new access and refresh
+// token pair. A refresh token can only be exchanged once; presenting it
+// again revokes every token issued from the same login.
+func (s *Server) RefreshSession(ctx context.Context, request *pb.RefreshRequest) (*pb.SessionToken, error) {
+	if request.Refreshtoken == "" {
 		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "RefreshSession"},
+			"Refresh token not specified.")
+		return nil, grpc.Errorf(codes.InvalidArgument, "Refresh token not specified.")
+	}
+
+	session, err := s.Sessions.Refresh(request.Refreshtoken, s.AccessTTL, s.RefreshTTL)
+	if err == ErrRefreshTokenReused {
+		s.Logger.Warn(logrus.Fields{
 			"phase": "process",
 			"event": "update",
-			"tag":   "redis",
-			"rpc":   "GenerateSessionToken"},
-			fmt.Sprintf("Redis problem, could not set value for user %s. Err: %v", sessionTokenKey, setErr))
-		return nil, grpc.Errorf(codes.Internal, "Redis problem, could not set value for user %s. Err: %v", userID.Uuid, setErr)
-	}
-
-	for _, reply = range replies {
-		if reply.Err != nil {
-			s.Logger.Error(logrus.Fields{
-				"phase": "process",
-				"event": "update",
-				"tag":   "redis",
-				"rpc":   "GenerateSessionToken"},
-				fmt.Sprintf("Redis problem, could not set value for user %s. Err: %v", userID.Uuid, reply.Err))
-		}
+			"tag":   "refreshtokenreuse",
+			"rpc":   "RefreshSession"},
+			fmt.Sprintf("Refresh token with digest %s was already used, revoked its token family", tokenDigest(request.Refreshtoken)))
+		return nil, grpc.Errorf(codes.Unauthenticated, "Refresh token already used, session revoked.")
+	}
+	if err == ErrSessionNotFound {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "update",
+			"tag":   "sessionstore",
+			"rpc":   "RefreshSession"},
+			fmt.Sprintf("Refresh token with digest %s does not exist", tokenDigest(request.Refreshtoken)))
+		return nil, grpc.Errorf(codes.Unauthenticated, "Refresh token does not exist or has expired.")
+	}
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "update",
+			"tag":   "sessionstore",
+			"rpc":   "RefreshSession"},
+			fmt.Sprintf("Could not refresh session for refresh token with digest %s. Error: %v", tokenDigest(request.Refreshtoken), err))
+		return nil, grpc.Errorf(codes.Internal, "Session store problem, could not refresh session.")
 	}
 
 	s.Logger.Info(logrus.Fields{
 		"phase": "process",
 		"event": "respond",
 		"tag":   "identity",
-		"rpc":   "GenerateSessionToken"},
-		fmt.Sprintf("Token %s successfully generated for user %s", token, userID.Uuid))
+		"rpc":   "RefreshSession"},
+		fmt.Sprintf("Refreshed token family %s for user %s", session.Family, session.UserID))
 
-	return &pb.SessionToken{Id: sessionTokenValue, Ttl: &pb.Timestamp{Seconds: sessionTTL}}, nil
+	token, err := s.sessionToken(session)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "sign",
+			"tag":   "tokensigner",
+			"rpc":   "RefreshSession"},
+			fmt.Sprintf("Could not sign token for user %s. Error: %v", session.UserID, err))
+		return nil, grpc.Errorf(codes.Internal, "Could not sign session token.")
+	}
+	return token, nil
 }
 
-func (s *Server) LookupSessionToken(ctx context.Context, token *pb.SessionToken) (*pb.UserId, error) {
-	var reply *redis.Reply
+// lookup returns the live session for an access token, opaque or signed.
+func (s *Server) lookup(token string, rpc string) (*Session, error) {
+	var session *Session
 	var err error
-	sessionTokenKey := strings.Join([]string{"token", token.Id}, "_")
-
-	reply, err = s.Pool.Get(sessionTokenKey)
-	uuid, uuidErr := reply.Str()
-	if uuidErr != nil || err != nil {
+	if s.Signer != nil && util.IsSignedToken(token) {
+		session, err = s.lookupSigned(token)
+	} else {
+		session, err = s.Sessions.Lookup(token)
+	}
+	if err == ErrSessionNotFound {
 		s.Logger.Error(logrus.Fields{
 			"phase": "process",
 			"event": "fetch",
-			"tag":   "redis",
-			"rpc":   "LookupSessionToken"},
-			fmt.Sprintf("Cannot fetch user ID for session token %s. Error: %v, %v", sessionTokenKey, uuidErr, err))
-		return nil, grpc.Errorf(codes.Internal, "Cannot fetch user ID for session token %s", sessionTokenKey)
+			"tag":   "sessionstore",
+			"rpc":   rpc},
+			fmt.Sprintf("Session token with digest %s does not exist", tokenDigest(token)))
+		return nil, grpc.Errorf(codes.NotFound, "Session token with digest %s does not exist", tokenDigest(token))
 	}
-
-	if uuid == "" {
+	if err != nil {
 		s.Logger.Error(logrus.Fields{
 			"phase": "process",
 			"event": "fetch",
-			"tag":   "redis",
-			"rpc":   "LookupSessionToken"},
-			fmt.Sprintf("Session token %s does not exist", token.Id))
-		return nil, grpc.Errorf(codes.Internal, "Session token %s does not exist", token.Id)
+			"tag":   "sessionstore",
+			"rpc":   rpc},
+			fmt.Sprintf("Cannot fetch user ID for session token with digest %s. Error: %v", tokenDigest(token), err))
+		return nil, grpc.Errorf(codes.Internal, "Cannot fetch user ID for session token with digest %s", tokenDigest(token))
+	}
+	return session, nil
+}
+
+// lookupSigned checks the token's signature and expiry, and that its
+// session has not been closed.
+func (s *Server) lookupSigned(token string) (*Session, error) {
+	claims, err := s.Signer.Verify(token)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+	session, err := s.Sessions.Family(claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != claims.UserID {
+		return nil, ErrSessionNotFound
+	}
+	session.AccessToken, session.AccessExpires = token, time.Unix(claims.Expires, 0)
+	return session, nil
+}
+
+func (s *Server) LookupSessionToken(ctx context.Context, token *pb.SessionToken) (*pb.UserId, error) {
+	session, err := s.lookup(token.Id, "LookupSessionToken")
+	if err != nil {
+		return nil, err
 	}
 
 	s.Logger.Info(logrus.Fields{
@@ -159,62 +249,222 @@ func (s *Server) LookupSessionToken(ctx context.Context, token *pb.SessionToken)
 		"event": "respond",
 		"tag":   "identity",
 		"rpc":   "LookupSessionToken"},
-		fmt.Sprintf("Returning User ID %s for token %s ", uuid, token.Id))
+		fmt.Sprintf("Returning User ID %s for token family %s", session.UserID, session.Family))
 
-	return &pb.UserId{Uuid: uuid}, nil
+	return &pb.UserId{Uuid: session.UserID}, nil
 }
 
 func (s *Server) CloseSession(ctx context.Context, token *pb.SessionToken) (*pb.Response, error) {
-	var reply *redis.Reply
-	var err error
-	sessionTokenKey := strings.Join([]string{"token", token.Id}, "_")
+	session, err := s.lookup(token.Id, "CloseSession")
+	if err != nil {
+		return nil, err
+	}
 
-	reply, err = s.Pool.Get(sessionTokenKey)
-	uuid, uuidErr := reply.Str()
-	if uuidErr != nil || err != nil {
+	err = s.Sessions.RevokeFamily(session.UserID, session.Family)
+	if err == ErrSessionNotFound {
 		s.Logger.Error(logrus.Fields{
 			"phase": "process",
-			"event": "fetch",
-			"tag":   "redis",
+			"event": "delete",
+			"tag":   "sessionstore",
 			"rpc":   "CloseSession"},
-			fmt.Sprintf("Cannot fetch user ID for session token %s. Error: %v, %v", sessionTokenKey, uuidErr, err))
-		return nil, grpc.Errorf(codes.Internal, "Cannot fetch user ID for session token %s", sessionTokenKey)
+			fmt.Sprintf("Token family %s does not exist", session.Family))
+		return nil, grpc.Errorf(codes.NotFound, "Token family %s does not exist", session.Family)
+	}
+	if err != nil {
+		errorMsg := fmt.Sprintf("Could not revoke token family %s. Error: %v", session.Family, err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "delete",
+			"tag":   "sessionstore",
+			"rpc":   "CloseSession"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
 	}
 
-	if uuid == "" {
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "respond",
+		"tag":   "identity",
+		"rpc":   "CloseSession"},
+		fmt.Sprintf("Closed session for token family %s", session.Family))
+	return &pb.Response{Success: true}, nil
+}
+
+// ListSessions returns the live sessions of the access token's user, marking
+// the one the token belongs to as current.
+func (s *Server) ListSessions(ctx context.Context, token *pb.SessionToken) (*pb.SessionList, error) {
+	current, err := s.lookup(token.Id, "ListSessions")
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.Sessions.List(current.UserID)
+	if err != nil {
 		s.Logger.Error(logrus.Fields{
 			"phase": "process",
 			"event": "fetch",
-			"tag":   "redis",
-			"rpc":   "CloseSession"},
-			fmt.Sprintf("Session token %s does not exist", token.Id))
-		return nil, grpc.Errorf(codes.Unknown, "Session token %s does not exist", token.Id)
+			"tag":   "sessionstore",
+			"rpc":   "ListSessions"},
+			fmt.Sprintf("Cannot list sessions for user %s. Error: %v", current.UserID, err))
+		return nil, grpc.Errorf(codes.Internal, "Cannot list sessions for user %s", current.UserID)
 	}
 
-	userKey := strings.Join([]string{"user", uuid}, "_")
-	reply, err = s.Pool.Del(sessionTokenKey, userKey)
+	list := &pb.SessionList{Sessions: make([]*pb.SessionInfo, 0, len(sessions))}
+	for _, session := range sessions {
+		list.Sessions = append(list.Sessions, sessionInfo(session, current.Family))
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "respond",
+		"tag":   "identity",
+		"rpc":   "ListSessions"},
+		fmt.Sprintf("Returning %d sessions for user %s", len(list.Sessions), current.UserID))
+	return list, nil
+}
+
+// RevokeSession ends one of the sessions of the access token's user.
+func (s *Server) RevokeSession(ctx context.Context, request *pb.RevokeSessionRequest) (*pb.Response, error) {
+	current, err := s.lookup(request.Token, "RevokeSession")
+	if err != nil {
+		return nil, err
+	}
 
-	deletedItems, deleteErr := reply.Int()
-	if deleteErr != nil || err != nil {
-		errorMsg := fmt.Sprintf("Could not delete session and user keys. Error: %v, %v", deleteErr, err)
+	err = s.Sessions.RevokeFamily(current.UserID, request.Sessionid)
+	if err == ErrSessionNotFound {
 		s.Logger.Error(logrus.Fields{
 			"phase": "process",
 			"event": "delete",
-			"tag":   "redis",
-			"rpc":   "CloseSession"},
+			"tag":   "sessionstore",
+			"rpc":   "RevokeSession"},
+			fmt.Sprintf("User %s has no session %s", current.UserID, request.Sessionid))
+		return nil, grpc.Errorf(codes.NotFound, "Session %s does not exist", request.Sessionid)
+	}
+	if err != nil {
+		errorMsg := fmt.Sprintf("Could not revoke session %s. Error: %v", request.Sessionid, err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "delete",
+			"tag":   "sessionstore",
+			"rpc":   "RevokeSession"},
 			errorMsg)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "respond",
+		"tag":   "identity",
+		"rpc":   "RevokeSession"},
+		fmt.Sprintf("Revoked session %s of user %s", request.Sessionid, current.UserID))
+	return &pb.Response{Success: true}, nil
+}
 
-		return nil, grpc.Errorf(codes.Unknown, errorMsg)
+// RevokeOtherSessions ends every session of the access token's user except
+// the one the token belongs to.
+func (s *Server) RevokeOtherSessions(ctx context.Context, token *pb.SessionToken) (*pb.RevokeSessionsResponse, error) {
+	current, err := s.lookup(token.Id, "RevokeOtherSessions")
+	if err != nil {
+		return nil, err
 	}
-	if deletedItems != 2 {
-		errorMsg := fmt.Sprintf("Inconsistant state, %d keys deleted (should be 2)", deletedItems)
+
+	revoked, err := s.Sessions.RevokeOthers(current.UserID, current.Family)
+	if err != nil {
+		errorMsg := fmt.Sprintf("Could not revoke sessions other than token family %s. Error: %v", current.Family, err)
 		s.Logger.Error(logrus.Fields{
 			"phase": "process",
 			"event": "delete",
-			"tag":   "redis",
-			"rpc":   "CloseSession"},
+			"tag":   "sessionstore",
+			"rpc":   "RevokeOtherSessions"},
 			errorMsg)
 		return nil, grpc.Errorf(codes.Internal, errorMsg)
 	}
-	return &pb.Response{Success: true}, nil
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "respond",
+		"tag":   "identity",
+		"rpc":   "RevokeOtherSessions"},
+		fmt.Sprintf("Revoked %d sessions other than token family %s", revoked, current.Family))
+	return &pb.RevokeSessionsResponse{Revoked: int32(revoked)}, nil
+}
+
+// RevokeAllSessions ends every session of a user, such as after their
+// password was reset. Only for other services; clients use
+// RevokeOtherSessions.
+func (s *Server) RevokeAllSessions(ctx context.Context, userID *pb.UserId) (*pb.RevokeSessionsResponse, error) {
+	if userID.Uuid == "" {
+		errorMsg := fmt.Sprintf("Identifier not specified.")
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "RevokeAllSessions"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.InvalidArgument, errorMsg)
+	}
+
+	revoked, err := s.Sessions.RevokeOthers(userID.Uuid, "")
+	if err != nil {
+		errorMsg := fmt.Sprintf("Could not revoke sessions of user %s. Error: %v", userID.Uuid, err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "delete",
+			"tag":   "sessionstore",
+			"rpc":   "RevokeAllSessions"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "respond",
+		"tag":   "identity",
+		"rpc":   "RevokeAllSessions"},
+		fmt.Sprintf("Revoked %d sessions of user %s", revoked, userID.Uuid))
+	return &pb.RevokeSessionsResponse{Revoked: int32(revoked)}, nil
+}
+
+// GetKeySet publishes the public keys signed tokens are verified with, as a
+// JSON Web Key Set. The set is empty when tokens are opaque.
+func (s *Server) GetKeySet(ctx context.Context, null *pb.EmptyRequest) (*pb.KeySet, error) {
+	set := &util.KeySet{Keys: []util.JSONWebKey{}}
+	if s.Signer != nil {
+		set = s.Signer.KeySet()
+	}
+	jwks, err := json.Marshal(set)
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "respond",
+			"tag":   "tokensigner",
+			"rpc":   "GetKeySet"},
+			fmt.Sprintf("Cannot encode key set. Error: %v", err))
+		return nil, grpc.Errorf(codes.Internal, "Cannot encode key set.")
+	}
+	return &pb.KeySet{Jwks: string(jwks)}, nil
+}
+
+// GetRevokedSessions lists the sessions closed while signed tokens issued
+// for them may still be unexpired.
+func (s *Server) GetRevokedSessions(ctx context.Context, null *pb.EmptyRequest) (*pb.RevokedSessions, error) {
+	revoked, err := s.Sessions.Revoked()
+	if err != nil {
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "sessionstore",
+			"rpc":   "GetRevokedSessions"},
+			fmt.Sprintf("Cannot list revoked sessions. Error: %v", err))
+		return nil, grpc.Errorf(codes.Internal, "Cannot list revoked sessions.")
+	}
+
+	sessions := &pb.RevokedSessions{Sessions: make([]*pb.RevokedSession, 0, len(revoked))}
+	for _, session := range revoked {
+		sessions.Sessions = append(sessions.Sessions, &pb.RevokedSession{
+			Id:      session.Family,
+			Expires: &pb.Timestamp{Seconds: session.Expires.Unix()},
+		})
+	}
+	return sessions, nil
 }
@@ -0,0 +1,449 @@
+/*
+// ----------------------------------------------------------------------------
+// identity_test.go
+// Countertop Identity Microservice Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package identity_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	pb "github.com/theorangechefco/cts/go-protos"
+	"github.com/theorangechefco/cts/go-shared-libs/cts/logger"
+	"github.com/theorangechefco/cts/go-shared-libs/cts/util"
+	identity "github.com/theorangechefco/cts/identity"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const testUUID = "d17eaf65-244a-4913-83d8-1583bb3cbbfd"
+
+var (
+	phone  = identity.Device{ID: "abc123", Appversion: "1.2.0"}
+	tablet = identity.Device{ID: "def456", Appversion: "1.1.0"}
+)
+
+func newTestServer(store identity.SessionStore) *identity.Server {
+	return &identity.Server{
+		Logger:     logger.NewLogger("test", "", 8080, false, logrus.ErrorLevel),
+		Sessions:   store,
+		AccessTTL:  15 * time.Minute,
+		RefreshTTL: 24 * time.Hour,
+	}
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store identity.SessionStore) {
+		server := newTestServer(store)
+		ctx := context.Background()
+		request := &pb.SessionRequest{Uuid: testUUID, Deviceid: "abc123", Appversion: "1.2.0"}
+
+		token, err := server.GenerateSessionToken(ctx, request)
+		if err != nil {
+			t.Fatalf("GenerateSessionToken(_) = _, %v", err)
+		}
+		if token.Refreshtoken == "" || token.Refreshtoken == token.Id {
+			t.Errorf("GenerateSessionToken(_) = %v, want distinct access and refresh tokens", token)
+		}
+
+		found, err := server.LookupSessionToken(ctx, token)
+		if err != nil || found.Uuid != testUUID {
+			t.Errorf("LookupSessionToken(_) = %v, %v, want %s", found, err, testUUID)
+		}
+		if _, err := server.LookupSessionToken(ctx, &pb.SessionToken{Id: token.Refreshtoken}); grpc.Code(err) != codes.NotFound {
+			t.Errorf("LookupSessionToken(refresh token) = _, %v, want %v", err, codes.NotFound)
+		}
+
+		if response, err := server.CloseSession(ctx, token); err != nil || !response.Success {
+			t.Errorf("CloseSession(_) = %v, %v", response, err)
+		}
+		if _, err := server.LookupSessionToken(ctx, token); grpc.Code(err) != codes.NotFound {
+			t.Errorf("LookupSessionToken(closed) = _, %v, want %v", err, codes.NotFound)
+		}
+		if _, err := server.CloseSession(ctx, token); grpc.Code(err) != codes.NotFound {
+			t.Errorf("CloseSession(closed) = _, %v, want %v", err, codes.NotFound)
+		}
+		if _, err := server.RefreshSession(ctx, &pb.RefreshRequest{Refreshtoken: token.Refreshtoken}); grpc.Code(err) != codes.Unauthenticated {
+			t.Errorf("RefreshSession(closed) = _, %v, want %v", err, codes.Unauthenticated)
+		}
+
+		replacement, err := server.GenerateSessionToken(ctx, request)
+		if err != nil || replacement.Id == token.Id {
+			t.Errorf("GenerateSessionToken(_) = %v, %v, want a new token", replacement, err)
+		}
+	})
+}
+
+func TestRefreshSession(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store identity.SessionStore) {
+		server := newTestServer(store)
+		ctx := context.Background()
+
+		first, err := server.GenerateSessionToken(ctx, &pb.SessionRequest{Uuid: testUUID})
+		if err != nil {
+			t.Fatalf("GenerateSessionToken(_) = _, %v", err)
+		}
+		second, err := server.RefreshSession(ctx, &pb.RefreshRequest{Refreshtoken: first.Refreshtoken})
+		if err != nil {
+			t.Fatalf("RefreshSession(_) = _, %v", err)
+		}
+		if second.Id == first.Id || second.Refreshtoken == first.Refreshtoken {
+			t.Errorf("RefreshSession(_) = %v, want new access and refresh tokens", second)
+		}
+		for _, token := range []*pb.SessionToken{first, second} {
+			if found, err := server.LookupSessionToken(ctx, token); err != nil || found.Uuid != testUUID {
+				t.Errorf("LookupSessionToken(%s) = %v, %v, want %s", token.Id, found, err, testUUID)
+			}
+		}
+
+		// Replaying the first refresh token revokes the whole family
+		if _, err := server.RefreshSession(ctx, &pb.RefreshRequest{Refreshtoken: first.Refreshtoken}); grpc.Code(err) != codes.Unauthenticated {
+			t.Errorf("RefreshSession(reused) = _, %v, want %v", err, codes.Unauthenticated)
+		}
+		for _, token := range []*pb.SessionToken{first, second} {
+			if _, err := server.LookupSessionToken(ctx, token); grpc.Code(err) != codes.NotFound {
+				t.Errorf("LookupSessionToken(%s) = _, %v after reuse, want %v", token.Id, err, codes.NotFound)
+			}
+		}
+		if _, err := server.RefreshSession(ctx, &pb.RefreshRequest{Refreshtoken: second.Refreshtoken}); grpc.Code(err) != codes.Unauthenticated {
+			t.Errorf("RefreshSession(revoked) = _, %v, want %v", err, codes.Unauthenticated)
+		}
+		if _, err := server.RefreshSession(ctx, &pb.RefreshRequest{}); grpc.Code(err) != codes.InvalidArgument {
+			t.Errorf("RefreshSession(empty) = _, %v, want %v", err, codes.InvalidArgument)
+		}
+	})
+}
+
+func TestMemorySessionExpiry(t *testing.T) {
+	now := time.Date(2015, 12, 7, 12, 0, 0, 0, time.UTC)
+	store := identity.NewMemorySessionStore()
+	store.Now = func() time.Time { return now }
+
+	session, err := store.Create(testUUID, phone, 15*time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("Create(_) = _, %v", err)
+	}
+	if !session.AccessExpires.Equal(now.Add(15*time.Minute)) || !session.RefreshExpires.Equal(now.Add(time.Hour)) {
+		t.Errorf("Create(_) = %v, want access and refresh tokens expiring at %v and %v", session, now.Add(15*time.Minute), now.Add(time.Hour))
+	}
+
+	now = now.Add(15 * time.Minute)
+	if _, err := store.Lookup(session.AccessToken); err != identity.ErrSessionNotFound {
+		t.Errorf("Lookup(_) = _, %v after access expiry, want %v", err, identity.ErrSessionNotFound)
+	}
+
+	// Refreshing pushes the family's expiry out a full refresh TTL
+	now = now.Add(30 * time.Minute)
+	refreshed, err := store.Refresh(session.RefreshToken, 15*time.Minute, time.Hour)
+	if err != nil || refreshed.Family != session.Family || !refreshed.RefreshExpires.Equal(now.Add(time.Hour)) {
+		t.Errorf("Refresh(_) = %v, %v, want family %s expiring at %v", refreshed, err, session.Family, now.Add(time.Hour))
+	}
+	if _, err := store.Lookup(refreshed.AccessToken); err != nil {
+		t.Errorf("Lookup(_) = _, %v after refresh", err)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := store.Refresh(refreshed.RefreshToken, 15*time.Minute, time.Hour); err != identity.ErrSessionNotFound {
+		t.Errorf("Refresh(_) = _, %v after refresh expiry, want %v", err, identity.ErrSessionNotFound)
+	}
+
+	replacement, err := store.Create(testUUID, phone, 15*time.Minute, time.Hour)
+	if err != nil || replacement.Family == session.Family {
+		t.Errorf("Create(_) = %v, %v after expiry, want a new family", replacement, err)
+	}
+}
+
+func TestMemorySessionCreateReplacesDeviceFamily(t *testing.T) {
+	store := identity.NewMemorySessionStore()
+	first, err := store.Create(testUUID, phone, time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("Create(_) = _, %v", err)
+	}
+	second, err := store.Create(testUUID, phone, time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("Create(_) = _, %v", err)
+	}
+	if _, err := store.Lookup(first.AccessToken); err != identity.ErrSessionNotFound {
+		t.Errorf("Lookup(replaced) = _, %v, want %v", err, identity.ErrSessionNotFound)
+	}
+	if _, err := store.Refresh(first.RefreshToken, time.Minute, time.Hour); err != identity.ErrSessionNotFound {
+		t.Errorf("Refresh(replaced) = _, %v, want %v", err, identity.ErrSessionNotFound)
+	}
+	if found, err := store.Lookup(second.AccessToken); err != nil || found.UserID != testUUID {
+		t.Errorf("Lookup(_) = %v, %v, want %s", found, err, testUUID)
+	}
+}
+
+func TestSessionsPerDevice(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store identity.SessionStore) {
+		server := newTestServer(store)
+		ctx := context.Background()
+
+		phoneToken, err := server.GenerateSessionToken(ctx, &pb.SessionRequest{Uuid: testUUID, Deviceid: phone.ID, Appversion: phone.Appversion})
+		if err != nil {
+			t.Fatalf("GenerateSessionToken(phone) = _, %v", err)
+		}
+		tabletToken, err := server.GenerateSessionToken(ctx, &pb.SessionRequest{Uuid: testUUID, Deviceid: tablet.ID, Appversion: tablet.Appversion})
+		if err != nil {
+			t.Fatalf("GenerateSessionToken(tablet) = _, %v", err)
+		}
+		otherToken, err := server.GenerateSessionToken(ctx, &pb.SessionRequest{Uuid: "a0d3c6d4-0d5e-4bde-9d37-5b7e8c9a2f10", Deviceid: phone.ID})
+		if err != nil {
+			t.Fatalf("GenerateSessionToken(other user) = _, %v", err)
+		}
+
+		list, err := server.ListSessions(ctx, tabletToken)
+		if err != nil {
+			t.Fatalf("ListSessions(_) = _, %v", err)
+		}
+		if len(list.Sessions) != 2 {
+			t.Fatalf("ListSessions(_) returned %d sessions, want 2", len(list.Sessions))
+		}
+		var phoneSession *pb.SessionInfo
+		for _, session := range list.Sessions {
+			if session.Deviceid == phone.ID {
+				phoneSession = session
+			}
+			if session.Current != (session.Deviceid == tablet.ID) {
+				t.Errorf("ListSessions(tablet) marked %s current = %t", session.Deviceid, session.Current)
+			}
+		}
+		if phoneSession == nil || phoneSession.Appversion != phone.Appversion || phoneSession.Created == nil {
+			t.Fatalf("ListSessions(_) = %v, want a session for %s", list.Sessions, phone.ID)
+		}
+
+		// Closing one device's session leaves the other signed in
+		if _, err := server.CloseSession(ctx, phoneToken); err != nil {
+			t.Errorf("CloseSession(phone) = _, %v", err)
+		}
+		if _, err := server.LookupSessionToken(ctx, tabletToken); err != nil {
+			t.Errorf("LookupSessionToken(tablet) = _, %v after closing phone session", err)
+		}
+
+		phoneToken, _ = server.GenerateSessionToken(ctx, &pb.SessionRequest{Uuid: testUUID, Deviceid: phone.ID})
+		list, _ = server.ListSessions(ctx, phoneToken)
+		for _, session := range list.Sessions {
+			if session.Current {
+				continue
+			}
+			// Users cannot revoke each other's sessions
+			if _, err := server.RevokeSession(ctx, &pb.RevokeSessionRequest{Token: otherToken.Id, Sessionid: session.Id}); grpc.Code(err) != codes.NotFound {
+				t.Errorf("RevokeSession(other user) = _, %v, want %v", err, codes.NotFound)
+			}
+			if _, err := server.RevokeSession(ctx, &pb.RevokeSessionRequest{Token: phoneToken.Id, Sessionid: session.Id}); err != nil {
+				t.Errorf("RevokeSession(_) = _, %v", err)
+			}
+		}
+		if _, err := server.LookupSessionToken(ctx, tabletToken); grpc.Code(err) != codes.NotFound {
+			t.Errorf("LookupSessionToken(revoked) = _, %v, want %v", err, codes.NotFound)
+		}
+
+		tabletToken, _ = server.GenerateSessionToken(ctx, &pb.SessionRequest{Uuid: testUUID, Deviceid: tablet.ID})
+		response, err := server.RevokeOtherSessions(ctx, phoneToken)
+		if err != nil || response.Revoked != 1 {
+			t.Errorf("RevokeOtherSessions(_) = %v, %v, want 1 revoked", response, err)
+		}
+		if _, err := server.LookupSessionToken(ctx, tabletToken); grpc.Code(err) != codes.NotFound {
+			t.Errorf("LookupSessionToken(tablet) = _, %v after RevokeOtherSessions, want %v", err, codes.NotFound)
+		}
+		for _, token := range []*pb.SessionToken{phoneToken, otherToken} {
+			if _, err := server.LookupSessionToken(ctx, token); err != nil {
+				t.Errorf("LookupSessionToken(%s) = _, %v after RevokeOtherSessions", token.Id, err)
+			}
+		}
+
+		response, err = server.RevokeAllSessions(ctx, &pb.UserId{Uuid: testUUID})
+		if err != nil || response.Revoked != 1 {
+			t.Errorf("RevokeAllSessions(_) = %v, %v, want 1 revoked", response, err)
+		}
+		if _, err := server.LookupSessionToken(ctx, phoneToken); grpc.Code(err) != codes.NotFound {
+			t.Errorf("LookupSessionToken(phone) = _, %v after RevokeAllSessions, want %v", err, codes.NotFound)
+		}
+		if _, err := server.LookupSessionToken(ctx, otherToken); err != nil {
+			t.Errorf("LookupSessionToken(other user) = _, %v after RevokeAllSessions", err)
+		}
+	})
+}
+
+func TestMemorySessionLastSeen(t *testing.T) {
+	now := time.Date(2015, 12, 7, 12, 0, 0, 0, time.UTC)
+	store := identity.NewMemorySessionStore()
+	store.Now = func() time.Time { return now }
+
+	first, _ := store.Create(testUUID, phone, time.Hour, 24*time.Hour)
+	now = now.Add(time.Minute)
+	store.Create(testUUID, tablet, time.Hour, 24*time.Hour)
+	now = now.Add(time.Minute)
+	if _, err := store.Lookup(first.AccessToken); err != nil {
+		t.Fatalf("Lookup(_) = _, %v", err)
+	}
+
+	sessions, err := store.List(testUUID)
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("List(_) = %v, %v, want 2 sessions", sessions, err)
+	}
+	if sessions[0].Device != phone || !sessions[0].LastSeen.Equal(now) || !sessions[0].Created.Equal(now.Add(-2*time.Minute)) {
+		t.Errorf("List(_)[0] = %v, want %v created at %v and seen at %v", sessions[0], phone, now.Add(-2*time.Minute), now)
+	}
+	if sessions[1].Device != tablet {
+		t.Errorf("List(_)[1] = %v, want %v", sessions[1], tablet)
+	}
+}
+
+// newTestSigner writes a key per ID to a temporary directory and loads them.
+func newTestSigner(t *testing.T, active string, ids ...string) *util.TokenSigner {
+	dir, err := ioutil.TempDir("", "signingkeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, id := range ids {
+		key, err := rsa.GenerateKey(rand.Reader, 1024)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+		if err := ioutil.WriteFile(filepath.Join(dir, id+".pem"), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	signer, err := util.LoadTokenSigner(dir, active)
+	if err != nil {
+		t.Fatalf("LoadTokenSigner(_, %s) = _, %v", active, err)
+	}
+	return signer
+}
+
+// syncVerifier loads the key set and revocation list the server publishes.
+func syncVerifier(t *testing.T, server *identity.Server, verifier *util.TokenVerifier) {
+	published, err := server.GetKeySet(context.Background(), &pb.EmptyRequest{})
+	if err != nil {
+		t.Fatalf("GetKeySet(_) = _, %v", err)
+	}
+	var set util.KeySet
+	if err := json.Unmarshal([]byte(published.Jwks), &set); err != nil {
+		t.Fatalf("GetKeySet(_) = %s, not a key set: %v", published.Jwks, err)
+	}
+	if err := verifier.SetKeySet(&set); err != nil {
+		t.Fatalf("SetKeySet(_) = %v", err)
+	}
+	sessions, err := server.GetRevokedSessions(context.Background(), &pb.EmptyRequest{})
+	if err != nil {
+		t.Fatalf("GetRevokedSessions(_) = _, %v", err)
+	}
+	revoked := make(map[string]time.Time)
+	for _, session := range sessions.Sessions {
+		revoked[session.Id] = time.Unix(session.Expires.Seconds, 0)
+	}
+	verifier.SetRevoked(revoked)
+}
+
+func TestSignedSessionTokens(t *testing.T) {
+	server := newTestServer(identity.NewMemorySessionStore())
+	server.Signer = newTestSigner(t, "2015-12", "2015-11", "2015-12")
+	ctx := context.Background()
+	verifier := util.NewTokenVerifier()
+
+	token, err := server.GenerateSessionToken(ctx, &pb.SessionRequest{Uuid: testUUID, Deviceid: phone.ID})
+	if err != nil {
+		t.Fatalf("GenerateSessionToken(_) = _, %v", err)
+	}
+	if !util.IsSignedToken(token.Id) || util.IsSignedToken(token.Refreshtoken) {
+		t.Fatalf("GenerateSessionToken(_) = %v, want a signed access token and an opaque refresh token", token)
+	}
+
+	// Until the key set is synced, the token has to be looked up
+	if _, err := verifier.Verify(token.Id); err != util.ErrUnknownTokenKey {
+		t.Errorf("Verify(_) = _, %v before sync, want %v", err, util.ErrUnknownTokenKey)
+	}
+	syncVerifier(t, server, verifier)
+	claims, err := verifier.Verify(token.Id)
+	if err != nil || claims.UserID != testUUID || claims.SessionID == "" || claims.Expires != token.Ttl.Seconds {
+		t.Errorf("Verify(_) = %v, %v, want claims for %s expiring at %d", claims, err, testUUID, token.Ttl.Seconds)
+	}
+	if found, err := server.LookupSessionToken(ctx, token); err != nil || found.Uuid != testUUID {
+		t.Errorf("LookupSessionToken(signed) = %v, %v, want %s", found, err, testUUID)
+	}
+
+	segments := strings.Split(token.Id, ".")
+	forged := segments[0] + "." + segments[1] + "." + strings.Repeat("A", len(segments[2]))
+	if _, err := verifier.Verify(forged); err != util.ErrBadTokenSignature {
+		t.Errorf("Verify(forged) = _, %v, want %v", err, util.ErrBadTokenSignature)
+	}
+	if _, err := server.LookupSessionToken(ctx, &pb.SessionToken{Id: forged}); grpc.Code(err) != codes.NotFound {
+		t.Errorf("LookupSessionToken(forged) = _, %v, want %v", err, codes.NotFound)
+	}
+
+	// Tokens signed with a key that is no longer active keep verifying
+	server.Signer.Active = "2015-11"
+	rotated, err := server.RefreshSession(ctx, &pb.RefreshRequest{Refreshtoken: token.Refreshtoken})
+	if err != nil {
+		t.Fatalf("RefreshSession(_) = _, %v", err)
+	}
+	for _, signed := range []string{token.Id, rotated.Id} {
+		if _, err := verifier.Verify(signed); err != nil {
+			t.Errorf("Verify(_) = _, %v after rotation", err)
+		}
+	}
+
+	verifier.Now = func() time.Time { return time.Unix(rotated.Ttl.Seconds, 0) }
+	if _, err := verifier.Verify(rotated.Id); err != util.ErrTokenExpired {
+		t.Errorf("Verify(_) = _, %v at expiry, want %v", err, util.ErrTokenExpired)
+	}
+	verifier.Now = time.Now
+
+	// Closing the session puts it on the revocation list
+	if _, err := server.CloseSession(ctx, rotated); err != nil {
+		t.Fatalf("CloseSession(signed) = _, %v", err)
+	}
+	syncVerifier(t, server, verifier)
+	if _, err := verifier.Verify(rotated.Id); err != util.ErrTokenRevoked {
+		t.Errorf("Verify(_) = _, %v after CloseSession, want %v", err, util.ErrTokenRevoked)
+	}
+	if _, err := server.LookupSessionToken(ctx, rotated); grpc.Code(err) != codes.NotFound {
+		t.Errorf("LookupSessionToken(closed) = _, %v, want %v", err, codes.NotFound)
+	}
+}
+
+func TestMemorySessionRevocationList(t *testing.T) {
+	now := time.Date(2015, 12, 14, 12, 0, 0, 0, time.UTC)
+	store := identity.NewMemorySessionStore()
+	store.Now = func() time.Time { return now }
+
+	first, _ := store.Create(testUUID, phone, 15*time.Minute, time.Hour)
+	second, _ := store.Create(testUUID, tablet, 15*time.Minute, time.Hour)
+	if revoked, err := store.RevokeOthers(testUUID, second.Family); err != nil || revoked != 1 {
+		t.Errorf("RevokeOthers(_) = %d, %v, want 1", revoked, err)
+	}
+	if _, err := store.Family(first.Family); err != identity.ErrSessionNotFound {
+		t.Errorf("Family(revoked) = _, %v, want %v", err, identity.ErrSessionNotFound)
+	}
+
+	revoked, err := store.Revoked()
+	want := identity.RevokedSession{Family: first.Family, Expires: first.AccessExpires}
+	if err != nil || len(revoked) != 1 || revoked[0] != want {
+		t.Errorf("Revoked() = %v, %v, want [%v]", revoked, err, want)
+	}
+
+	now = now.Add(15 * time.Minute)
+	if revoked, err := store.Revoked(); err != nil || len(revoked) != 0 {
+		t.Errorf("Revoked() = %v, %v once access tokens expired, want none", revoked, err)
+	}
+}
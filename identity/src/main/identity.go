@@ -16,6 +16,7 @@ import (
 	"flag"
 	"fmt"
 	"net"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/theorangechefco/cts/go-shared-libs/cts/logger"
@@ -30,10 +31,14 @@ import (
 
 var (
 	port          = flag.Int("port", 50051, "The server port")
+	sessionStore  = flag.String("session_store", "redis", "Session store: redis, or memory for a single node without Redis")
 	redisHost     = flag.String("redis_host", "127.0.0.1:6379", "Hostname of Redis server")
 	redisPass     = flag.String("redis_pass", "abc", "Redis password (optional)")
 	redisPoolSize = flag.Int("redis_pool_size", 150, "Redis pool size")
-	redisTTL      = flag.Int("redis_ttl", 86400, "Redis TTL in seconds")
+	accessTTL     = flag.Duration("access_ttl", 15*time.Minute, "Lifetime of access tokens")
+	refreshTTL    = flag.Duration("refresh_ttl", 30*24*time.Hour, "Lifetime of refresh tokens, extended each time the session is refreshed")
+	signingKeys   = flag.String("signing_keys", "", "Directory of PEM encoded RSA private keys, one <key ID>.pem per key, used to issue signed access tokens. If left blank, access tokens are opaque")
+	activeKey     = flag.String("active_key", "", "ID of the signing key new tokens are signed with. The other keys are still published, for rotation")
 	tls           = flag.Bool("tls", false, "Connection uses TLS if true, else plain TCP")
 	certFile      = flag.String("cert_file", "keys/server1.pem", "The TLS cert file")
 	keyFile       = flag.String("key_file", "keys/server1.key", "The TLS key file")
@@ -47,19 +52,50 @@ func main() {
 
 	identityServerInstance := new(identityutil.Server)
 	identityServerInstance.Logger = logger.NewLogger("identitysrv", *fluentdHost, *fluentdPort, *stdErrLog, logrus.DebugLevel)
-	identityServerInstance.TTL = *redisTTL
-	pool, err := util.NewPool(identityServerInstance.Logger, *redisHost, *redisPass, *redisPoolSize, 3, 500)
-	if err != nil {
-		identityServerInstance.Logger.Fatal(logrus.Fields{
+	identityServerInstance.AccessTTL = *accessTTL
+	identityServerInstance.RefreshTTL = *refreshTTL
+	switch *sessionStore {
+	case "redis":
+		pool, err := util.NewPool(identityServerInstance.Logger, *redisHost, *redisPass, *redisPoolSize, 3, 500)
+		if err != nil {
+			identityServerInstance.Logger.Fatal(logrus.Fields{
+				"phase": "startup",
+				"event": "connect"},
+				fmt.Sprintf("Unable to set up Redis pool: %v", err))
+		}
+		identityServerInstance.Logger.Info(logrus.Fields{
 			"phase": "startup",
 			"event": "connect"},
-			fmt.Sprintf("Unable to set up Redis pool: %v", err))
+			fmt.Sprintf("Successfully setup pool with %d connections.", *redisPoolSize))
+		identityServerInstance.Sessions = &identityutil.RedisSessionStore{Pool: pool}
+	case "memory":
+		identityServerInstance.Sessions = identityutil.NewMemorySessionStore()
+		identityServerInstance.Logger.Info(logrus.Fields{
+			"phase": "startup",
+			"event": "setup"},
+			"Keeping sessions in memory, they will be lost when the server exits")
+	default:
+		identityServerInstance.Logger.Fatal(logrus.Fields{
+			"phase": "startup",
+			"event": "setup"},
+			fmt.Sprintf("Unknown session store %q, expected redis or memory", *sessionStore))
+	}
+
+	if *signingKeys != "" {
+		signer, err := util.LoadTokenSigner(*signingKeys, *activeKey)
+		if err != nil {
+			identityServerInstance.Logger.Fatal(logrus.Fields{
+				"phase": "startup",
+				"event": "setup",
+				"tags":  "signingkeys"},
+				fmt.Sprintf("Unable to load signing keys: %v", err))
+		}
+		identityServerInstance.Signer = signer
+		identityServerInstance.Logger.Info(logrus.Fields{
+			"phase": "startup",
+			"event": "setup"},
+			fmt.Sprintf("Issuing signed access tokens with key %s of %d published keys", *activeKey, len(signer.Keys)))
 	}
-	identityServerInstance.Logger.Info(logrus.Fields{
-		"phase": "startup",
-		"event": "connect"},
-		fmt.Sprintf("Successfully setup pool with %d connections.", *redisPoolSize))
-	identityServerInstance.Pool = pool
 
 	lis, lisErr := net.Listen("tcp", fmt.Sprintf(":%d", *port))
 	if lisErr != nil {
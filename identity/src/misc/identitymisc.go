@@ -15,7 +15,10 @@ import (
 	"encoding/base64"
 )
 
-const SESSION_KEY_LENGTH int = 64
+const (
+	SESSION_KEY_LENGTH int = 64
+	FAMILY_ID_LENGTH   int = 16
+)
 
 // Bootlegged from https://elithrar.github.io/article/generating-secure-random-numbers-crypto-rand/
 
@@ -43,3 +46,10 @@ func GenerateSessionKey() (string, error) {
 	b, err := GenerateRandomBytes(SESSION_KEY_LENGTH)
 	return base64.URLEncoding.EncodeToString(b), err
 }
+
+// GenerateFamilyID returns a URL-safe identifier for a token family, the
+// chain of access and refresh tokens issued from one login.
+func GenerateFamilyID() (string, error) {
+	b, err := GenerateRandomBytes(FAMILY_ID_LENGTH)
+	return base64.URLEncoding.EncodeToString(b), err
+}
@@ -0,0 +1,565 @@
+/*
+// ----------------------------------------------------------------------------
+// redisstore.go
+// Countertop Identity Microservice Redis Session Store
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package identity
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fzzy/radix/redis"
+	"github.com/theorangechefco/cts/go-shared-libs/cts/util"
+	misc "github.com/theorangechefco/cts/identity/misc"
+)
+
+const tokenAttempts = 5
+
+// A token family is stored as a family_<id> hash holding the user ID, the
+// device, creation and last seen times, and the current refresh token.
+// access_<token> and refresh_<token> hold the family ID, and user_<uuid> is a
+// hash of the user's device IDs to their family. The family and its refresh
+// tokens expire together; access tokens expire sooner. Revoking a family
+// deletes its hash, which invalidates every token pointing at it, and adds
+// it to the revokedsessions sorted set, scored by when its last access token
+// expires. Each operation is a Lua script so that Redis applies it
+// atomically.
+//
+// Scripts only touch keys passed in KEYS, as Redis Cluster requires. Keys
+// named by other keys' values are read first, and the scripts check that
+// those values did not change in between.
+const (
+	// Defines revoke(familyKey, userKey, revokedKey, family), shared by the
+	// scripts that end families. userKey must be the family's user's key.
+	// Returns 1 if the family existed.
+	revokeFunction = `
+local function revoke(familyKey, userKey, revokedKey, family)
+	local device, expires = unpack(redis.call('HMGET', familyKey, 'device', 'accessexpires'))
+	if not device then
+		return 0
+	end
+	redis.call('DEL', familyKey)
+	if redis.call('HGET', userKey, device) == family then
+		redis.call('HDEL', userKey, device)
+	end
+	redis.call('ZADD', revokedKey, expires, family)
+	return 1
+end
+`
+
+	// KEYS: user key, candidate family key, candidate access key, candidate
+	// refresh key, revoked sessions key, and the family key of the device's
+	// current family, if it has one
+	// ARGV: family ID, user ID, refresh token, access TTL, refresh TTL,
+	// device ID, app version, current time, the device's current family ID
+	// or ''
+	// Returns ok, taken if a candidate key is taken, or changed if the
+	// device's current family is not the one given.
+	createScript = revokeFunction + `
+if redis.call('EXISTS', KEYS[2]) == 1 or redis.call('EXISTS', KEYS[3]) == 1 or redis.call('EXISTS', KEYS[4]) == 1 then
+	return {'taken'}
+end
+local old = redis.call('HGET', KEYS[1], ARGV[6]) or ''
+if old ~= ARGV[9] then
+	return {'changed'}
+end
+if old ~= '' then
+	revoke(KEYS[6], KEYS[1], KEYS[5], old)
+end
+redis.call('HMSET', KEYS[2], 'user', ARGV[2], 'refresh', ARGV[3], 'device', ARGV[6], 'appversion', ARGV[7], 'created', ARGV[8], 'lastseen', ARGV[8], 'accessexpires', ARGV[8] + ARGV[4])
+redis.call('EXPIRE', KEYS[2], ARGV[5])
+redis.call('HSET', KEYS[1], ARGV[6], ARGV[1])
+if redis.call('TTL', KEYS[1]) < tonumber(ARGV[5]) then
+	redis.call('EXPIRE', KEYS[1], ARGV[5])
+end
+redis.call('SET', KEYS[3], ARGV[1], 'EX', ARGV[4])
+redis.call('SET', KEYS[4], ARGV[1], 'EX', ARGV[5])
+return {'ok'}`
+
+	// KEYS: access key, family key
+	// ARGV: current time, family ID the access key held
+	// Returns the remaining TTL and the family fields, or nothing.
+	lookupScript = `
+if redis.call('GET', KEYS[1]) ~= ARGV[2] or redis.call('EXISTS', KEYS[2]) == 0 then
+	return {}
+end
+redis.call('HSET', KEYS[2], 'lastseen', ARGV[1])
+local fields = redis.call('HMGET', KEYS[2], 'user', 'device', 'appversion', 'created', 'lastseen')
+return {tostring(redis.call('TTL', KEYS[1])), unpack(fields)}`
+
+	// KEYS: refresh key, family key, user key, candidate access key,
+	// candidate refresh key, revoked sessions key
+	// ARGV: refresh token, new refresh token, access TTL, refresh TTL,
+	// current time, family ID the refresh key held, the family's user ID
+	// Returns ok with the family fields, reused if the refresh token was
+	// already exchanged, taken if a candidate key is taken, or nothing.
+	refreshScript = revokeFunction + `
+if redis.call('GET', KEYS[1]) ~= ARGV[6] or redis.call('HGET', KEYS[2], 'user') ~= ARGV[7] then
+	return {}
+end
+if redis.call('HGET', KEYS[2], 'refresh') ~= ARGV[1] then
+	revoke(KEYS[2], KEYS[3], KEYS[6], ARGV[6])
+	return {'reused'}
+end
+if redis.call('EXISTS', KEYS[4]) == 1 or redis.call('EXISTS', KEYS[5]) == 1 then
+	return {'taken'}
+end
+redis.call('HMSET', KEYS[2], 'refresh', ARGV[2], 'lastseen', ARGV[5], 'accessexpires', ARGV[5] + ARGV[3])
+redis.call('EXPIRE', KEYS[2], ARGV[4])
+if redis.call('TTL', KEYS[3]) < tonumber(ARGV[4]) then
+	redis.call('EXPIRE', KEYS[3], ARGV[4])
+end
+redis.call('SET', KEYS[4], ARGV[6], 'EX', ARGV[3])
+redis.call('SET', KEYS[5], ARGV[6], 'EX', ARGV[4])
+local fields = redis.call('HMGET', KEYS[2], 'user', 'device', 'appversion', 'created', 'lastseen')
+return {'ok', unpack(fields)}`
+
+	// KEYS: family key
+	// Returns the family fields, or nothing.
+	familyScript = `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return {}
+end
+return redis.call('HMGET', KEYS[1], 'user', 'device', 'appversion', 'created', 'lastseen')`
+
+	// KEYS: user key, then the family key of each of the user's devices
+	// ARGV: the device ID and family ID of each family key
+	// Returns the family ID and fields of each live session, dropping
+	// entries for families that have expired.
+	listScript = `
+local sessions = {}
+for i = 2, #KEYS do
+	local device, family = ARGV[2 * i - 3], ARGV[2 * i - 2]
+	local fields = redis.call('HMGET', KEYS[i], 'user', 'device', 'appversion', 'created', 'lastseen')
+	if fields[1] then
+		table.insert(sessions, family)
+		for _, field in ipairs(fields) do
+			table.insert(sessions, field)
+		end
+	elseif redis.call('HGET', KEYS[1], device) == family then
+		redis.call('HDEL', KEYS[1], device)
+	end
+end
+return sessions`
+
+	// KEYS: family key, user key, revoked sessions key
+	// ARGV: user ID, family ID
+	// Returns 1 if the user had the family.
+	revokeFamilyScript = revokeFunction + `
+if redis.call('HGET', KEYS[1], 'user') ~= ARGV[1] then
+	return 0
+end
+return revoke(KEYS[1], KEYS[2], KEYS[3], ARGV[2])`
+
+	// KEYS: user key, revoked sessions key, then the family key of each of
+	// the user's devices but the one kept
+	// ARGV: family ID to keep, then the device ID and family ID of each
+	// family key
+	// Returns the number of other families revoked, or -1 if the user's
+	// devices are not the ones given.
+	revokeOthersScript = revokeFunction + `
+local given = {}
+for i = 3, #KEYS do
+	given[ARGV[2 * i - 4]] = ARGV[2 * i - 3]
+end
+local entries = redis.call('HGETALL', KEYS[1])
+for i = 1, #entries, 2 do
+	if entries[i + 1] ~= ARGV[1] and given[entries[i]] ~= entries[i + 1] then
+		return -1
+	end
+end
+local revoked = 0
+for i = 3, #KEYS do
+	local device, family = ARGV[2 * i - 4], ARGV[2 * i - 3]
+	revoked = revoked + revoke(KEYS[i], KEYS[1], KEYS[2], family)
+	if redis.call('HGET', KEYS[1], device) == family then
+		redis.call('HDEL', KEYS[1], device)
+	end
+end
+return revoked`
+
+	// KEYS: revoked sessions key
+	// ARGV: current time
+	// Returns the family ID and access token expiry of each revoked family
+	// whose access tokens have not expired, dropping the rest.
+	revokedScript = `
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+return redis.call('ZRANGE', KEYS[1], 0, -1, 'WITHSCORES')`
+)
+
+const revokedSessionsKey = "revokedsessions"
+
+// Number of family fields returned by the scripts: user, device, app
+// version, created and last seen.
+const familyFields = 5
+
+type RedisSessionStore struct {
+	Pool *util.RedisHandler
+}
+
+func userKey(userID string) string {
+	return strings.Join([]string{"user", userID}, "_")
+}
+
+func familyKey(family string) string {
+	return strings.Join([]string{"family", family}, "_")
+}
+
+func accessKey(token string) string {
+	return strings.Join([]string{"access", token}, "_")
+}
+
+func refreshKey(token string) string {
+	return strings.Join([]string{"refresh", token}, "_")
+}
+
+func (r *RedisSessionStore) Create(userID string, device Device, accessTTL, refreshTTL time.Duration) (*Session, error) {
+	for attempt := 0; attempt < tokenAttempts; attempt++ {
+		family, err := misc.GenerateFamilyID()
+		if err != nil {
+			return nil, err
+		}
+		session, err := newSession(accessTTL, refreshTTL)
+		if err != nil {
+			return nil, err
+		}
+		session.Family, session.UserID, session.Device = family, userID, device
+		created := time.Unix(time.Now().Unix(), 0)
+		session.Created, session.LastSeen = created, created
+		old, err := r.hget(userKey(userID), device.ID)
+		if err != nil {
+			return nil, err
+		}
+		keys := []string{userKey(userID), familyKey(family), accessKey(session.AccessToken),
+			refreshKey(session.RefreshToken), revokedSessionsKey}
+		if old != "" {
+			keys = append(keys, familyKey(old))
+		}
+		reply, err := r.Pool.Eval(createScript, keys,
+			family, userID, session.RefreshToken, seconds(accessTTL), seconds(refreshTTL),
+			device.ID, device.Appversion, session.Created.Unix(), old)
+		if err != nil {
+			return nil, err
+		}
+		values, err := reply.List()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case len(values) == 1 && values[0] == "ok":
+			return session, nil
+		case len(values) != 1 || (values[0] != "taken" && values[0] != "changed"):
+			return nil, errors.New("unexpected reply from session script")
+		}
+	}
+	return nil, fmt.Errorf("no unique session tokens after %d attempts", tokenAttempts)
+}
+
+func (r *RedisSessionStore) Lookup(accessToken string) (*Session, error) {
+	family, err := r.get(accessKey(accessToken))
+	if err != nil {
+		return nil, err
+	}
+	if family == "" {
+		return nil, ErrSessionNotFound
+	}
+	reply, err := r.Pool.Eval(lookupScript, []string{accessKey(accessToken), familyKey(family)},
+		time.Now().Unix(), family)
+	if err != nil {
+		return nil, err
+	}
+	values, err := reply.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, ErrSessionNotFound
+	}
+	if len(values) != 1+familyFields {
+		return nil, errors.New("unexpected reply from session script")
+	}
+	ttl, err := strconv.Atoi(values[0])
+	if err != nil {
+		return nil, err
+	}
+	session, err := parseFamily(family, values[1:])
+	if err != nil {
+		return nil, err
+	}
+	session.AccessToken = accessToken
+	session.AccessExpires = time.Now().Add(time.Duration(ttl) * time.Second)
+	return session, nil
+}
+
+func (r *RedisSessionStore) Refresh(refreshToken string, accessTTL, refreshTTL time.Duration) (*Session, error) {
+	family, err := r.get(refreshKey(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if family == "" {
+		return nil, ErrSessionNotFound
+	}
+	userID, err := r.hget(familyKey(family), "user")
+	if err != nil {
+		return nil, err
+	}
+	if userID == "" {
+		return nil, ErrSessionNotFound
+	}
+	for attempt := 0; attempt < tokenAttempts; attempt++ {
+		tokens, err := newSession(accessTTL, refreshTTL)
+		if err != nil {
+			return nil, err
+		}
+		reply, err := r.Pool.Eval(refreshScript,
+			[]string{refreshKey(refreshToken), familyKey(family), userKey(userID),
+				accessKey(tokens.AccessToken), refreshKey(tokens.RefreshToken), revokedSessionsKey},
+			refreshToken, tokens.RefreshToken, seconds(accessTTL), seconds(refreshTTL), time.Now().Unix(),
+			family, userID)
+		if err != nil {
+			return nil, err
+		}
+		values, err := reply.List()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case len(values) == 0:
+			return nil, ErrSessionNotFound
+		case values[0] == "reused":
+			return nil, ErrRefreshTokenReused
+		case values[0] == "ok" && len(values) == 1+familyFields:
+			session, err := parseFamily(family, values[1:])
+			if err != nil {
+				return nil, err
+			}
+			session.AccessToken, session.AccessExpires = tokens.AccessToken, tokens.AccessExpires
+			session.RefreshToken, session.RefreshExpires = tokens.RefreshToken, tokens.RefreshExpires
+			return session, nil
+		case values[0] != "taken":
+			return nil, errors.New("unexpected reply from session script")
+		}
+	}
+	return nil, fmt.Errorf("no unique session tokens after %d attempts", tokenAttempts)
+}
+
+func (r *RedisSessionStore) Family(family string) (*Session, error) {
+	reply, err := r.Pool.Eval(familyScript, []string{familyKey(family)})
+	if err != nil {
+		return nil, err
+	}
+	values, err := reply.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, ErrSessionNotFound
+	}
+	if len(values) != familyFields {
+		return nil, errors.New("unexpected reply from session script")
+	}
+	return parseFamily(family, values)
+}
+
+func (r *RedisSessionStore) List(userID string) ([]*Session, error) {
+	devices, err := r.devices(userID)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{userKey(userID)}
+	args := make([]interface{}, 0, 2*len(devices))
+	for _, device := range devices {
+		keys = append(keys, familyKey(device[1]))
+		args = append(args, device[0], device[1])
+	}
+	reply, err := r.Pool.Eval(listScript, keys, args...)
+	if err != nil {
+		return nil, err
+	}
+	values, err := reply.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(values)%(1+familyFields) != 0 {
+		return nil, errors.New("unexpected reply from session script")
+	}
+	sessions := make([]*Session, 0, len(values)/(1+familyFields))
+	for i := 0; i < len(values); i += 1 + familyFields {
+		session, err := parseFamily(values[i], values[i+1:i+1+familyFields])
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	sort.Sort(byLastSeen(sessions))
+	return sessions, nil
+}
+
+func (r *RedisSessionStore) RevokeFamily(userID string, family string) error {
+	reply, err := r.Pool.Eval(revokeFamilyScript,
+		[]string{familyKey(family), userKey(userID), revokedSessionsKey}, userID, family)
+	if err != nil {
+		return err
+	}
+	revoked, err := reply.Int()
+	if err != nil {
+		return err
+	}
+	if revoked == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (r *RedisSessionStore) RevokeOthers(userID string, keep string) (int, error) {
+	for attempt := 0; attempt < tokenAttempts; attempt++ {
+		devices, err := r.devices(userID)
+		if err != nil {
+			return 0, err
+		}
+		keys := []string{userKey(userID), revokedSessionsKey}
+		args := []interface{}{keep}
+		for _, device := range devices {
+			if device[1] == keep {
+				continue
+			}
+			keys = append(keys, familyKey(device[1]))
+			args = append(args, device[0], device[1])
+		}
+		reply, err := r.Pool.Eval(revokeOthersScript, keys, args...)
+		if err != nil {
+			return 0, err
+		}
+		revoked, err := reply.Int()
+		if err != nil || revoked >= 0 {
+			return revoked, err
+		}
+	}
+	return 0, fmt.Errorf("sessions of user %s kept changing after %d attempts", userID, tokenAttempts)
+}
+
+func (r *RedisSessionStore) Revoked() ([]RevokedSession, error) {
+	reply, err := r.Pool.Eval(revokedScript, []string{revokedSessionsKey}, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	values, err := reply.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(values)%2 != 0 {
+		return nil, errors.New("unexpected reply from session script")
+	}
+	revoked := make([]RevokedSession, 0, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		expires, err := strconv.ParseInt(values[i+1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		revoked = append(revoked, RevokedSession{Family: values[i], Expires: time.Unix(expires, 0)})
+	}
+	return revoked, nil
+}
+
+// get returns the value of key, or "" if it does not exist.
+func (r *RedisSessionStore) get(key string) (string, error) {
+	reply, err := r.Pool.Get(key)
+	if err != nil {
+		return "", err
+	}
+	return optionalString(reply)
+}
+
+// hget returns the value of field in the hash at key, or "" if it does not
+// exist.
+func (r *RedisSessionStore) hget(key string, field string) (string, error) {
+	reply, err := r.Pool.HGet(key, field)
+	if err != nil {
+		return "", err
+	}
+	return optionalString(reply)
+}
+
+// devices returns the device ID and family ID pairs of the user's families,
+// sorted by device ID.
+func (r *RedisSessionStore) devices(userID string) ([][2]string, error) {
+	reply, err := r.Pool.HGetAll(userKey(userID))
+	if err != nil {
+		return nil, err
+	}
+	families, err := reply.Hash()
+	if err != nil {
+		return nil, err
+	}
+	devices := make([][2]string, 0, len(families))
+	for device, family := range families {
+		devices = append(devices, [2]string{device, family})
+	}
+	sort.Sort(byDevice(devices))
+	return devices, nil
+}
+
+type byDevice [][2]string
+
+func (d byDevice) Len() int           { return len(d) }
+func (d byDevice) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+func (d byDevice) Less(i, j int) bool { return d[i][0] < d[j][0] }
+
+func optionalString(reply *redis.Reply) (string, error) {
+	if reply.Type == redis.NilReply {
+		return "", nil
+	}
+	return reply.Str()
+}
+
+// newSession generates a candidate access and refresh token pair.
+func newSession(accessTTL, refreshTTL time.Duration) (*Session, error) {
+	accessToken, err := misc.GenerateSessionKey()
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := misc.GenerateSessionKey()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &Session{
+		AccessToken:    accessToken,
+		AccessExpires:  now.Add(accessTTL),
+		RefreshToken:   refreshToken,
+		RefreshExpires: now.Add(refreshTTL),
+	}, nil
+}
+
+// parseFamily reads the user, device, app version, created and last seen
+// fields returned by the scripts.
+func parseFamily(family string, fields []string) (*Session, error) {
+	created, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	lastSeen, err := strconv.ParseInt(fields[4], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		Family:   family,
+		UserID:   fields[0],
+		Device:   Device{ID: fields[1], Appversion: fields[2]},
+		Created:  time.Unix(created, 0),
+		LastSeen: time.Unix(lastSeen, 0),
+	}, nil
+}
+
+func seconds(ttl time.Duration) int {
+	return int(ttl / time.Second)
+}
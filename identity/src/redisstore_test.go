@@ -0,0 +1,148 @@
+/*
+// ----------------------------------------------------------------------------
+// redisstore_test.go
+// Countertop Identity Microservice Redis Session Store Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package identity_test
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/theorangechefco/cts/go-shared-libs/cts/logger"
+	"github.com/theorangechefco/cts/go-shared-libs/cts/util"
+	identity "github.com/theorangechefco/cts/identity"
+)
+
+var (
+	redisHost = flag.String("redis_host", "", "Hostname of a Redis server to run the session store tests against; skipped if empty")
+	redisPass = flag.String("redis_pass", "abc", "Password of Redis server")
+)
+
+// redisStore returns a session store backed by an emptied Redis database, or
+// nil if no Redis server was given.
+func redisStore(t *testing.T) identity.SessionStore {
+	if *redisHost == "" {
+		return nil
+	}
+	testLogger := logger.NewLogger("test", "", 8080, false, logrus.ErrorLevel)
+	client, err := util.NewClient(testLogger, *redisHost, *redisPass)
+	if err != nil {
+		t.Fatalf("NewClient(%s) = _, %v", *redisHost, err)
+	}
+	defer client.Close()
+	if err := util.BlowAwayRedis(client); err != nil {
+		t.Fatal(err)
+	}
+	pool, err := util.NewPool(testLogger, *redisHost, *redisPass, 2, 3, 0)
+	if err != nil || pool == nil {
+		t.Fatalf("NewPool(%s) = %v, %v", *redisHost, pool, err)
+	}
+	return &identity.RedisSessionStore{Pool: pool}
+}
+
+// forEachStore runs test against the memory store and, if a Redis server was
+// given with -redis_host, the Redis store.
+func forEachStore(t *testing.T, test func(*testing.T, identity.SessionStore)) {
+	stores := []identity.SessionStore{identity.NewMemorySessionStore()}
+	if store := redisStore(t); store != nil {
+		stores = append(stores, store)
+	}
+	for _, store := range stores {
+		t.Logf("testing %T", store)
+		test(t, store)
+	}
+}
+
+func TestSessionStoreRefreshReuseRevokesFamily(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store identity.SessionStore) {
+		first, err := store.Create(testUUID, phone, time.Minute, time.Hour)
+		if err != nil {
+			t.Fatalf("Create(_) = _, %v", err)
+		}
+		second, err := store.Refresh(first.RefreshToken, time.Minute, time.Hour)
+		if err != nil {
+			t.Fatalf("Refresh(_) = _, %v", err)
+		}
+		if second.Family != first.Family || second.UserID != testUUID || second.Device.ID != phone.ID {
+			t.Errorf("Refresh(_) = %+v, want family %s of %s on %s", second, first.Family, testUUID, phone.ID)
+		}
+
+		if _, err := store.Refresh(first.RefreshToken, time.Minute, time.Hour); err != identity.ErrRefreshTokenReused {
+			t.Errorf("Refresh(reused) = _, %v, want %v", err, identity.ErrRefreshTokenReused)
+		}
+		for _, token := range []string{first.AccessToken, second.AccessToken} {
+			if _, err := store.Lookup(token); err != identity.ErrSessionNotFound {
+				t.Errorf("Lookup(%s) = _, %v after reuse, want %v", token, err, identity.ErrSessionNotFound)
+			}
+		}
+		if _, err := store.Refresh(second.RefreshToken, time.Minute, time.Hour); err != identity.ErrSessionNotFound {
+			t.Errorf("Refresh(latest) = _, %v after reuse, want %v", err, identity.ErrSessionNotFound)
+		}
+		if _, err := store.Family(first.Family); err != identity.ErrSessionNotFound {
+			t.Errorf("Family(_) = _, %v after reuse, want %v", err, identity.ErrSessionNotFound)
+		}
+		if sessions, err := store.List(testUUID); err != nil || len(sessions) != 0 {
+			t.Errorf("List(_) = %v, %v after reuse, want none", sessions, err)
+		}
+
+		revoked, err := store.Revoked()
+		if err != nil {
+			t.Fatalf("Revoked() = _, %v", err)
+		}
+		found := false
+		for _, session := range revoked {
+			found = found || session.Family == first.Family
+		}
+		if !found {
+			t.Errorf("Revoked() = %v, want family %s", revoked, first.Family)
+		}
+	})
+}
+
+func TestSessionStoreCreateReplacesDeviceFamily(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store identity.SessionStore) {
+		first, err := store.Create(testUUID, phone, time.Minute, time.Hour)
+		if err != nil {
+			t.Fatalf("Create(_) = _, %v", err)
+		}
+		tabletSession, err := store.Create(testUUID, tablet, time.Minute, time.Hour)
+		if err != nil {
+			t.Fatalf("Create(tablet) = _, %v", err)
+		}
+		second, err := store.Create(testUUID, phone, time.Minute, time.Hour)
+		if err != nil {
+			t.Fatalf("Create(_) = _, %v", err)
+		}
+		if _, err := store.Lookup(first.AccessToken); err != identity.ErrSessionNotFound {
+			t.Errorf("Lookup(replaced) = _, %v, want %v", err, identity.ErrSessionNotFound)
+		}
+		for _, session := range []*identity.Session{second, tabletSession} {
+			if found, err := store.Lookup(session.AccessToken); err != nil || found.Family != session.Family {
+				t.Errorf("Lookup(%s) = %v, %v, want family %s", session.Device.ID, found, err, session.Family)
+			}
+		}
+		if sessions, err := store.List(testUUID); err != nil || len(sessions) != 2 {
+			t.Errorf("List(_) = %v, %v, want 2 sessions", sessions, err)
+		}
+
+		if revoked, err := store.RevokeOthers(testUUID, second.Family); err != nil || revoked != 1 {
+			t.Errorf("RevokeOthers(_) = %d, %v, want 1", revoked, err)
+		}
+		if err := store.RevokeFamily("a0d3c6d4-0d5e-4bde-9d37-5b7e8c9a2f10", second.Family); err != identity.ErrSessionNotFound {
+			t.Errorf("RevokeFamily(other user) = %v, want %v", err, identity.ErrSessionNotFound)
+		}
+		if err := store.RevokeFamily(testUUID, second.Family); err != nil {
+			t.Errorf("RevokeFamily(_) = %v", err)
+		}
+		if sessions, err := store.List(testUUID); err != nil || len(sessions) != 0 {
+			t.Errorf("List(_) = %v, %v after revoking, want none", sessions, err)
+		}
+	})
+}
@@ -0,0 +1,362 @@
+/*
+// ----------------------------------------------------------------------------
+// sessionstore.go
+// Countertop Identity Microservice Session Stores
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package identity
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	misc "github.com/theorangechefco/cts/identity/misc"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrRefreshTokenReused is returned when a refresh token is presented a
+	// second time. The token family it belongs to has been revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+// Device identifies the app install a session was created for.
+type Device struct {
+	ID         string
+	Appversion string
+}
+
+// Session is one token family: the tokens issued from a single login. Each
+// refresh rotates the refresh token and issues a new access token. Only the
+// latest refresh token can be exchanged; presenting an older one revokes the
+// family, since it means the token was copied.
+type Session struct {
+	Family   string
+	UserID   string
+	Device   Device
+	Created  time.Time
+	LastSeen time.Time
+	// Only set by Create, Lookup and Refresh
+	AccessToken   string
+	AccessExpires time.Time
+	// Only set when tokens are issued
+	RefreshToken   string
+	RefreshExpires time.Time
+}
+
+// RevokedSession is a token family that was revoked before the access
+// tokens issued for it expired. Services that verify signed access tokens
+// locally must reject tokens of the family until Expires.
+type RevokedSession struct {
+	Family  string
+	Expires time.Time
+}
+
+// SessionStore keeps one token family per user and device.
+type SessionStore interface {
+	// Create starts a new token family for the user on the device, revoking
+	// the one the device had before.
+	Create(userID string, device Device, accessTTL, refreshTTL time.Duration) (*Session, error)
+	// Lookup returns the live session for an access token and marks it as
+	// seen, or returns ErrSessionNotFound.
+	Lookup(accessToken string) (*Session, error)
+	// Family returns a live token family, or ErrSessionNotFound. Access
+	// tokens are not set.
+	Family(family string) (*Session, error)
+	// Refresh exchanges the family's current refresh token for a new access
+	// and refresh token pair. Returns ErrSessionNotFound for unknown or
+	// expired tokens, and ErrRefreshTokenReused for refresh tokens that were
+	// already exchanged.
+	Refresh(refreshToken string, accessTTL, refreshTTL time.Duration) (*Session, error)
+	// List returns the user's live sessions, most recently seen first.
+	List(userID string) ([]*Session, error)
+	// RevokeFamily ends one of the user's sessions, or returns
+	// ErrSessionNotFound if the user has no such session.
+	RevokeFamily(userID string, family string) error
+	// RevokeOthers ends every session of the user except the one kept,
+	// returning how many were ended.
+	RevokeOthers(userID string, keep string) (int, error)
+	// Revoked lists the revoked families whose access tokens have not
+	// expired yet.
+	Revoked() ([]RevokedSession, error)
+}
+
+//
+// In-process session store, for tests and single node development setups
+//
+
+type MemorySessionStore struct {
+	// Now returns the current time, and can be replaced in tests.
+	Now func() time.Time
+
+	mu       sync.Mutex
+	families map[string]*tokenFamily
+	access   map[string]issuedToken
+	// Refresh tokens are kept after they are exchanged, until they expire,
+	// so that reuse can be detected.
+	refresh map[string]issuedToken
+	// Maps user IDs to device IDs to their token family
+	users map[string]map[string]string
+	// Maps revoked families to when their last access token expires
+	revoked map[string]time.Time
+}
+
+type tokenFamily struct {
+	userID   string
+	device   Device
+	created  time.Time
+	lastSeen time.Time
+	// The only refresh token that can still be exchanged
+	refresh string
+	expires time.Time
+	// When the last access token issued expires
+	accessExpires time.Time
+}
+
+func (f *tokenFamily) session(id string) *Session {
+	return &Session{Family: id, UserID: f.userID, Device: f.device, Created: f.created, LastSeen: f.lastSeen}
+}
+
+type issuedToken struct {
+	family  string
+	expires time.Time
+}
+
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		Now:      time.Now,
+		families: make(map[string]*tokenFamily),
+		access:   make(map[string]issuedToken),
+		refresh:  make(map[string]issuedToken),
+		users:    make(map[string]map[string]string),
+		revoked:  make(map[string]time.Time),
+	}
+}
+
+func (m *MemorySessionStore) Create(userID string, device Device, accessTTL, refreshTTL time.Duration) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.Now()
+	m.sweep(now)
+
+	if family, ok := m.users[userID][device.ID]; ok {
+		m.revokeFamily(family)
+	}
+
+	family, err := misc.GenerateFamilyID()
+	for err == nil {
+		if _, taken := m.families[family]; !taken {
+			break
+		}
+		family, err = misc.GenerateFamilyID()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m.families[family] = &tokenFamily{userID: userID, device: device, created: now, lastSeen: now}
+	if m.users[userID] == nil {
+		m.users[userID] = make(map[string]string)
+	}
+	m.users[userID][device.ID] = family
+	return m.issue(family, now, accessTTL, refreshTTL)
+}
+
+func (m *MemorySessionStore) Lookup(accessToken string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.Now()
+	token, ok := m.access[accessToken]
+	if !ok || !now.Before(token.expires) {
+		return nil, ErrSessionNotFound
+	}
+	family := m.liveFamily(token.family, now)
+	if family == nil {
+		return nil, ErrSessionNotFound
+	}
+	family.lastSeen = now
+	session := family.session(token.family)
+	session.AccessToken, session.AccessExpires = accessToken, token.expires
+	return session, nil
+}
+
+func (m *MemorySessionStore) Refresh(refreshToken string, accessTTL, refreshTTL time.Duration) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.Now()
+	m.sweep(now)
+
+	token, ok := m.refresh[refreshToken]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	family := m.liveFamily(token.family, now)
+	if family == nil {
+		return nil, ErrSessionNotFound
+	}
+	if family.refresh != refreshToken {
+		m.revokeFamily(token.family)
+		return nil, ErrRefreshTokenReused
+	}
+	family.lastSeen = now
+	return m.issue(token.family, now, accessTTL, refreshTTL)
+}
+
+func (m *MemorySessionStore) Family(id string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	family := m.liveFamily(id, m.Now())
+	if family == nil {
+		return nil, ErrSessionNotFound
+	}
+	return family.session(id), nil
+}
+
+func (m *MemorySessionStore) List(userID string) ([]*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(m.Now())
+	sessions := make([]*Session, 0, len(m.users[userID]))
+	for _, id := range m.users[userID] {
+		sessions = append(sessions, m.families[id].session(id))
+	}
+	sort.Sort(byLastSeen(sessions))
+	return sessions, nil
+}
+
+func (m *MemorySessionStore) RevokeFamily(userID string, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	family := m.liveFamily(id, m.Now())
+	if family == nil || family.userID != userID {
+		return ErrSessionNotFound
+	}
+	m.revokeFamily(id)
+	return nil
+}
+
+func (m *MemorySessionStore) RevokeOthers(userID string, keep string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(m.Now())
+	revoked := 0
+	for _, id := range m.users[userID] {
+		if id != keep {
+			m.revokeFamily(id)
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (m *MemorySessionStore) Revoked() ([]RevokedSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(m.Now())
+	revoked := make([]RevokedSession, 0, len(m.revoked))
+	for family, expires := range m.revoked {
+		revoked = append(revoked, RevokedSession{Family: family, Expires: expires})
+	}
+	return revoked, nil
+}
+
+// issue adds a new access and refresh token pair to the family, and extends
+// the family to expire with the refresh token. Callers must hold m.mu.
+func (m *MemorySessionStore) issue(family string, now time.Time, accessTTL, refreshTTL time.Duration) (*Session, error) {
+	accessToken, err := newToken(m.access)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := newToken(m.refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	f := m.families[family]
+	f.refresh, f.expires, f.accessExpires = refreshToken, now.Add(refreshTTL), now.Add(accessTTL)
+	m.access[accessToken] = issuedToken{family: family, expires: now.Add(accessTTL)}
+	m.refresh[refreshToken] = issuedToken{family: family, expires: f.expires}
+	session := f.session(family)
+	session.AccessToken, session.AccessExpires = accessToken, now.Add(accessTTL)
+	session.RefreshToken, session.RefreshExpires = refreshToken, f.expires
+	return session, nil
+}
+
+func newToken(taken map[string]issuedToken) (string, error) {
+	token, err := misc.GenerateSessionKey()
+	for err == nil {
+		if _, ok := taken[token]; !ok {
+			break
+		}
+		token, err = misc.GenerateSessionKey()
+	}
+	return token, err
+}
+
+// Callers must hold m.mu.
+func (m *MemorySessionStore) liveFamily(id string, now time.Time) *tokenFamily {
+	family, ok := m.families[id]
+	if !ok || !now.Before(family.expires) {
+		return nil
+	}
+	return family
+}
+
+// revokeFamily drops the family. Its tokens stop working at once and are
+// dropped by the next sweep. The family stays on the revocation list until
+// its last access token expires. Callers must hold m.mu.
+func (m *MemorySessionStore) revokeFamily(id string) {
+	if family, ok := m.families[id]; ok {
+		m.revoked[id] = family.accessExpires
+		devices := m.users[family.userID]
+		if devices[family.device.ID] == id {
+			delete(devices, family.device.ID)
+		}
+		if len(devices) == 0 {
+			delete(m.users, family.userID)
+		}
+		delete(m.families, id)
+	}
+}
+
+// sweep drops expired families and tokens, and tokens of revoked families.
+// Callers must hold m.mu.
+func (m *MemorySessionStore) sweep(now time.Time) {
+	for id, family := range m.families {
+		if !now.Before(family.expires) {
+			m.revokeFamily(id)
+		}
+	}
+	for _, tokens := range []map[string]issuedToken{m.access, m.refresh} {
+		for token, issued := range tokens {
+			if _, ok := m.families[issued.family]; !ok || !now.Before(issued.expires) {
+				delete(tokens, token)
+			}
+		}
+	}
+	for family, expires := range m.revoked {
+		if !now.Before(expires) {
+			delete(m.revoked, family)
+		}
+	}
+}
+
+// byLastSeen orders sessions from the most recently seen.
+type byLastSeen []*Session
+
+func (s byLastSeen) Len() int           { return len(s) }
+func (s byLastSeen) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byLastSeen) Less(i, j int) bool { return s[i].LastSeen.After(s[j].LastSeen) }
@@ -0,0 +1,457 @@
+/*
+// ----------------------------------------------------------------------------
+// accounts.go
+// Countertop Profile Microservice Account Credentials
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package profile
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	pb "github.com/theorangechefco/cts/go-protos"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// Register adds an email address and password to the anonymous profile the
+// caller is signed in to, or to a new profile.
+func (s *Server) Register(ctx context.Context, request *pb.RegisterRequest) (*pb.UserId, error) {
+	if request.Profile == nil || request.Profile.Identifier == nil || request.Profile.Identifier.Deviceidentifier == "" {
+		errorMsg := fmt.Sprintf("Profile with a device identifier not specified.")
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "Register"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.InvalidArgument, errorMsg)
+	}
+	deviceID := request.Profile.Identifier.Deviceidentifier
+
+	email, err := NormalizeEmail(request.Email)
+	if err == nil {
+		err = CheckPasswordPolicy(request.Password)
+	}
+	if err != nil {
+		errorMsg := fmt.Sprintf("Cannot register device %s: %v", deviceID, err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "Register"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.InvalidArgument, errorMsg)
+	}
+
+	passwordHash, err := HashPassword(request.Password)
+	if err != nil {
+		errorMsg := fmt.Sprintf("Cannot hash password: %v", err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "hash",
+			"tag":   "credentials",
+			"rpc":   "Register"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.Internal, errorMsg)
+	}
+
+	user, created, err := s.accountProfile(request.Currentuser, request.Profile)
+	if err != nil {
+		errorMsg := fmt.Sprintf("Database query failed: %v", err)
+		code := codes.Unknown
+		switch err {
+		case ErrProfileNotFound:
+			errorMsg = fmt.Sprintf("Profile with ID %s not found.", request.Currentuser.Uuid)
+			code = codes.NotFound
+		case errAccountExists:
+			errorMsg = fmt.Sprintf("Profile with ID %s already has an account.", request.Currentuser.Uuid)
+			code = codes.AlreadyExists
+		}
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "database",
+			"rpc":   "Register"},
+			errorMsg)
+		return nil, grpc.Errorf(code, errorMsg)
+	}
+
+	// The credential goes first, so that a taken email address leaves no
+	// new profile behind
+	credential := &Credential{UUID: user.UUID, Email: email, PasswordHash: passwordHash}
+	if err := s.Repository.CreateCredential(credential); err != nil {
+		errorMsg := fmt.Sprintf("Could not add credential for device %s. Error: %v", deviceID, err)
+		code := codes.Unknown
+		switch err {
+		case ErrDuplicateEmail:
+			errorMsg = fmt.Sprintf("Email address %s is already registered.", email)
+			code = codes.AlreadyExists
+		case ErrDuplicateProfile:
+			errorMsg = fmt.Sprintf("Profile with ID %s already has an account.", user.UUID)
+			code = codes.AlreadyExists
+		}
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "createrecord",
+			"tag":   "database",
+			"rpc":   "Register"},
+			errorMsg)
+		return nil, grpc.Errorf(code, errorMsg)
+	}
+	if created {
+		if err := s.createAccountUser(user); err != nil {
+			s.Repository.DeleteCredential(credential)
+			errorMsg := fmt.Sprintf("Could not add record for profile with ID: %v. Error: %v", request.Profile.Identifier, err)
+			code := codes.Unknown
+			if err == ErrDuplicateProfile {
+				code = codes.AlreadyExists
+			}
+			s.Logger.Error(logrus.Fields{
+				"phase": "process",
+				"event": "createrecord",
+				"tag":   "database",
+				"rpc":   "Register"},
+				errorMsg)
+			return nil, grpc.Errorf(code, errorMsg)
+		}
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "createrecord",
+		"tag":   "credentials",
+		"rpc":   "Register"},
+		fmt.Sprintf("Registered %s for user with UUID %s", email, user.UUID))
+
+	return &pb.UserId{Uuid: user.UUID}, nil
+}
+
+// VerifyCredentials returns the user an email address and password belong
+// to. Unknown emails and wrong passwords are not told apart.
+func (s *Server) VerifyCredentials(ctx context.Context, request *pb.LoginRequest) (*pb.UserId, error) {
+	var credential *Credential
+	email, err := NormalizeEmail(request.Email)
+	if err == nil {
+		credential, err = s.Repository.FindCredentialByEmail(email)
+	}
+	if err != nil && err != ErrInvalidEmail && err != ErrCredentialNotFound {
+		errorMsg := fmt.Sprintf("Database query failed: %v", err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "database",
+			"rpc":   "VerifyCredentials"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.Unknown, errorMsg)
+	}
+
+	passwordHash := unusedPasswordHash
+	if credential != nil {
+		passwordHash = credential.PasswordHash
+	}
+	if !CheckPassword(passwordHash, request.Password) || credential == nil {
+		s.Logger.Warn(logrus.Fields{
+			"phase": "process",
+			"event": "verify",
+			"tag":   "credentials",
+			"rpc":   "VerifyCredentials"},
+			fmt.Sprintf("Failed sign in for %q", request.Email))
+		return nil, grpc.Errorf(codes.Unauthenticated, "Invalid email or password.")
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "verify",
+		"tag":   "credentials",
+		"rpc":   "VerifyCredentials"},
+		fmt.Sprintf("Verified credentials of user with UUID %s", credential.UUID))
+
+	return &pb.UserId{Uuid: credential.UUID}, nil
+}
+
+// ChangePassword replaces the user's password, given the current one.
+func (s *Server) ChangePassword(ctx context.Context, request *pb.ChangePasswordRequest) (*pb.Response, error) {
+	if request.Id == nil || request.Id.Uuid == "" {
+		errorMsg := fmt.Sprintf("Identifier not specified.")
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "ChangePassword"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.InvalidArgument, errorMsg)
+	}
+	if err := CheckPasswordPolicy(request.Newpassword); err != nil {
+		errorMsg := fmt.Sprintf("Cannot change password of user with UUID %s: %v", request.Id.Uuid, err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "ChangePassword"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.InvalidArgument, errorMsg)
+	}
+
+	credential, err := s.Repository.FindCredentialByUUID(request.Id.Uuid)
+	if err != nil {
+		errorMsg := fmt.Sprintf("Database query failed: %v", err)
+		code := codes.Unknown
+		if err == ErrCredentialNotFound {
+			errorMsg = fmt.Sprintf("User with UUID %s has not registered an email address.", request.Id.Uuid)
+			code = codes.FailedPrecondition
+		}
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "database",
+			"rpc":   "ChangePassword"},
+			errorMsg)
+		return nil, grpc.Errorf(code, errorMsg)
+	}
+	if !CheckPassword(credential.PasswordHash, request.Currentpassword) {
+		s.Logger.Warn(logrus.Fields{
+			"phase": "process",
+			"event": "verify",
+			"tag":   "credentials",
+			"rpc":   "ChangePassword"},
+			fmt.Sprintf("Wrong current password for user with UUID %s", credential.UUID))
+		return nil, grpc.Errorf(codes.PermissionDenied, "Current password is incorrect.")
+	}
+
+	if err := s.setPassword(credential, request.Newpassword); err != nil {
+		errorMsg := fmt.Sprintf("Could not update password of user with UUID %s. Error: %v", credential.UUID, err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "update",
+			"tag":   "database",
+			"rpc":   "ChangePassword"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.Unknown, errorMsg)
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "update",
+		"tag":   "credentials",
+		"rpc":   "ChangePassword"},
+		fmt.Sprintf("Changed password of user with UUID %s", credential.UUID))
+
+	return &pb.Response{Success: true}, nil
+}
+
+// RequestPasswordReset mails a reset code to the email address if it is
+// registered. The response is the same either way.
+func (s *Server) RequestPasswordReset(ctx context.Context, request *pb.PasswordResetRequest) (*pb.Response, error) {
+	email, err := NormalizeEmail(request.Email)
+	if err != nil {
+		errorMsg := fmt.Sprintf("Cannot reset password for %q: %v", request.Email, err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "RequestPasswordReset"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.InvalidArgument, errorMsg)
+	}
+
+	credential, err := s.Repository.FindCredentialByEmail(email)
+	if err == ErrCredentialNotFound {
+		s.Logger.Info(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "credentials",
+			"rpc":   "RequestPasswordReset"},
+			fmt.Sprintf("Not sending password reset code to unregistered %s", email))
+		return &pb.Response{Success: true}, nil
+	}
+	if err != nil {
+		errorMsg := fmt.Sprintf("Database query failed: %v", err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "database",
+			"rpc":   "RequestPasswordReset"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.Unknown, errorMsg)
+	}
+
+	code, codeHash, err := generateResetCode()
+	if err == nil {
+		credential.ResetCodeHash = codeHash
+		credential.ResetCodeExpires = time.Now().Add(s.ResetCodeTTL)
+		err = s.Repository.UpdateCredential(credential)
+	}
+	if err != nil {
+		errorMsg := fmt.Sprintf("Could not store password reset code for user with UUID %s. Error: %v", credential.UUID, err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "update",
+			"tag":   "database",
+			"rpc":   "RequestPasswordReset"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.Unknown, errorMsg)
+	}
+
+	message := &MailMessage{
+		To:      credential.Email,
+		Subject: "Reset your Countertop password",
+		Body: fmt.Sprintf("Enter this code in the Countertop app to choose a new password:\r\n\r\n    %s\r\n\r\n"+
+			"The code expires in %d minutes. If you did not ask to reset your password, you can ignore this message.",
+			code, int(s.ResetCodeTTL.Minutes())),
+	}
+	if err := s.Mail.Send(message); err != nil {
+		errorMsg := fmt.Sprintf("Could not mail password reset code to user with UUID %s. Error: %v", credential.UUID, err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "send",
+			"tag":   "mail",
+			"rpc":   "RequestPasswordReset"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.Unavailable, "Could not send password reset code.")
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "send",
+		"tag":   "mail",
+		"rpc":   "RequestPasswordReset"},
+		fmt.Sprintf("Sent password reset code to user with UUID %s", credential.UUID))
+
+	return &pb.Response{Success: true}, nil
+}
+
+// ResetPassword replaces the password of the user a reset code was mailed
+// to. Each code can be used once.
+func (s *Server) ResetPassword(ctx context.Context, request *pb.ResetPasswordRequest) (*pb.UserId, error) {
+	email, err := NormalizeEmail(request.Email)
+	if err == nil {
+		err = CheckPasswordPolicy(request.Newpassword)
+	}
+	if err != nil {
+		errorMsg := fmt.Sprintf("Cannot reset password for %q: %v", request.Email, err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "ResetPassword"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.InvalidArgument, errorMsg)
+	}
+
+	credential, err := s.Repository.FindCredentialByEmail(email)
+	if err != nil && err != ErrCredentialNotFound {
+		errorMsg := fmt.Sprintf("Database query failed: %v", err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "database",
+			"rpc":   "ResetPassword"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.Unknown, errorMsg)
+	}
+	if credential == nil || credential.ResetCodeHash == "" || !time.Now().Before(credential.ResetCodeExpires) ||
+		subtle.ConstantTimeCompare([]byte(credential.ResetCodeHash), []byte(hashResetCode(request.Code))) != 1 {
+		s.Logger.Warn(logrus.Fields{
+			"phase": "process",
+			"event": "verify",
+			"tag":   "credentials",
+			"rpc":   "ResetPassword"},
+			fmt.Sprintf("Invalid or expired password reset code for %s", email))
+		return nil, grpc.Errorf(codes.PermissionDenied, "Invalid or expired password reset code.")
+	}
+
+	if err := s.setPassword(credential, request.Newpassword); err != nil {
+		errorMsg := fmt.Sprintf("Could not update password of user with UUID %s. Error: %v", credential.UUID, err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "update",
+			"tag":   "database",
+			"rpc":   "ResetPassword"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.Unknown, errorMsg)
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "update",
+		"tag":   "credentials",
+		"rpc":   "ResetPassword"},
+		fmt.Sprintf("Reset password of user with UUID %s", credential.UUID))
+
+	return &pb.UserId{Uuid: credential.UUID}, nil
+}
+
+// setPassword stores a new password hash, and drops any outstanding reset
+// code.
+func (s *Server) setPassword(credential *Credential, password string) error {
+	passwordHash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	credential.PasswordHash = passwordHash
+	credential.ResetCodeHash = ""
+	credential.ResetCodeExpires = time.Time{}
+	return s.Repository.UpdateCredential(credential)
+}
+
+// errAccountExists is returned by accountProfile when the caller's profile
+// already has an account.
+var errAccountExists = errors.New("profile already has an account")
+
+// accountProfile returns the profile a new account is attached to. That is
+// the profile of the current user, whom the endpoint authenticated, if they
+// have no account yet. Otherwise it is a new profile, which is not stored
+// yet. Profiles are never picked by device identifier, which callers could
+// make up.
+func (s *Server) accountProfile(currentUser *pb.UserId, profile *pb.Profile) (*User, bool, error) {
+	if currentUser == nil || currentUser.Uuid == "" {
+		user := newUser(profile)
+		return &user, true, nil
+	}
+	user, err := s.Repository.FindByUUID(currentUser.Uuid)
+	if err != nil {
+		return nil, false, err
+	}
+	required, err := s.requiresSignIn(user.UUID)
+	if err != nil {
+		return nil, false, err
+	}
+	if required {
+		return nil, false, errAccountExists
+	}
+	return user, false, nil
+}
+
+// createAccountUser stores a new profile made by accountProfile. If the
+// device's identifier is taken by the anonymous profile the device had
+// before, the new profile goes without one.
+func (s *Server) createAccountUser(user *User) error {
+	err := s.Repository.CreateUser(user)
+	if err == ErrDuplicateProfile && user.DeviceId != "" {
+		user.DeviceId = ""
+		err = s.Repository.CreateUser(user)
+	}
+	return err
+}
+
+// requiresSignIn reports whether the user has an email address and password
+// or an identity provider account, and so cannot be signed in by device
+// identifier alone.
+func (s *Server) requiresSignIn(uuid string) (bool, error) {
+	if _, err := s.Repository.FindCredentialByUUID(uuid); err != ErrCredentialNotFound {
+		return err == nil, err
+	}
+	if _, err := s.Repository.FindFederatedIdentityByUUID(uuid); err != ErrFederatedIdentityNotFound {
+		return err == nil, err
+	}
+	return false, nil
+}
@@ -0,0 +1,295 @@
+/*
+// ----------------------------------------------------------------------------
+// accounts_test.go
+// Countertop Profile Microservice Account Credential Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package profile_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+	profile "github.com/theorangechefco/cts/profile"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// recordingMailSender keeps the messages sent instead of delivering them.
+type recordingMailSender struct {
+	sent []*profile.MailMessage
+}
+
+func (r *recordingMailSender) Send(message *profile.MailMessage) error {
+	r.sent = append(r.sent, message)
+	return nil
+}
+
+// resetCode returns the code from the last password reset message sent.
+func resetCode(t *testing.T, server *profile.Server) string {
+	sent := server.Mail.(*recordingMailSender).sent
+	if len(sent) == 0 {
+		t.Fatalf("no password reset message sent")
+	}
+	for _, field := range strings.Fields(sent[len(sent)-1].Body) {
+		if len(field) == 16 && strings.ToUpper(field) == field {
+			return field
+		}
+	}
+	t.Fatalf("no reset code in message %q", sent[len(sent)-1].Body)
+	return ""
+}
+
+func registerRequest(deviceID string, email string) *pb.RegisterRequest {
+	testprofile := testProfile()
+	testprofile.Identifier = &pb.Identifier{Deviceidentifier: deviceID}
+	return &pb.RegisterRequest{Email: email, Password: "correct horse", Profile: testprofile}
+}
+
+func TestRegisterAndVerifyCredentials(t *testing.T) {
+	server := newTestServer()
+	ctx := context.Background()
+
+	userID, err := server.Register(ctx, registerRequest("abc123", "John@Example.com"))
+	if err != nil {
+		t.Fatalf("Register(_) = _, %v", err)
+	}
+	if _, err := server.GetProfileInfoByUUID(ctx, userID); err != nil {
+		t.Errorf("GetProfileInfoByUUID(%s) = _, %v, want the registered profile", userID.Uuid, err)
+	}
+	if _, err := server.GetUUID(ctx, &pb.Identifier{Deviceidentifier: "abc123"}); grpc.Code(err) != codes.PermissionDenied {
+		t.Errorf("GetUUID(registered device) = _, %v, want %v", err, codes.PermissionDenied)
+	}
+
+	verified, err := server.VerifyCredentials(ctx, &pb.LoginRequest{Email: " john@example.COM", Password: "correct horse"})
+	if err != nil || verified.Uuid != userID.Uuid {
+		t.Errorf("VerifyCredentials(_) = %v, %v, want %s", verified, err, userID.Uuid)
+	}
+	for _, login := range []*pb.LoginRequest{
+		{Email: "john@example.com", Password: "wrong horse"},
+		{Email: "jane@example.com", Password: "correct horse"},
+		{Email: "not an email", Password: "correct horse"},
+	} {
+		if _, err := server.VerifyCredentials(ctx, login); grpc.Code(err) != codes.Unauthenticated {
+			t.Errorf("VerifyCredentials(%v) = _, %v, want %v", login, err, codes.Unauthenticated)
+		}
+	}
+
+	// A taken email leaves no profile behind on the other device
+	if _, err := server.Register(ctx, registerRequest("def456", "john@example.com")); grpc.Code(err) != codes.AlreadyExists {
+		t.Errorf("Register(taken email) = _, %v, want %v", err, codes.AlreadyExists)
+	}
+	if _, err := server.GetUUID(ctx, &pb.Identifier{Deviceidentifier: "def456"}); grpc.Code(err) != codes.NotFound {
+		t.Errorf("GetUUID(def456) = _, %v, want %v", err, codes.NotFound)
+	}
+}
+
+func TestRegisterExistingProfile(t *testing.T) {
+	server := newTestServer()
+	ctx := context.Background()
+
+	created, err := server.CreateProfile(ctx, testProfile())
+	if err != nil {
+		t.Fatalf("CreateProfile(_) = _, %v", err)
+	}
+
+	// Knowing the device identifier is not enough to take the profile over
+	other, err := server.Register(ctx, registerRequest("abc123", "mallory@example.com"))
+	if err != nil || other.Uuid == created.Uuid {
+		t.Errorf("Register(device only) = %v, %v, want a new profile", other, err)
+	}
+	if anonymous, err := server.GetUUID(ctx, &pb.Identifier{Deviceidentifier: "abc123"}); err != nil || anonymous.Uuid != created.Uuid {
+		t.Errorf("GetUUID(abc123) = %v, %v, want %s", anonymous, err, created.Uuid)
+	}
+
+	owner := registerRequest("abc123", "john@example.com")
+	owner.Currentuser = created
+	registered, err := server.Register(ctx, owner)
+	if err != nil || registered.Uuid != created.Uuid {
+		t.Errorf("Register(signed in) = %v, %v, want %s", registered, err, created.Uuid)
+	}
+	again := registerRequest("abc123", "jane@example.com")
+	again.Currentuser = created
+	if _, err := server.Register(ctx, again); grpc.Code(err) != codes.AlreadyExists {
+		t.Errorf("Register(registered profile) = _, %v, want %v", err, codes.AlreadyExists)
+	}
+	unknown := registerRequest("abc123", "jane@example.com")
+	unknown.Currentuser = &pb.UserId{Uuid: "00000000-0000-0000-0000-000000000000"}
+	if _, err := server.Register(ctx, unknown); grpc.Code(err) != codes.NotFound {
+		t.Errorf("Register(unknown profile) = _, %v, want %v", err, codes.NotFound)
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	server := newTestServer()
+	ctx := context.Background()
+
+	short := registerRequest("abc123", "john@example.com")
+	short.Password = "horse"
+	noDevice := registerRequest("", "john@example.com")
+	for _, request := range []*pb.RegisterRequest{
+		registerRequest("abc123", "john"),
+		registerRequest("abc123", "John <john@example.com>"),
+		short,
+		noDevice,
+		{Email: "john@example.com", Password: "correct horse"},
+	} {
+		if _, err := server.Register(ctx, request); grpc.Code(err) != codes.InvalidArgument {
+			t.Errorf("Register(%v) = _, %v, want %v", request, err, codes.InvalidArgument)
+		}
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	server := newTestServer()
+	ctx := context.Background()
+
+	userID, err := server.Register(ctx, registerRequest("abc123", "john@example.com"))
+	if err != nil {
+		t.Fatalf("Register(_) = _, %v", err)
+	}
+
+	wrong := &pb.ChangePasswordRequest{Id: userID, Currentpassword: "wrong horse", Newpassword: "battery staple"}
+	if _, err := server.ChangePassword(ctx, wrong); grpc.Code(err) != codes.PermissionDenied {
+		t.Errorf("ChangePassword(wrong password) = _, %v, want %v", err, codes.PermissionDenied)
+	}
+	weak := &pb.ChangePasswordRequest{Id: userID, Currentpassword: "correct horse", Newpassword: "staple"}
+	if _, err := server.ChangePassword(ctx, weak); grpc.Code(err) != codes.InvalidArgument {
+		t.Errorf("ChangePassword(weak password) = _, %v, want %v", err, codes.InvalidArgument)
+	}
+	change := &pb.ChangePasswordRequest{Id: userID, Currentpassword: "correct horse", Newpassword: "battery staple"}
+	if _, err := server.ChangePassword(ctx, change); err != nil {
+		t.Fatalf("ChangePassword(_) = _, %v", err)
+	}
+
+	if _, err := server.VerifyCredentials(ctx, &pb.LoginRequest{Email: "john@example.com", Password: "correct horse"}); grpc.Code(err) != codes.Unauthenticated {
+		t.Errorf("VerifyCredentials(old password) = _, %v, want %v", err, codes.Unauthenticated)
+	}
+	if _, err := server.VerifyCredentials(ctx, &pb.LoginRequest{Email: "john@example.com", Password: "battery staple"}); err != nil {
+		t.Errorf("VerifyCredentials(new password) = _, %v", err)
+	}
+
+	anonymous, err := server.CreateProfile(ctx, registerRequest("def456", "").Profile)
+	if err != nil {
+		t.Fatalf("CreateProfile(_) = _, %v", err)
+	}
+	change.Id = anonymous
+	if _, err := server.ChangePassword(ctx, change); grpc.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ChangePassword(unregistered) = _, %v, want %v", err, codes.FailedPrecondition)
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	server := newTestServer()
+	ctx := context.Background()
+
+	userID, err := server.Register(ctx, registerRequest("abc123", "john@example.com"))
+	if err != nil {
+		t.Fatalf("Register(_) = _, %v", err)
+	}
+
+	// Unregistered emails get the same response, and no mail
+	if _, err := server.RequestPasswordReset(ctx, &pb.PasswordResetRequest{Email: "jane@example.com"}); err != nil {
+		t.Errorf("RequestPasswordReset(unregistered) = _, %v", err)
+	}
+	if sent := server.Mail.(*recordingMailSender).sent; len(sent) != 0 {
+		t.Errorf("RequestPasswordReset(unregistered) sent %v", sent)
+	}
+
+	if _, err := server.RequestPasswordReset(ctx, &pb.PasswordResetRequest{Email: "John@example.com"}); err != nil {
+		t.Fatalf("RequestPasswordReset(_) = _, %v", err)
+	}
+	if to := server.Mail.(*recordingMailSender).sent[0].To; to != "john@example.com" {
+		t.Errorf("RequestPasswordReset(_) mailed %s, want john@example.com", to)
+	}
+	code := resetCode(t, server)
+
+	wrong := &pb.ResetPasswordRequest{Email: "john@example.com", Code: "AAAAAAAAAAAAAAAA", Newpassword: "battery staple"}
+	if _, err := server.ResetPassword(ctx, wrong); grpc.Code(err) != codes.PermissionDenied {
+		t.Errorf("ResetPassword(wrong code) = _, %v, want %v", err, codes.PermissionDenied)
+	}
+	reset := &pb.ResetPasswordRequest{Email: "john@example.com", Code: strings.ToLower(code), Newpassword: "battery staple"}
+	resetID, err := server.ResetPassword(ctx, reset)
+	if err != nil || resetID.Uuid != userID.Uuid {
+		t.Fatalf("ResetPassword(_) = %v, %v, want %s", resetID, err, userID.Uuid)
+	}
+	if _, err := server.VerifyCredentials(ctx, &pb.LoginRequest{Email: "john@example.com", Password: "battery staple"}); err != nil {
+		t.Errorf("VerifyCredentials(new password) = _, %v", err)
+	}
+	if _, err := server.ResetPassword(ctx, reset); grpc.Code(err) != codes.PermissionDenied {
+		t.Errorf("ResetPassword(used code) = _, %v, want %v", err, codes.PermissionDenied)
+	}
+}
+
+func TestPasswordResetCodeExpires(t *testing.T) {
+	server := newTestServer()
+	server.ResetCodeTTL = time.Millisecond
+	ctx := context.Background()
+
+	if _, err := server.Register(ctx, registerRequest("abc123", "john@example.com")); err != nil {
+		t.Fatalf("Register(_) = _, %v", err)
+	}
+	if _, err := server.RequestPasswordReset(ctx, &pb.PasswordResetRequest{Email: "john@example.com"}); err != nil {
+		t.Fatalf("RequestPasswordReset(_) = _, %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	reset := &pb.ResetPasswordRequest{Email: "john@example.com", Code: resetCode(t, server), Newpassword: "battery staple"}
+	if _, err := server.ResetPassword(ctx, reset); grpc.Code(err) != codes.PermissionDenied {
+		t.Errorf("ResetPassword(expired code) = _, %v, want %v", err, codes.PermissionDenied)
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := profile.HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword(_) = _, %v", err)
+	}
+	if !strings.HasPrefix(hash, "bcrypt-sha256$") {
+		t.Errorf("HashPassword(_) = %s, want a bcrypt-sha256 hash", hash)
+	}
+	if !profile.CheckPassword(hash, "correct horse") {
+		t.Errorf("CheckPassword(%s, correct horse) = false, want true", hash)
+	}
+	if profile.CheckPassword(hash, "correct horsE") {
+		t.Errorf("CheckPassword(%s, correct horsE) = true, want false", hash)
+	}
+
+	// Passwords differing past bcrypt's 72 bytes are still told apart
+	long := strings.Repeat("a", 80)
+	if hash, err := profile.HashPassword(long); err != nil || profile.CheckPassword(hash, long[:79]+"b") {
+		t.Errorf("CheckPassword(HashPassword(long), other long) = true, %v, want false", err)
+	}
+
+	for _, encoded := range []string{
+		hash[:len(hash)-4],
+		"",
+		"bcrypt$10$abc$def",
+		strings.TrimPrefix(hash, "bcrypt-sha256$"),
+		"pbkdf2-sha256$1000$MDEyMzQ1Njc4OWFiY2RlZg$cBg8D2DungRB9k76szThf5ehfyBz991ay6PT8Srwk4M",
+	} {
+		if profile.CheckPassword(encoded, "correct horse") {
+			t.Errorf("CheckPassword(%q, correct horse) = true, want false", encoded)
+		}
+	}
+}
+
+func TestWriterMailSender(t *testing.T) {
+	var out bytes.Buffer
+	sender := profile.NewWriterMailSender(&out)
+	if err := sender.Send(&profile.MailMessage{To: "john@example.com", Subject: "Hello", Body: "Hi John"}); err != nil {
+		t.Fatalf("Send(_) = %v", err)
+	}
+	for _, want := range []string{"To: john@example.com\r\n", "Subject: Hello\r\n", "\r\n\r\nHi John\r\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Send(_) wrote %q, want it to contain %q", out.String(), want)
+		}
+	}
+}
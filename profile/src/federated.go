@@ -0,0 +1,142 @@
+/*
+// ----------------------------------------------------------------------------
+// federated.go
+// Countertop Profile Microservice Federated Identities
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package profile
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	pb "github.com/theorangechefco/cts/go-protos"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// FederatedLogin returns the user an identity provider account is linked
+// to. The first time the account signs in, it is linked to the anonymous
+// profile the caller is signed in to, or to a new profile. The caller is
+// trusted to have verified the provider's ID token and session token.
+func (s *Server) FederatedLogin(ctx context.Context, request *pb.FederatedLoginRequest) (*pb.UserId, error) {
+	if request.Issuer == "" || request.Subject == "" {
+		errorMsg := fmt.Sprintf("Issuer and subject not specified.")
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "FederatedLogin"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.InvalidArgument, errorMsg)
+	}
+	account := fmt.Sprintf("%s account %s", request.Issuer, request.Subject)
+
+	identity, err := s.Repository.FindFederatedIdentity(request.Issuer, request.Subject)
+	if err == nil {
+		s.Logger.Info(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "federatedidentity",
+			"rpc":   "FederatedLogin"},
+			fmt.Sprintf("Returning UserID %s for %s", identity.UUID, account))
+		return &pb.UserId{Uuid: identity.UUID}, nil
+	}
+	if err != ErrFederatedIdentityNotFound {
+		errorMsg := fmt.Sprintf("Database query failed: %v", err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "database",
+			"rpc":   "FederatedLogin"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.Unknown, errorMsg)
+	}
+
+	// First sign in
+	if request.Profile == nil || request.Profile.Identifier == nil || request.Profile.Identifier.Deviceidentifier == "" {
+		errorMsg := fmt.Sprintf("Profile with a device identifier not specified for first sign in of %s.", account)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "FederatedLogin"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.InvalidArgument, errorMsg)
+	}
+
+	if request.Profile.Dietaryprofile == nil {
+		request.Profile.Dietaryprofile = &pb.DietaryProfile{Omnivore: true}
+	}
+	if request.Profile.Dietaryrestriction == nil {
+		request.Profile.Dietaryrestriction = &pb.DietaryRestriction{}
+	}
+	user, created, err := s.accountProfile(request.Currentuser, request.Profile)
+	if err != nil {
+		errorMsg := fmt.Sprintf("Database query failed: %v", err)
+		code := codes.Unknown
+		switch err {
+		case ErrProfileNotFound:
+			errorMsg = fmt.Sprintf("Profile with ID %s not found, cannot link %s.", request.Currentuser.Uuid, account)
+			code = codes.NotFound
+		case errAccountExists:
+			errorMsg = fmt.Sprintf("Profile with ID %s belongs to another account, cannot link %s.", request.Currentuser.Uuid, account)
+			code = codes.AlreadyExists
+		}
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "database",
+			"rpc":   "FederatedLogin"},
+			errorMsg)
+		return nil, grpc.Errorf(code, errorMsg)
+	}
+
+	identity = &FederatedIdentity{UUID: user.UUID, Issuer: request.Issuer, Subject: request.Subject, Email: request.Email}
+	if err := s.Repository.CreateFederatedIdentity(identity); err != nil {
+		if err == ErrDuplicateFederatedIdentity {
+			// Linked by a concurrent first sign in
+			if identity, err = s.Repository.FindFederatedIdentity(request.Issuer, request.Subject); err == nil {
+				return &pb.UserId{Uuid: identity.UUID}, nil
+			}
+		}
+		errorMsg := fmt.Sprintf("Could not link %s. Error: %v", account, err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "createrecord",
+			"tag":   "database",
+			"rpc":   "FederatedLogin"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.Unknown, errorMsg)
+	}
+	if created {
+		if err := s.createAccountUser(user); err != nil {
+			s.Repository.DeleteFederatedIdentity(identity)
+			errorMsg := fmt.Sprintf("Could not add record for profile with ID: %v. Error: %v", request.Profile.Identifier, err)
+			code := codes.Unknown
+			if err == ErrDuplicateProfile {
+				code = codes.AlreadyExists
+			}
+			s.Logger.Error(logrus.Fields{
+				"phase": "process",
+				"event": "createrecord",
+				"tag":   "database",
+				"rpc":   "FederatedLogin"},
+				errorMsg)
+			return nil, grpc.Errorf(code, errorMsg)
+		}
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "createrecord",
+		"tag":   "federatedidentity",
+		"rpc":   "FederatedLogin"},
+		fmt.Sprintf("Linked %s to user with UUID %s", account, user.UUID))
+
+	return &pb.UserId{Uuid: user.UUID}, nil
+}
@@ -0,0 +1,113 @@
+/*
+// ----------------------------------------------------------------------------
+// federated_test.go
+// Countertop Profile Microservice Federated Identity Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package profile_test
+
+import (
+	"testing"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const testIssuer = "https://accounts.example.com"
+
+func federatedLoginRequest(subject string, deviceID string) *pb.FederatedLoginRequest {
+	return &pb.FederatedLoginRequest{
+		Issuer:  testIssuer,
+		Subject: subject,
+		Email:   "john@example.com",
+		Profile: &pb.Profile{
+			Identifier: &pb.Identifier{Deviceidentifier: deviceID},
+			Firstname:  "John",
+		},
+	}
+}
+
+func TestFederatedLogin(t *testing.T) {
+	server := newTestServer()
+	ctx := context.Background()
+
+	userID, err := server.FederatedLogin(ctx, federatedLoginRequest("1089154", "abc123"))
+	if err != nil {
+		t.Fatalf("FederatedLogin(first sign in) = _, %v", err)
+	}
+	stored, err := server.GetProfileInfoByUUID(ctx, userID)
+	if err != nil || stored.Firstname != "John" || !stored.Dietaryprofile.Omnivore {
+		t.Errorf("GetProfileInfoByUUID(%s) = %v, %v, want a new profile for John", userID.Uuid, stored, err)
+	}
+	if _, err := server.GetUUID(ctx, &pb.Identifier{Deviceidentifier: "abc123"}); grpc.Code(err) != codes.PermissionDenied {
+		t.Errorf("GetUUID(linked device) = _, %v, want %v", err, codes.PermissionDenied)
+	}
+
+	// Later sign ins find the same user from any device, without a profile
+	again, err := server.FederatedLogin(ctx, &pb.FederatedLoginRequest{Issuer: testIssuer, Subject: "1089154"})
+	if err != nil || again.Uuid != userID.Uuid {
+		t.Errorf("FederatedLogin(again) = %v, %v, want %s", again, err, userID.Uuid)
+	}
+
+	// The same subject at another issuer is another account
+	otherIssuer := federatedLoginRequest("1089154", "def456")
+	otherIssuer.Issuer = "https://login.example.net"
+	if other, err := server.FederatedLogin(ctx, otherIssuer); err != nil || other.Uuid == userID.Uuid {
+		t.Errorf("FederatedLogin(other issuer) = %v, %v, want a new user", other, err)
+	}
+
+	// Another account on the same device gets its own profile
+	if other, err := server.FederatedLogin(ctx, federatedLoginRequest("2271828", "abc123")); err != nil || other.Uuid == userID.Uuid {
+		t.Errorf("FederatedLogin(other account on linked device) = %v, %v, want a new user", other, err)
+	}
+	signedIn := federatedLoginRequest("5772156", "abc123")
+	signedIn.Currentuser = userID
+	if _, err := server.FederatedLogin(ctx, signedIn); grpc.Code(err) != codes.AlreadyExists {
+		t.Errorf("FederatedLogin(signed in to an account) = _, %v, want %v", err, codes.AlreadyExists)
+	}
+	if other, err := server.Register(ctx, registerRequest("abc123", "jane@example.com")); err != nil || other.Uuid == userID.Uuid {
+		t.Errorf("Register(linked device) = %v, %v, want a new user", other, err)
+	}
+
+	for _, request := range []*pb.FederatedLoginRequest{
+		{Issuer: testIssuer, Subject: "3141592"},
+		{Issuer: testIssuer, Profile: federatedLoginRequest("", "ghi789").Profile},
+		{Subject: "3141592", Profile: federatedLoginRequest("", "ghi789").Profile},
+	} {
+		if _, err := server.FederatedLogin(ctx, request); grpc.Code(err) != codes.InvalidArgument {
+			t.Errorf("FederatedLogin(%v) = _, %v, want %v", request, err, codes.InvalidArgument)
+		}
+	}
+}
+
+func TestFederatedLoginLinksSignedInProfile(t *testing.T) {
+	server := newTestServer()
+	ctx := context.Background()
+
+	created, err := server.CreateProfile(ctx, testProfile())
+	if err != nil {
+		t.Fatalf("CreateProfile(_) = _, %v", err)
+	}
+
+	// Knowing the device identifier is not enough to take the profile over
+	other, err := server.FederatedLogin(ctx, federatedLoginRequest("2271828", "abc123"))
+	if err != nil || other.Uuid == created.Uuid {
+		t.Errorf("FederatedLogin(device only) = %v, %v, want a new profile", other, err)
+	}
+
+	request := federatedLoginRequest("1089154", "abc123")
+	request.Currentuser = created
+	linked, err := server.FederatedLogin(ctx, request)
+	if err != nil || linked.Uuid != created.Uuid {
+		t.Errorf("FederatedLogin(signed in) = %v, %v, want %s", linked, err, created.Uuid)
+	}
+	stored, err := server.GetProfileInfoByUUID(ctx, linked)
+	if err != nil || stored.Weightkg != testProfile().Weightkg {
+		t.Errorf("GetProfileInfoByUUID(%s) = %v, %v, want the signed in profile kept", linked.Uuid, stored, err)
+	}
+}
@@ -0,0 +1,90 @@
+/*
+// ----------------------------------------------------------------------------
+// mail.go
+// Countertop Profile Mail Senders
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package profile
+
+import (
+	"fmt"
+	"io"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// MailSender delivers mail to users, such as password reset codes.
+type MailSender interface {
+	Send(message *MailMessage) error
+}
+
+//
+// Local mail sender, for development setups
+//
+
+// WriterMailSender writes each message to a writer instead of delivering
+// it, such as the console or a file.
+type WriterMailSender struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterMailSender(w io.Writer) *WriterMailSender {
+	return &WriterMailSender{w: w}
+}
+
+func NewConsoleMailSender() *WriterMailSender {
+	return NewWriterMailSender(os.Stdout)
+}
+
+// NewFileMailSender appends messages to the file at path, creating it if
+// needed.
+func NewFileMailSender(path string) (*WriterMailSender, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterMailSender(file), nil
+}
+
+func (w *WriterMailSender) Send(message *MailMessage) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := fmt.Fprintf(w.w, "Date: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n.\r\n",
+		time.Now().Format(time.RFC1123Z), message.To, message.Subject, message.Body)
+	return err
+}
+
+//
+// SMTP mail sender
+//
+
+type SMTPMailSender struct {
+	// Host and port of the SMTP server
+	Addr string
+	From string
+	// Auth may be nil for servers that do not require it
+	Auth smtp.Auth
+}
+
+func (s *SMTPMailSender) Send(message *MailMessage) error {
+	if strings.ContainsAny(message.To+message.Subject, "\r\n") {
+		return fmt.Errorf("mail headers cannot contain line breaks")
+	}
+	body := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		s.From, message.To, message.Subject, time.Now().Format(time.RFC1123Z), message.Body)
+	return smtp.SendMail(s.Addr, s.Auth, s.From, []string{message.To}, []byte(body))
+}
@@ -14,6 +14,9 @@ import (
 	"flag"
 	"fmt"
 	"net"
+	"net/smtp"
+	"strings"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jinzhu/gorm"
@@ -30,6 +33,7 @@ import (
 
 var (
 	port          = flag.Int("port", 50051, "The server port")
+	store         = flag.String("store", "mysql", "Profile store: mysql, or memory to keep profiles in memory until the server exits")
 	dbHost        = flag.String("db_host", ":::::::", "Hostname of MySQL server")
 	dbPort        = flag.Int("db_port", 3306, "Port of MySQL server")
 	dbUser        = flag.String("db_user", "admin", "Username of MySQL server")
@@ -44,6 +48,13 @@ var (
 	stdErrLog     = flag.Bool("stderr_log", true, "Log to STDERR")
 	fluentdHost   = flag.String("fluentd_host", "", "Fluentd agent hostname. If left blank, fluentd logging disabled")
 	fluentdPort   = flag.Int("fluentd_port", 24224, "Fluentd agent port")
+	mail          = flag.String("mail", "console", "How password reset codes are mailed: console, file to append them to --mail_file, or smtp")
+	mailFile      = flag.String("mail_file", "mail.log", "File password reset mail is appended to")
+	mailFrom      = flag.String("mail_from", "no-reply@theorangechef.com", "Sender address of mail")
+	smtpAddr      = flag.String("smtp_addr", "localhost:25", "Host and port of SMTP server")
+	smtpUser      = flag.String("smtp_user", "", "Username of SMTP server. If left blank, mail is sent without authentication")
+	smtpPass      = flag.String("smtp_pass", "", "Password of SMTP server user")
+	resetCodeTTL  = flag.Duration("reset_code_ttl", time.Hour, "How long password reset codes can be used for")
 )
 
 func main() {
@@ -52,35 +63,50 @@ func main() {
 
 	profileServerInstance := new(profileutil.Server)
 	profileServerInstance.Logger = logger.NewLogger("profilesrv", *fluentdHost, *fluentdPort, *stdErrLog, logrus.DebugLevel)
-	connStr := fmt.Sprintf("%s:%s@tcp([%s]:%d)/%s?charset=utf8&parseTime=true", *dbUser, *dbPass, *dbHost, *dbPort, *dbName)
 
-	profileServerInstance.DB, err = gorm.Open("mysql", connStr)
-	if err != nil {
+	switch *store {
+	case "mysql":
+		db := connectMySQL(profileServerInstance.Logger)
+		defer db.Close()
+		profileServerInstance.Repository = &profileutil.MySQLProfileRepository{DB: db}
+	case "memory":
+		profileServerInstance.Repository = profileutil.NewMemoryProfileRepository()
+		profileServerInstance.Logger.Info(logrus.Fields{
+			"phase": "startup",
+			"event": "setup"},
+			"Keeping profiles in memory, they will be lost when the server exits")
+	default:
 		profileServerInstance.Logger.Fatal(logrus.Fields{
 			"phase": "startup",
-			"event": "connect"},
-			fmt.Sprintf("Unable to set up connection to with database: %s Error: %v", connStr, err))
-	}
-	defer profileServerInstance.DB.Close()
-
-	infoMsg := fmt.Sprintf("Connected to: ([%s]:%d)", *dbHost, *dbPort)
-	profileServerInstance.Logger.Info(logrus.Fields{
-		"phase": "startup",
-		"event": "connect"},
-		infoMsg)
-
-	profileServerInstance.DB.DB().SetMaxIdleConns(*dbMaxIdleConn)
-	profileServerInstance.DB.DB().SetMaxOpenConns(*dbMaxOpenConn)
-	profileServerInstance.DB.SingularTable(true)
-	if *dbLog {
-		profileServerInstance.DB.LogMode(true)
+			"event": "setup"},
+			fmt.Sprintf("Unknown profile store %q, expected mysql or memory", *store))
 	}
 
-	if err := profileServerInstance.DB.DB().Ping(); err != nil {
+	profileServerInstance.ResetCodeTTL = *resetCodeTTL
+	switch *mail {
+	case "console":
+		profileServerInstance.Mail = profileutil.NewConsoleMailSender()
+	case "file":
+		sender, err := profileutil.NewFileMailSender(*mailFile)
+		if err != nil {
+			profileServerInstance.Logger.Fatal(logrus.Fields{
+				"phase": "startup",
+				"event": "setup",
+				"tag":   "mail"},
+				fmt.Sprintf("Cannot open mail file: %v", err))
+		}
+		profileServerInstance.Mail = sender
+	case "smtp":
+		sender := &profileutil.SMTPMailSender{Addr: *smtpAddr, From: *mailFrom}
+		if *smtpUser != "" {
+			sender.Auth = smtp.PlainAuth("", *smtpUser, *smtpPass, strings.Split(*smtpAddr, ":")[0])
+		}
+		profileServerInstance.Mail = sender
+	default:
 		profileServerInstance.Logger.Fatal(logrus.Fields{
 			"phase": "startup",
-			"event": "connect"},
-			fmt.Sprintf("Unable to pinh database %s. Error: %v", connStr, err))
+			"event": "setup"},
+			fmt.Sprintf("Unknown mail sender %q, expected console, file or smtp", *mail))
 	}
 
 	lis, lisErr := net.Listen("tcp", fmt.Sprintf(":%d", *port))
@@ -114,3 +140,36 @@ func main() {
 
 	grpcServer.Serve(lis)
 }
+
+func connectMySQL(log *logger.CtsLogger) gorm.DB {
+	connStr := fmt.Sprintf("%s:%s@tcp([%s]:%d)/%s?charset=utf8&parseTime=true", *dbUser, *dbPass, *dbHost, *dbPort, *dbName)
+
+	db, err := gorm.Open("mysql", connStr)
+	if err != nil {
+		log.Fatal(logrus.Fields{
+			"phase": "startup",
+			"event": "connect"},
+			fmt.Sprintf("Unable to set up connection to with database: %s Error: %v", connStr, err))
+	}
+
+	infoMsg := fmt.Sprintf("Connected to: ([%s]:%d)", *dbHost, *dbPort)
+	log.Info(logrus.Fields{
+		"phase": "startup",
+		"event": "connect"},
+		infoMsg)
+
+	db.DB().SetMaxIdleConns(*dbMaxIdleConn)
+	db.DB().SetMaxOpenConns(*dbMaxOpenConn)
+	db.SingularTable(true)
+	if *dbLog {
+		db.LogMode(true)
+	}
+
+	if err := db.DB().Ping(); err != nil {
+		log.Fatal(logrus.Fields{
+			"phase": "startup",
+			"event": "connect"},
+			fmt.Sprintf("Unable to pinh database %s. Error: %v", connStr, err))
+	}
+	return db
+}
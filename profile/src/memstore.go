@@ -0,0 +1,245 @@
+/*
+// ----------------------------------------------------------------------------
+// memstore.go
+// Countertop Profile In-Memory Repository
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package profile
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryProfileRepository keeps profiles in memory, for tests and for running
+// the service without MySQL. Like the user table, device and user
+// identifiers must be unique, as must credential email addresses.
+type MemoryProfileRepository struct {
+	mu     sync.RWMutex
+	lastID uint
+	users  map[string]User
+	// Credentials by user UUID
+	lastCredentialID uint
+	credentials      map[string]Credential
+	// Federated identities by issuer and subject
+	lastFederatedID uint
+	federated       map[federatedKey]FederatedIdentity
+}
+
+type federatedKey struct {
+	issuer  string
+	subject string
+}
+
+func NewMemoryProfileRepository() *MemoryProfileRepository {
+	return &MemoryProfileRepository{
+		users:       make(map[string]User),
+		credentials: make(map[string]Credential),
+		federated:   make(map[federatedKey]FederatedIdentity),
+	}
+}
+
+func (m *MemoryProfileRepository) FindByUserID(userID string) (*User, error) {
+	return m.find(func(user *User) bool { return userID != "" && user.UserId == userID })
+}
+
+func (m *MemoryProfileRepository) FindByDeviceID(deviceID string) (*User, error) {
+	return m.find(func(user *User) bool { return deviceID != "" && user.DeviceId == deviceID })
+}
+
+func (m *MemoryProfileRepository) FindByUUID(uuid string) (*User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	user, ok := m.users[uuid]
+	if !ok {
+		return nil, ErrProfileNotFound
+	}
+	return &user, nil
+}
+
+func (m *MemoryProfileRepository) find(matches func(*User) bool) (*User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.users {
+		if matches(&user) {
+			return &user, nil
+		}
+	}
+	return nil, ErrProfileNotFound
+}
+
+func (m *MemoryProfileRepository) CreateUser(user *User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[user.UUID]; ok || m.conflicts(user) {
+		return ErrDuplicateProfile
+	}
+	m.lastID++
+	user.ID = m.lastID
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	m.users[user.UUID] = *user
+	return nil
+}
+
+func (m *MemoryProfileRepository) UpdateUser(user *User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.users[user.UUID]
+	if !ok {
+		return ErrProfileNotFound
+	}
+	updated := *user
+	updated.ID = stored.ID
+	updated.DeviceId = stored.DeviceId
+	updated.UserId = stored.UserId
+	updated.Role = stored.Role
+	updated.CreatedAt = stored.CreatedAt
+	updated.UpdatedAt = time.Now()
+	user.UpdatedAt = updated.UpdatedAt
+	m.users[user.UUID] = updated
+	return nil
+}
+
+// conflicts reports whether another user has the same device or user
+// identifier. Callers must hold m.mu.
+func (m *MemoryProfileRepository) conflicts(user *User) bool {
+	for uuid, other := range m.users {
+		if uuid == user.UUID {
+			continue
+		}
+		if (user.DeviceId != "" && other.DeviceId == user.DeviceId) || (user.UserId != "" && other.UserId == user.UserId) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MemoryProfileRepository) FindCredentialByEmail(email string) (*Credential, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, credential := range m.credentials {
+		if email != "" && credential.Email == email {
+			return &credential, nil
+		}
+	}
+	return nil, ErrCredentialNotFound
+}
+
+func (m *MemoryProfileRepository) FindCredentialByUUID(uuid string) (*Credential, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	credential, ok := m.credentials[uuid]
+	if !ok {
+		return nil, ErrCredentialNotFound
+	}
+	return &credential, nil
+}
+
+func (m *MemoryProfileRepository) CreateCredential(credential *Credential) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.credentials[credential.UUID]; ok {
+		return ErrDuplicateProfile
+	}
+	if m.emailTaken(credential) {
+		return ErrDuplicateEmail
+	}
+	m.lastCredentialID++
+	credential.ID = m.lastCredentialID
+	credential.CreatedAt = time.Now()
+	credential.UpdatedAt = credential.CreatedAt
+	m.credentials[credential.UUID] = *credential
+	return nil
+}
+
+func (m *MemoryProfileRepository) UpdateCredential(credential *Credential) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.credentials[credential.UUID]; !ok {
+		return ErrCredentialNotFound
+	}
+	if m.emailTaken(credential) {
+		return ErrDuplicateEmail
+	}
+	credential.UpdatedAt = time.Now()
+	m.credentials[credential.UUID] = *credential
+	return nil
+}
+
+func (m *MemoryProfileRepository) DeleteCredential(credential *Credential) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.credentials, credential.UUID)
+	return nil
+}
+
+func (m *MemoryProfileRepository) FindFederatedIdentity(issuer string, subject string) (*FederatedIdentity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	identity, ok := m.federated[federatedKey{issuer, subject}]
+	if !ok {
+		return nil, ErrFederatedIdentityNotFound
+	}
+	return &identity, nil
+}
+
+func (m *MemoryProfileRepository) FindFederatedIdentityByUUID(uuid string) (*FederatedIdentity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, identity := range m.federated {
+		if identity.UUID == uuid {
+			return &identity, nil
+		}
+	}
+	return nil, ErrFederatedIdentityNotFound
+}
+
+func (m *MemoryProfileRepository) CreateFederatedIdentity(identity *FederatedIdentity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := federatedKey{identity.Issuer, identity.Subject}
+	if _, ok := m.federated[key]; ok {
+		return ErrDuplicateFederatedIdentity
+	}
+	m.lastFederatedID++
+	identity.ID = m.lastFederatedID
+	identity.CreatedAt = time.Now()
+	identity.UpdatedAt = identity.CreatedAt
+	m.federated[key] = *identity
+	return nil
+}
+
+func (m *MemoryProfileRepository) DeleteFederatedIdentity(identity *FederatedIdentity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.federated, federatedKey{identity.Issuer, identity.Subject})
+	return nil
+}
+
+// emailTaken reports whether another user's credential has the same email
+// address. Callers must hold m.mu.
+func (m *MemoryProfileRepository) emailTaken(credential *Credential) bool {
+	for uuid, other := range m.credentials {
+		if uuid != credential.UUID && other.Email == credential.Email {
+			return true
+		}
+	}
+	return false
+}
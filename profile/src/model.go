@@ -20,7 +20,7 @@ import (
 type User struct {
 	ID            uint   `gorm:"primary_key"`
 	UUID          string `sql:"unique;not null;index"`
-	DeviceId      string `sql:"unique;not null;index"`
+	DeviceId      string `sql:"unique;default: null;index"`
 	UserId        string `sql:"unique;default: null;index"`
 	Firstname     string
 	Birthyear     int32
@@ -30,7 +30,9 @@ type User struct {
 	Goalweightkg  float32
 	Activitylevel int32
 	Mealplan      int32
+	Dailycalories int32
 	Weightgoal    int32
+	Role          int32
 	Omnivore      bool `sql:"default: 1"`
 	Vegetarian    bool `sql:"default: 0"`
 	Vegan         bool `sql:"default: 0"`
@@ -43,3 +45,30 @@ type User struct {
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
+
+// Credential is the email address and password a user signs in with. Users
+// created from a device identifier alone have none.
+type Credential struct {
+	ID           uint   `gorm:"primary_key"`
+	UUID         string `sql:"unique;not null;index"`
+	Email        string `sql:"unique;not null;index"`
+	PasswordHash string `sql:"not null"`
+	// SHA-256 of the outstanding password reset code, if any
+	ResetCodeHash    string
+	ResetCodeExpires time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// FederatedIdentity links a user to an account at an OpenID Connect
+// provider, identified by the provider's issuer and its ID for the user.
+type FederatedIdentity struct {
+	ID      uint   `gorm:"primary_key"`
+	UUID    string `sql:"not null;index"`
+	Issuer  string `sql:"not null;unique_index:uix_federated_identity_issuer_subject"`
+	Subject string `sql:"not null;unique_index:uix_federated_identity_issuer_subject"`
+	// Verified email address the provider reported, if any
+	Email     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
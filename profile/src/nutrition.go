@@ -0,0 +1,192 @@
+/*
+// ----------------------------------------------------------------------------
+// nutrition.go
+// Countertop Profile Calorie Target Computation
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+const (
+	weightLossDeficit = 500
+	weightGainSurplus = 300
+	minCaloriesFemale = 1200
+	minCaloriesMale   = 1500
+)
+
+var ErrIncompleteBiometrics = errors.New("height, weight and birth year are required")
+
+// Multipliers applied to BMR to estimate total daily energy expenditure.
+var activityMultipliers = map[pb.ActivityLevel]float64{
+	pb.ActivityLevel_SEDENTARY:         1.2,
+	pb.ActivityLevel_LIGHTLY_ACTIVE:    1.375,
+	pb.ActivityLevel_MODERATELY_ACTIVE: 1.55,
+	pb.ActivityLevel_VERY_ACTIVE:       1.725,
+	pb.ActivityLevel_EXTRA_ACTIVE:      1.9,
+}
+
+// Meal plans in ascending calorie order.
+var mealPlanCalories = []struct {
+	mealPlan pb.MealPlan
+	calories float64
+}{
+	{pb.MealPlan_TWELVE_HUNDRED, 1200},
+	{pb.MealPlan_FIFTEEN_HUNDRED, 1500},
+	{pb.MealPlan_EIGHTEEN_HUNDRED, 1800},
+	{pb.MealPlan_TWENTY_ONE_HUNDRED, 2100},
+	{pb.MealPlan_TWENTY_FOUR_HUNDRED, 2400},
+}
+
+type Biometrics struct {
+	Gender        pb.Gender
+	Birthyear     int32
+	Heightcm      float32
+	Weightkg      float32
+	Activitylevel pb.ActivityLevel
+	Weightgoal    pb.WeightGoal
+}
+
+func (u *User) Biometrics() Biometrics {
+	return Biometrics{
+		Gender:        pb.Gender(u.Gender),
+		Birthyear:     u.Birthyear,
+		Heightcm:      u.Heightcm,
+		Weightkg:      u.Weightkg,
+		Activitylevel: pb.ActivityLevel(u.Activitylevel),
+		Weightgoal:    pb.WeightGoal(u.Weightgoal),
+	}
+}
+
+func ProfileBiometrics(profile *pb.Profile) Biometrics {
+	return Biometrics{
+		Gender:        profile.Gender,
+		Birthyear:     profile.Birthyear,
+		Heightcm:      profile.Heightcm,
+		Weightkg:      profile.Weightkg,
+		Activitylevel: profile.Activitylevel,
+		Weightgoal:    profile.Weightgoal,
+	}
+}
+
+// ComputeCalorieTarget estimates BMR with the Mifflin-St Jeor equation,
+// scales it by activity level to get TDEE, adjusts for the weight goal and
+// picks the meal plan closest to the resulting daily calorie target.
+func ComputeCalorieTarget(biometrics Biometrics, currentYear int) (*pb.CalorieTarget, error) {
+	age := currentYear - int(biometrics.Birthyear)
+	if biometrics.Heightcm <= 0 || biometrics.Weightkg <= 0 || biometrics.Birthyear <= 0 || age <= 0 {
+		return nil, ErrIncompleteBiometrics
+	}
+
+	bmr := 10*float64(biometrics.Weightkg) + 6.25*float64(biometrics.Heightcm) - 5*float64(age)
+	minCalories := float64(minCaloriesMale)
+	if biometrics.Gender == pb.Gender_FEMALE {
+		bmr -= 161
+		minCalories = minCaloriesFemale
+	} else {
+		bmr += 5
+	}
+
+	multiplier, ok := activityMultipliers[biometrics.Activitylevel]
+	if !ok {
+		multiplier = activityMultipliers[pb.ActivityLevel_SEDENTARY]
+	}
+	tdee := bmr * multiplier
+
+	daily := tdee
+	switch biometrics.Weightgoal {
+	case pb.WeightGoal_LOSE:
+		daily -= weightLossDeficit
+	case pb.WeightGoal_GAIN:
+		daily += weightGainSurplus
+	}
+	daily = math.Max(daily, minCalories)
+
+	return &pb.CalorieTarget{
+		Bmr:           float32(bmr),
+		Tdee:          float32(tdee),
+		Dailycalories: int32(math.Floor(daily + 0.5)),
+		Mealplan:      closestMealPlan(daily),
+	}, nil
+}
+
+func closestMealPlan(calories float64) pb.MealPlan {
+	best := mealPlanCalories[0]
+	for _, candidate := range mealPlanCalories[1:] {
+		if math.Abs(candidate.calories-calories) < math.Abs(best.calories-calories) {
+			best = candidate
+		}
+	}
+	return best.mealPlan
+}
+
+func (s *Server) GetCalorieTarget(ctx context.Context, userID *pb.UserId) (*pb.CalorieTarget, error) {
+	if userID.Uuid == "" {
+		errorMsg := fmt.Sprintf("Identifier not specified.")
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "GetCalorieTarget"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.InvalidArgument, errorMsg)
+	}
+
+	user, err := s.Repository.FindByUUID(userID.Uuid)
+	if err != nil {
+		if err == ErrProfileNotFound {
+			errorMsg := fmt.Sprintf("Profile with id %s not found.", userID.Uuid)
+			s.Logger.Error(logrus.Fields{
+				"phase": "process",
+				"event": "fetch",
+				"tag":   "database",
+				"rpc":   "GetCalorieTarget"},
+				errorMsg)
+			return nil, grpc.Errorf(codes.NotFound, errorMsg)
+		}
+		errorMsg := fmt.Sprintf("Database query failed: %v", err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "database",
+			"rpc":   "GetCalorieTarget"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.Unknown, errorMsg)
+	}
+
+	target, err := ComputeCalorieTarget(user.Biometrics(), time.Now().Year())
+	if err != nil {
+		errorMsg := fmt.Sprintf("Cannot compute calorie target for profile %s: %v", userID.Uuid, err)
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "compute",
+			"tag":   "nutrition",
+			"rpc":   "GetCalorieTarget"},
+			errorMsg)
+		return nil, grpc.Errorf(codes.FailedPrecondition, errorMsg)
+	}
+
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "compute",
+		"tag":   "nutrition",
+		"rpc":   "GetCalorieTarget"},
+		fmt.Sprintf("Returning calorie target of %d for user with UserID %s", target.Dailycalories, user.UUID))
+
+	return target, nil
+}
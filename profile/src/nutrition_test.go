@@ -0,0 +1,117 @@
+/*
+// ----------------------------------------------------------------------------
+// nutrition_test.go
+// Countertop Profile Calorie Target Computation Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package profile_test
+
+import (
+	"math"
+	"testing"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+	profile "github.com/theorangechefco/cts/profile"
+)
+
+const testYear = 2015
+
+// 30 years old in testYear, with a BMR of 1780.
+func male(activity pb.ActivityLevel, goal pb.WeightGoal) profile.Biometrics {
+	return profile.Biometrics{
+		Gender:        pb.Gender_MALE,
+		Birthyear:     1985,
+		Heightcm:      180,
+		Weightkg:      80,
+		Activitylevel: activity,
+		Weightgoal:    goal,
+	}
+}
+
+// 30 years old in testYear, with a BMR of 1320.25.
+func female(activity pb.ActivityLevel, goal pb.WeightGoal) profile.Biometrics {
+	return profile.Biometrics{
+		Gender:        pb.Gender_FEMALE,
+		Birthyear:     1985,
+		Heightcm:      165,
+		Weightkg:      60,
+		Activitylevel: activity,
+		Weightgoal:    goal,
+	}
+}
+
+func TestComputeCalorieTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		biometrics profile.Biometrics
+		bmr        float64
+		tdee       float64
+		daily      int32
+		mealPlan   pb.MealPlan
+	}{
+		// Every activity level, maintaining weight
+		{"male sedentary", male(pb.ActivityLevel_SEDENTARY, pb.WeightGoal_MAINTAIN), 1780, 2136, 2136, pb.MealPlan_TWENTY_ONE_HUNDRED},
+		{"male lightly active", male(pb.ActivityLevel_LIGHTLY_ACTIVE, pb.WeightGoal_MAINTAIN), 1780, 2447.5, 2448, pb.MealPlan_TWENTY_FOUR_HUNDRED},
+		{"male moderately active", male(pb.ActivityLevel_MODERATELY_ACTIVE, pb.WeightGoal_MAINTAIN), 1780, 2759, 2759, pb.MealPlan_TWENTY_FOUR_HUNDRED},
+		{"male very active", male(pb.ActivityLevel_VERY_ACTIVE, pb.WeightGoal_MAINTAIN), 1780, 3070.5, 3071, pb.MealPlan_TWENTY_FOUR_HUNDRED},
+		{"male extra active", male(pb.ActivityLevel_EXTRA_ACTIVE, pb.WeightGoal_MAINTAIN), 1780, 3382, 3382, pb.MealPlan_TWENTY_FOUR_HUNDRED},
+		{"female sedentary", female(pb.ActivityLevel_SEDENTARY, pb.WeightGoal_MAINTAIN), 1320.25, 1584.3, 1584, pb.MealPlan_FIFTEEN_HUNDRED},
+		{"female lightly active", female(pb.ActivityLevel_LIGHTLY_ACTIVE, pb.WeightGoal_MAINTAIN), 1320.25, 1815.34375, 1815, pb.MealPlan_EIGHTEEN_HUNDRED},
+		{"female moderately active", female(pb.ActivityLevel_MODERATELY_ACTIVE, pb.WeightGoal_MAINTAIN), 1320.25, 2046.3875, 2046, pb.MealPlan_TWENTY_ONE_HUNDRED},
+		{"female very active", female(pb.ActivityLevel_VERY_ACTIVE, pb.WeightGoal_MAINTAIN), 1320.25, 2277.43125, 2277, pb.MealPlan_TWENTY_FOUR_HUNDRED},
+		{"female extra active", female(pb.ActivityLevel_EXTRA_ACTIVE, pb.WeightGoal_MAINTAIN), 1320.25, 2508.475, 2508, pb.MealPlan_TWENTY_FOUR_HUNDRED},
+
+		// Weight goals adjust the daily target but not BMR or TDEE
+		{"male losing", male(pb.ActivityLevel_LIGHTLY_ACTIVE, pb.WeightGoal_LOSE), 1780, 2447.5, 1948, pb.MealPlan_EIGHTEEN_HUNDRED},
+		{"male gaining", male(pb.ActivityLevel_SEDENTARY, pb.WeightGoal_GAIN), 1780, 2136, 2436, pb.MealPlan_TWENTY_FOUR_HUNDRED},
+		{"female losing", female(pb.ActivityLevel_VERY_ACTIVE, pb.WeightGoal_LOSE), 1320.25, 2277.43125, 1777, pb.MealPlan_EIGHTEEN_HUNDRED},
+		{"female gaining", female(pb.ActivityLevel_LIGHTLY_ACTIVE, pb.WeightGoal_GAIN), 1320.25, 1815.34375, 2115, pb.MealPlan_TWENTY_ONE_HUNDRED},
+
+		// Losing weight never goes below the floor for the gender
+		{"female floor", female(pb.ActivityLevel_SEDENTARY, pb.WeightGoal_LOSE), 1320.25, 1584.3, 1200, pb.MealPlan_TWELVE_HUNDRED},
+		{"male floor", profile.Biometrics{Gender: pb.Gender_MALE, Birthyear: 1945, Heightcm: 160, Weightkg: 55,
+			Activitylevel: pb.ActivityLevel_SEDENTARY, Weightgoal: pb.WeightGoal_LOSE}, 1205, 1446, 1500, pb.MealPlan_FIFTEEN_HUNDRED},
+
+		// Daily calories round half up, and the meal plan is the closest one
+		// above or below
+		{"daily rounds half up", male(pb.ActivityLevel_VERY_ACTIVE, pb.WeightGoal_LOSE), 1780, 3070.5, 2571, pb.MealPlan_TWENTY_FOUR_HUNDRED},
+		{"meal plan rounds down", female(pb.ActivityLevel_LIGHTLY_ACTIVE, pb.WeightGoal_LOSE), 1320.25, 1815.34375, 1315, pb.MealPlan_TWELVE_HUNDRED},
+		{"meal plan rounds up", male(pb.ActivityLevel_SEDENTARY, pb.WeightGoal_LOSE), 1780, 2136, 1636, pb.MealPlan_FIFTEEN_HUNDRED},
+	}
+
+	for _, test := range tests {
+		target, err := profile.ComputeCalorieTarget(test.biometrics, testYear)
+		if err != nil {
+			t.Errorf("%s: ComputeCalorieTarget(_) = _, %v", test.name, err)
+			continue
+		}
+		if math.Abs(float64(target.Bmr)-test.bmr) > 0.01 || math.Abs(float64(target.Tdee)-test.tdee) > 0.01 {
+			t.Errorf("%s: ComputeCalorieTarget(_) BMR, TDEE = %v, %v, want %v, %v", test.name, target.Bmr, target.Tdee, test.bmr, test.tdee)
+		}
+		if target.Dailycalories != test.daily || target.Mealplan != test.mealPlan {
+			t.Errorf("%s: ComputeCalorieTarget(_) daily calories, meal plan = %d, %v, want %d, %v",
+				test.name, target.Dailycalories, target.Mealplan, test.daily, test.mealPlan)
+		}
+	}
+}
+
+func TestComputeCalorieTargetMissingBiometrics(t *testing.T) {
+	tests := map[string]func(*profile.Biometrics){
+		"no height":       func(b *profile.Biometrics) { b.Heightcm = 0 },
+		"no weight":       func(b *profile.Biometrics) { b.Weightkg = 0 },
+		"negative weight": func(b *profile.Biometrics) { b.Weightkg = -80 },
+		"no birth year":   func(b *profile.Biometrics) { b.Birthyear = 0 },
+		"born this year":  func(b *profile.Biometrics) { b.Birthyear = testYear },
+		"born in future":  func(b *profile.Biometrics) { b.Birthyear = testYear + 1 },
+	}
+	for name, change := range tests {
+		biometrics := male(pb.ActivityLevel_SEDENTARY, pb.WeightGoal_MAINTAIN)
+		change(&biometrics)
+		if target, err := profile.ComputeCalorieTarget(biometrics, testYear); err != profile.ErrIncompleteBiometrics {
+			t.Errorf("%s: ComputeCalorieTarget(_) = %v, %v, want %v", name, target, err, profile.ErrIncompleteBiometrics)
+		}
+	}
+}
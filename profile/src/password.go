@@ -0,0 +1,108 @@
+/*
+// ----------------------------------------------------------------------------
+// password.go
+// Countertop Profile Password Hashing
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package profile
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashes are encoded as <scheme>$<hash>, so that the scheme can
+// change without invalidating the hashes already stored. New hashes are
+// bcrypt-sha256: bcrypt of the base64 SHA-256 of the password, since bcrypt
+// ignores everything past 72 bytes.
+const (
+	passwordScheme = "bcrypt-sha256"
+	passwordCost   = 12
+
+	MinPasswordLength = 8
+	MaxPasswordLength = 256
+
+	// Reset codes are mailed to users, and typed back into the app
+	resetCodeLength = 10
+)
+
+// unusedPasswordHash is checked against when no user matches an email, so
+// that failed sign ins take as long whether or not the email is registered.
+const unusedPasswordHash = "bcrypt-sha256$$2a$12$fTeQX4sP1ShLfNedfN7TF.xahblvJounjDbHHLayQIPAVzlCYJ4J6"
+
+var (
+	ErrInvalidEmail    = errors.New("invalid email address")
+	ErrPasswordTooWeak = fmt.Errorf("passwords must be between %d and %d characters", MinPasswordLength, MaxPasswordLength)
+)
+
+// NormalizeEmail checks that the email is a bare address, and lower cases it
+// so that uniqueness does not depend on how users type it.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
+
+func CheckPasswordPolicy(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < MinPasswordLength || length > MaxPasswordLength {
+		return ErrPasswordTooWeak
+	}
+	return nil
+}
+
+func HashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword(prehashPassword(password), passwordCost)
+	if err != nil {
+		return "", err
+	}
+	return passwordScheme + "$" + string(hashed), nil
+}
+
+// CheckPassword reports whether the password matches a hash made by
+// HashPassword. Malformed hashes never match.
+func CheckPassword(encoded string, password string) bool {
+	fields := strings.SplitN(encoded, "$", 2)
+	if len(fields) != 2 || fields[0] != passwordScheme {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(fields[1]), prehashPassword(password)) == nil
+}
+
+func prehashPassword(password string) []byte {
+	digest := sha256.Sum256([]byte(password))
+	return []byte(base64.StdEncoding.EncodeToString(digest[:]))
+}
+
+// generateResetCode returns a reset code to mail to the user, and the hash
+// of it to store.
+func generateResetCode() (string, string, error) {
+	raw := make([]byte, resetCodeLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	code := base32.StdEncoding.EncodeToString(raw)
+	return code, hashResetCode(code), nil
+}
+
+// Codes are matched case insensitively, since users type them in.
+func hashResetCode(code string) string {
+	digest := sha256.Sum256([]byte(strings.ToUpper(strings.TrimSpace(code))))
+	return hex.EncodeToString(digest[:])
+}
@@ -12,11 +12,9 @@ package profile
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/Sirupsen/logrus"
-	_ "github.com/go-sql-driver/mysql"
-	"github.com/jinzhu/gorm"
-
 	"github.com/theorangechefco/cts/go-shared-libs/cts/logger"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
@@ -28,19 +26,25 @@ import (
 )
 
 type Server struct {
-	DB     gorm.DB
-	Logger *logger.CtsLogger
+	Repository ProfileRepository
+	Logger     *logger.CtsLogger
+	// Mail delivers password reset codes
+	Mail MailSender
+	// How long password reset codes can be used for
+	ResetCodeTTL time.Duration
 }
 
 func (s *Server) GetUUID(ctx context.Context, identifier *pb.Identifier) (*pb.UserId, error) {
-	var user User
-	var query *gorm.DB
+	var user *User
+	var err error
 	var identifierString string
 
 	if identifier.Useridentifier != "" {
 		identifierString = identifier.Useridentifier
+		user, err = s.Repository.FindByUserID(identifierString)
 	} else if identifier.Deviceidentifier != "" {
 		identifierString = identifier.Deviceidentifier
+		user, err = s.Repository.FindByDeviceID(identifierString)
 	} else {
 		errorMsg := fmt.Sprintf("Identifier not specified.")
 		s.Logger.Error(logrus.Fields{
@@ -52,10 +56,9 @@ func (s *Server) GetUUID(ctx context.Context, identifier *pb.Identifier) (*pb.Us
 		return nil, grpc.Errorf(codes.InvalidArgument, errorMsg)
 	}
 
-	query = s.DB.Select("uuid").Where(&User{UserId: identifierString}).First(&user)
-	if query.Error != nil {
-		if query.Error == gorm.RecordNotFound {
-			errorMsg := fmt.Sprintf("User with userid %s not found.", identifier.Useridentifier)
+	if err != nil {
+		if err == ErrProfileNotFound {
+			errorMsg := fmt.Sprintf("User with identifier %s not found.", identifierString)
 			s.Logger.Error(logrus.Fields{
 				"phase": "process",
 				"event": "fetch",
@@ -64,7 +67,7 @@ func (s *Server) GetUUID(ctx context.Context, identifier *pb.Identifier) (*pb.Us
 				errorMsg)
 			return nil, grpc.Errorf(codes.NotFound, errorMsg)
 		} else {
-			errorMsg := fmt.Sprintf("Database query failed: %v", query.Error)
+			errorMsg := fmt.Sprintf("Database query failed: %v", err)
 			s.Logger.Error(logrus.Fields{
 				"phase": "process",
 				"event": "fetch",
@@ -75,6 +78,24 @@ func (s *Server) GetUUID(ctx context.Context, identifier *pb.Identifier) (*pb.Us
 		}
 	}
 
+	// Users who registered an email address or an identity provider account
+	// must sign in with it
+	if required, err := s.requiresSignIn(user.UUID); required || err != nil {
+		errorMsg := fmt.Sprintf("User with identifier %s has an account and must sign in to it.", identifierString)
+		code := codes.PermissionDenied
+		if err != nil {
+			errorMsg = fmt.Sprintf("Database query failed: %v", err)
+			code = codes.Unknown
+		}
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "credentials",
+			"rpc":   "GetUUID"},
+			errorMsg)
+		return nil, grpc.Errorf(code, errorMsg)
+	}
+
 	infoMsg := fmt.Sprintf("Returning UserID %s for user with identifier %s", user.UUID, identifierString)
 	s.Logger.Info(logrus.Fields{
 		"phase": "process",
@@ -87,8 +108,6 @@ func (s *Server) GetUUID(ctx context.Context, identifier *pb.Identifier) (*pb.Us
 }
 
 func (s *Server) GetProfileInfoByUUID(ctx context.Context, userID *pb.UserId) (*pb.Profile, error) {
-	var user User
-
 	if userID.Uuid == "" {
 		errorMsg := fmt.Sprintf("Identifier not specified.")
 		s.Logger.Error(logrus.Fields{
@@ -100,9 +119,9 @@ func (s *Server) GetProfileInfoByUUID(ctx context.Context, userID *pb.UserId) (*
 		return nil, grpc.Errorf(codes.InvalidArgument, errorMsg)
 	}
 
-	query := s.DB.Where(&User{UUID: userID.Uuid}).First(&user)
-	if query.Error != nil {
-		if query.Error == gorm.RecordNotFound {
+	user, err := s.Repository.FindByUUID(userID.Uuid)
+	if err != nil {
+		if err == ErrProfileNotFound {
 			errorMsg := fmt.Sprintf("Profile with id %s not found.", userID.Uuid)
 			s.Logger.Error(logrus.Fields{
 				"phase": "process",
@@ -112,7 +131,7 @@ func (s *Server) GetProfileInfoByUUID(ctx context.Context, userID *pb.UserId) (*
 				errorMsg)
 			return nil, grpc.Errorf(codes.NotFound, errorMsg)
 		} else {
-			errorMsg := fmt.Sprintf("Database query failed: %v", query.Error)
+			errorMsg := fmt.Sprintf("Database query failed: %v", err)
 			s.Logger.Error(logrus.Fields{
 				"phase": "process",
 				"event": "fetch",
@@ -146,6 +165,7 @@ func (s *Server) GetProfileInfoByUUID(ctx context.Context, userID *pb.UserId) (*
 		Activitylevel: pb.ActivityLevel(user.Activitylevel),
 		Mealplan:      pb.MealPlan(user.Mealplan),
 		Weightgoal:    pb.WeightGoal(user.Weightgoal),
+		Role:          pb.Role(user.Role),
 		Dietaryprofile: &pb.DietaryProfile{
 			Omnivore:   user.Omnivore,
 			Vegetarian: user.Vegetarian,
@@ -163,6 +183,35 @@ func (s *Server) GetProfileInfoByUUID(ctx context.Context, userID *pb.UserId) (*
 }
 
 func (s *Server) CreateProfile(ctx context.Context, Profile *pb.Profile) (*pb.UserId, error) {
+	user := newUser(Profile)
+	if err := s.Repository.CreateUser(&user); err != nil {
+		errorMsg := fmt.Sprintf("Could not add record for profile with ID: %v. Error: %v", Profile.Identifier, err)
+		code := codes.Unknown
+		if err == ErrDuplicateProfile {
+			code = codes.AlreadyExists
+		}
+		s.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "createrecord",
+			"tag":   "database",
+			"rpc":   "CreateProfile"},
+			errorMsg)
+		return nil, grpc.Errorf(code, errorMsg)
+	}
+
+	infoMsg := fmt.Sprintf("Successfuly created profile with UUID: %s", user.UUID)
+	s.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "fetch",
+		"tag":   "database",
+		"rpc":   "CreateProfile"},
+		infoMsg)
+
+	return &pb.UserId{Uuid: user.UUID}, nil
+}
+
+// newUser builds a user with a new UUID from a profile.
+func newUser(Profile *pb.Profile) User {
 	user := User{
 		DeviceId:      Profile.Identifier.Deviceidentifier,
 		UserId:        Profile.Identifier.Useridentifier,
@@ -186,36 +235,17 @@ func (s *Server) CreateProfile(ctx context.Context, Profile *pb.Profile) (*pb.Us
 		Soyfree:       Profile.Dietaryrestriction.Soyfree,
 		Lowsodium:     Profile.Dietaryrestriction.Lowsodium,
 	}
-	//TODO(ppietkiewicz): Ensure that duplicate records aren't entered and handle error gracefully
-	query := s.DB.Create(&user)
-	if query.Error != nil {
-		errorMsg := fmt.Sprintf("Could not add record for profile with ID: %v. Error: %v", Profile.Identifier, query.Error)
-		s.Logger.Error(logrus.Fields{
-			"phase": "process",
-			"event": "createrecord",
-			"tag":   "database",
-			"rpc":   "CreateProfile"},
-			errorMsg)
-		return nil, grpc.Errorf(codes.Unknown, errorMsg)
+	if target, err := ComputeCalorieTarget(ProfileBiometrics(Profile), time.Now().Year()); err == nil {
+		user.Mealplan = int32(target.Mealplan)
+		user.Dailycalories = target.Dailycalories
 	}
-
-	infoMsg := fmt.Sprintf("Successfuly created profile with UUID: %s", user.UUID)
-	s.Logger.Info(logrus.Fields{
-		"phase": "process",
-		"event": "fetch",
-		"tag":   "database",
-		"rpc":   "CreateProfile"},
-		infoMsg)
-
-	return &pb.UserId{Uuid: user.UUID}, nil
+	return user
 }
 
 func (s *Server) SetProfileInfo(ctx context.Context, profileUpdateReq *pb.ProfileUpdateRequest) (*pb.Response, error) {
-	var user User
-
-	query := s.DB.Where(&User{UUID: profileUpdateReq.Id.Uuid}).First(&user)
-	if query.Error != nil {
-		if query.Error == gorm.RecordNotFound {
+	user, err := s.Repository.FindByUUID(profileUpdateReq.Id.Uuid)
+	if err != nil {
+		if err == ErrProfileNotFound {
 			errorMsg := fmt.Sprintf("Profile with ID %s not found.", profileUpdateReq.Id.Uuid)
 			s.Logger.Error(logrus.Fields{
 				"phase": "process",
@@ -225,7 +255,7 @@ func (s *Server) SetProfileInfo(ctx context.Context, profileUpdateReq *pb.Profil
 				errorMsg)
 			return nil, grpc.Errorf(codes.NotFound, errorMsg)
 		} else {
-			errorMsg := fmt.Sprintf("Database query failed: %v", query.Error)
+			errorMsg := fmt.Sprintf("Database query failed: %v", err)
 			s.Logger.Error(logrus.Fields{
 				"phase": "process",
 				"event": "fetch",
@@ -236,30 +266,41 @@ func (s *Server) SetProfileInfo(ctx context.Context, profileUpdateReq *pb.Profil
 		}
 	}
 
-	// Not updating deviceid, useridentifier, id or uuid
-	userMap := map[string]interface{}{
-		"Firstname":     profileUpdateReq.Profile.Firstname,
-		"Birthyear":     profileUpdateReq.Profile.Birthyear,
-		"Gender":        int32(profileUpdateReq.Profile.Gender),
-		"Heightcm":      profileUpdateReq.Profile.Heightcm,
-		"Weightkg":      profileUpdateReq.Profile.Weightkg,
-		"Goalweightkg":  profileUpdateReq.Profile.Goalweightkg,
-		"Activitylevel": int32(profileUpdateReq.Profile.Activitylevel),
-		"Mealplan":      int32(profileUpdateReq.Profile.Mealplan),
-		"Weightgoal":    int32(profileUpdateReq.Profile.Weightgoal),
-		"Omnivore":      profileUpdateReq.Profile.Dietaryprofile.Omnivore,
-		"Vegetarian":    profileUpdateReq.Profile.Dietaryprofile.Vegetarian,
-		"Vegan":         profileUpdateReq.Profile.Dietaryprofile.Vegan,
-		"Raw":           profileUpdateReq.Profile.Dietaryprofile.Raw,
-		"Glutenfree":    profileUpdateReq.Profile.Dietaryrestriction.Glutenfree,
-		"Nutfree":       profileUpdateReq.Profile.Dietaryrestriction.Nutfree,
-		"Dairyfree":     profileUpdateReq.Profile.Dietaryrestriction.Dairyfree,
-		"Soyfree":       profileUpdateReq.Profile.Dietaryrestriction.Soyfree,
-		"Lowsodium":     profileUpdateReq.Profile.Dietaryrestriction.Lowsodium,
+	// Not updating deviceid, useridentifier, id, uuid or role
+	user.Firstname = profileUpdateReq.Profile.Firstname
+	user.Birthyear = profileUpdateReq.Profile.Birthyear
+	user.Gender = int32(profileUpdateReq.Profile.Gender)
+	user.Heightcm = profileUpdateReq.Profile.Heightcm
+	user.Weightkg = profileUpdateReq.Profile.Weightkg
+	user.Goalweightkg = profileUpdateReq.Profile.Goalweightkg
+	user.Activitylevel = int32(profileUpdateReq.Profile.Activitylevel)
+	user.Mealplan = int32(profileUpdateReq.Profile.Mealplan)
+	user.Weightgoal = int32(profileUpdateReq.Profile.Weightgoal)
+	user.Omnivore = profileUpdateReq.Profile.Dietaryprofile.Omnivore
+	user.Vegetarian = profileUpdateReq.Profile.Dietaryprofile.Vegetarian
+	user.Vegan = profileUpdateReq.Profile.Dietaryprofile.Vegan
+	user.Raw = profileUpdateReq.Profile.Dietaryprofile.Raw
+	user.Glutenfree = profileUpdateReq.Profile.Dietaryrestriction.Glutenfree
+	user.Nutfree = profileUpdateReq.Profile.Dietaryrestriction.Nutfree
+	user.Dairyfree = profileUpdateReq.Profile.Dietaryrestriction.Dairyfree
+	user.Soyfree = profileUpdateReq.Profile.Dietaryrestriction.Soyfree
+	user.Lowsodium = profileUpdateReq.Profile.Dietaryrestriction.Lowsodium
+
+	// Keep the meal plan in line with the updated biometrics
+	target, targetErr := ComputeCalorieTarget(ProfileBiometrics(profileUpdateReq.Profile), time.Now().Year())
+	if targetErr == nil {
+		user.Mealplan = int32(target.Mealplan)
+		user.Dailycalories = target.Dailycalories
+	} else {
+		s.Logger.Info(logrus.Fields{
+			"phase": "process",
+			"event": "compute",
+			"tag":   "nutrition",
+			"rpc":   "SetProfileInfo"},
+			fmt.Sprintf("Not recomputing meal plan for profile with ID %s: %v", profileUpdateReq.Id.Uuid, targetErr))
 	}
-	query = s.DB.Model(&user).Updates(userMap)
-	if query.Error != nil {
-		errorMsg := fmt.Sprintf("Could not update profile with ID %s., Error: %v", profileUpdateReq.Id.Uuid, query.Error)
+	if err := s.Repository.UpdateUser(user); err != nil {
+		errorMsg := fmt.Sprintf("Could not update profile with ID %s., Error: %v", profileUpdateReq.Id.Uuid, err)
 		s.Logger.Error(logrus.Fields{
 			"phase": "process",
 			"event": "update",
@@ -0,0 +1,155 @@
+/*
+// ----------------------------------------------------------------------------
+// profile_test.go
+// Countertop Profile Microservice Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package profile_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	pb "github.com/theorangechefco/cts/go-protos"
+	"github.com/theorangechefco/cts/go-shared-libs/cts/logger"
+	profile "github.com/theorangechefco/cts/profile"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func newTestServer() *profile.Server {
+	return &profile.Server{
+		Repository:   profile.NewMemoryProfileRepository(),
+		Logger:       logger.NewLogger("test", "", 8080, false, logrus.ErrorLevel),
+		Mail:         &recordingMailSender{},
+		ResetCodeTTL: time.Hour,
+	}
+}
+
+func testProfile() *pb.Profile {
+	return &pb.Profile{
+		Identifier:     &pb.Identifier{Deviceidentifier: "abc123"},
+		Firstname:      "John",
+		Birthyear:      1985,
+		Gender:         pb.Gender_MALE,
+		Heightcm:       185,
+		Weightkg:       90,
+		Goalweightkg:   80,
+		Activitylevel:  pb.ActivityLevel_SEDENTARY,
+		Weightgoal:     pb.WeightGoal_LOSE,
+		Dietaryprofile: &pb.DietaryProfile{Omnivore: true},
+		Dietaryrestriction: &pb.DietaryRestriction{
+			Soyfree: true,
+		},
+	}
+}
+
+func TestCreateAndGetProfile(t *testing.T) {
+	server := newTestServer()
+	ctx := context.Background()
+
+	userID, err := server.CreateProfile(ctx, testProfile())
+	if err != nil {
+		t.Fatalf("CreateProfile(_) = _, %v", err)
+	}
+
+	found, err := server.GetUUID(ctx, &pb.Identifier{Deviceidentifier: "abc123"})
+	if err != nil || found.Uuid != userID.Uuid {
+		t.Errorf("GetUUID(abc123) = %v, %v, want %s", found, err, userID.Uuid)
+	}
+
+	stored, err := server.GetProfileInfoByUUID(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetProfileInfoByUUID(%s) = _, %v", userID.Uuid, err)
+	}
+	if stored.Firstname != "John" || stored.Identifier.Deviceidentifier != "abc123" || !stored.Dietaryrestriction.Soyfree {
+		t.Errorf("GetProfileInfoByUUID(%s) = %v", userID.Uuid, stored)
+	}
+
+	if _, err := server.CreateProfile(ctx, testProfile()); grpc.Code(err) != codes.AlreadyExists {
+		t.Errorf("CreateProfile(duplicate device) = _, %v, want %v", err, codes.AlreadyExists)
+	}
+}
+
+func TestSetProfileInfo(t *testing.T) {
+	server := newTestServer()
+	ctx := context.Background()
+
+	userID, err := server.CreateProfile(ctx, testProfile())
+	if err != nil {
+		t.Fatalf("CreateProfile(_) = _, %v", err)
+	}
+
+	update := testProfile()
+	update.Firstname = "Johnny"
+	update.Weightkg = 85
+	update.Identifier = &pb.Identifier{Deviceidentifier: "other-device"}
+	if _, err := server.SetProfileInfo(ctx, &pb.ProfileUpdateRequest{Profile: update, Id: userID}); err != nil {
+		t.Fatalf("SetProfileInfo(_) = _, %v", err)
+	}
+
+	stored, err := server.GetProfileInfoByUUID(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetProfileInfoByUUID(%s) = _, %v", userID.Uuid, err)
+	}
+	if stored.Firstname != "Johnny" || stored.Weightkg != 85 {
+		t.Errorf("GetProfileInfoByUUID(%s) = %v, want the updated profile", userID.Uuid, stored)
+	}
+	if stored.Identifier.Deviceidentifier != "abc123" {
+		t.Errorf("SetProfileInfo(_) changed the device identifier to %s", stored.Identifier.Deviceidentifier)
+	}
+}
+
+func TestUpdateUserKeepsIdentifiersAndRole(t *testing.T) {
+	server := newTestServer()
+	ctx := context.Background()
+
+	userID, err := server.CreateProfile(ctx, testProfile())
+	if err != nil {
+		t.Fatalf("CreateProfile(_) = _, %v", err)
+	}
+	user, err := server.Repository.FindByUUID(userID.Uuid)
+	if err != nil {
+		t.Fatalf("FindByUUID(%s) = _, %v", userID.Uuid, err)
+	}
+	user.Firstname = "Johnny"
+	user.DeviceId = ""
+	user.UserId = "johnny"
+	user.Role = int32(pb.Role_ADMIN)
+	if err := server.Repository.UpdateUser(user); err != nil {
+		t.Fatalf("UpdateUser(_) = %v", err)
+	}
+
+	stored, err := server.Repository.FindByUUID(userID.Uuid)
+	if err != nil {
+		t.Fatalf("FindByUUID(%s) = _, %v", userID.Uuid, err)
+	}
+	if stored.Firstname != "Johnny" || stored.DeviceId != "abc123" || stored.UserId != "" || stored.Role != int32(pb.Role_USER) {
+		t.Errorf("FindByUUID(%s) = %+v, want only the profile fields updated", userID.Uuid, stored)
+	}
+}
+
+func TestMissingProfile(t *testing.T) {
+	server := newTestServer()
+	ctx := context.Background()
+
+	if _, err := server.GetUUID(ctx, &pb.Identifier{Useridentifier: "nobody"}); grpc.Code(err) != codes.NotFound {
+		t.Errorf("GetUUID(nobody) = _, %v, want %v", err, codes.NotFound)
+	}
+	if _, err := server.GetUUID(ctx, &pb.Identifier{}); grpc.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetUUID(empty) = _, %v, want %v", err, codes.InvalidArgument)
+	}
+	missing := &pb.UserId{Uuid: "d17eaf65-244a-4913-83d8-1583bb3cbbfd"}
+	if _, err := server.GetProfileInfoByUUID(ctx, missing); grpc.Code(err) != codes.NotFound {
+		t.Errorf("GetProfileInfoByUUID(missing) = _, %v, want %v", err, codes.NotFound)
+	}
+	if _, err := server.SetProfileInfo(ctx, &pb.ProfileUpdateRequest{Profile: testProfile(), Id: missing}); grpc.Code(err) != codes.NotFound {
+		t.Errorf("SetProfileInfo(missing) = _, %v, want %v", err, codes.NotFound)
+	}
+}
@@ -0,0 +1,232 @@
+/*
+// ----------------------------------------------------------------------------
+// repository.go
+// Countertop Profile Repository
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package profile
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	ErrProfileNotFound    = errors.New("profile not found")
+	ErrDuplicateProfile   = errors.New("profile with the same identifier already exists")
+	ErrCredentialNotFound = errors.New("credential not found")
+	ErrDuplicateEmail     = errors.New("email address already registered")
+
+	ErrFederatedIdentityNotFound  = errors.New("federated identity not found")
+	ErrDuplicateFederatedIdentity = errors.New("federated identity already linked")
+)
+
+// MySQL error number for unique key violations
+const mysqlDuplicateEntry = 1062
+
+// ProfileRepository stores user profiles. Lookups return ErrProfileNotFound
+// when no user matches.
+type ProfileRepository interface {
+	FindByUserID(userID string) (*User, error)
+	FindByDeviceID(deviceID string) (*User, error)
+	FindByUUID(uuid string) (*User, error)
+	// CreateUser stores a new user, assigning its ID and timestamps.
+	CreateUser(user *User) error
+	// UpdateUser stores the profile fields of a user previously returned by
+	// one of the lookups. Identifiers and role are left as stored, so that
+	// concurrent changes to them are not undone.
+	UpdateUser(user *User) error
+
+	// Credential lookups return ErrCredentialNotFound when no user matches.
+	// Emails are stored normalized, see NormalizeEmail.
+	FindCredentialByEmail(email string) (*Credential, error)
+	FindCredentialByUUID(uuid string) (*Credential, error)
+	// CreateCredential returns ErrDuplicateEmail if the email address is
+	// taken, or ErrDuplicateProfile if the user already has a credential.
+	CreateCredential(credential *Credential) error
+	UpdateCredential(credential *Credential) error
+	DeleteCredential(credential *Credential) error
+
+	// Federated identity lookups return ErrFederatedIdentityNotFound when no
+	// user matches.
+	FindFederatedIdentity(issuer string, subject string) (*FederatedIdentity, error)
+	FindFederatedIdentityByUUID(uuid string) (*FederatedIdentity, error)
+	// CreateFederatedIdentity returns ErrDuplicateFederatedIdentity if the
+	// provider account is already linked to a user.
+	CreateFederatedIdentity(identity *FederatedIdentity) error
+	DeleteFederatedIdentity(identity *FederatedIdentity) error
+}
+
+//
+// MySQL backed repository
+//
+
+type MySQLProfileRepository struct {
+	DB gorm.DB
+}
+
+// Blank identifiers match no user; gorm would drop them from the query and
+// match any user.
+func (m *MySQLProfileRepository) FindByUserID(userID string) (*User, error) {
+	if userID == "" {
+		return nil, ErrProfileNotFound
+	}
+	return m.find(&User{UserId: userID})
+}
+
+func (m *MySQLProfileRepository) FindByDeviceID(deviceID string) (*User, error) {
+	if deviceID == "" {
+		return nil, ErrProfileNotFound
+	}
+	return m.find(&User{DeviceId: deviceID})
+}
+
+func (m *MySQLProfileRepository) FindByUUID(uuid string) (*User, error) {
+	if uuid == "" {
+		return nil, ErrProfileNotFound
+	}
+	return m.find(&User{UUID: uuid})
+}
+
+func (m *MySQLProfileRepository) find(where *User) (*User, error) {
+	var user User
+	query := m.DB.Where(where).First(&user)
+	if query.Error == gorm.RecordNotFound {
+		return nil, ErrProfileNotFound
+	}
+	if query.Error != nil {
+		return nil, query.Error
+	}
+	return &user, nil
+}
+
+func (m *MySQLProfileRepository) CreateUser(user *User) error {
+	err := m.DB.Create(user).Error
+	if isDuplicateEntry(err) {
+		return ErrDuplicateProfile
+	}
+	return err
+}
+
+func (m *MySQLProfileRepository) UpdateUser(user *User) error {
+	// Not updating deviceid, useridentifier, role, id or uuid
+	userMap := map[string]interface{}{
+		"Firstname":     user.Firstname,
+		"Birthyear":     user.Birthyear,
+		"Gender":        user.Gender,
+		"Heightcm":      user.Heightcm,
+		"Weightkg":      user.Weightkg,
+		"Goalweightkg":  user.Goalweightkg,
+		"Activitylevel": user.Activitylevel,
+		"Mealplan":      user.Mealplan,
+		"Dailycalories": user.Dailycalories,
+		"Weightgoal":    user.Weightgoal,
+		"Omnivore":      user.Omnivore,
+		"Vegetarian":    user.Vegetarian,
+		"Vegan":         user.Vegan,
+		"Raw":           user.Raw,
+		"Glutenfree":    user.Glutenfree,
+		"Nutfree":       user.Nutfree,
+		"Dairyfree":     user.Dairyfree,
+		"Soyfree":       user.Soyfree,
+		"Lowsodium":     user.Lowsodium,
+	}
+	return m.DB.Model(user).Updates(userMap).Error
+}
+
+func (m *MySQLProfileRepository) FindCredentialByEmail(email string) (*Credential, error) {
+	if email == "" {
+		return nil, ErrCredentialNotFound
+	}
+	return m.findCredential(&Credential{Email: email})
+}
+
+func (m *MySQLProfileRepository) FindCredentialByUUID(uuid string) (*Credential, error) {
+	if uuid == "" {
+		return nil, ErrCredentialNotFound
+	}
+	return m.findCredential(&Credential{UUID: uuid})
+}
+
+func (m *MySQLProfileRepository) findCredential(where *Credential) (*Credential, error) {
+	var credential Credential
+	query := m.DB.Where(where).First(&credential)
+	if query.Error == gorm.RecordNotFound {
+		return nil, ErrCredentialNotFound
+	}
+	if query.Error != nil {
+		return nil, query.Error
+	}
+	return &credential, nil
+}
+
+func (m *MySQLProfileRepository) CreateCredential(credential *Credential) error {
+	if _, err := m.FindCredentialByUUID(credential.UUID); err == nil {
+		return ErrDuplicateProfile
+	}
+	err := m.DB.Create(credential).Error
+	if isDuplicateEntry(err) {
+		return ErrDuplicateEmail
+	}
+	return err
+}
+
+func (m *MySQLProfileRepository) UpdateCredential(credential *Credential) error {
+	err := m.DB.Save(credential).Error
+	if isDuplicateEntry(err) {
+		return ErrDuplicateEmail
+	}
+	return err
+}
+
+func (m *MySQLProfileRepository) DeleteCredential(credential *Credential) error {
+	return m.DB.Delete(credential).Error
+}
+
+func (m *MySQLProfileRepository) FindFederatedIdentity(issuer string, subject string) (*FederatedIdentity, error) {
+	if issuer == "" || subject == "" {
+		return nil, ErrFederatedIdentityNotFound
+	}
+	return m.findFederatedIdentity(&FederatedIdentity{Issuer: issuer, Subject: subject})
+}
+
+func (m *MySQLProfileRepository) FindFederatedIdentityByUUID(uuid string) (*FederatedIdentity, error) {
+	if uuid == "" {
+		return nil, ErrFederatedIdentityNotFound
+	}
+	return m.findFederatedIdentity(&FederatedIdentity{UUID: uuid})
+}
+
+func (m *MySQLProfileRepository) findFederatedIdentity(where *FederatedIdentity) (*FederatedIdentity, error) {
+	var identity FederatedIdentity
+	query := m.DB.Where(where).First(&identity)
+	if query.Error == gorm.RecordNotFound {
+		return nil, ErrFederatedIdentityNotFound
+	}
+	if query.Error != nil {
+		return nil, query.Error
+	}
+	return &identity, nil
+}
+
+func (m *MySQLProfileRepository) CreateFederatedIdentity(identity *FederatedIdentity) error {
+	err := m.DB.Create(identity).Error
+	if isDuplicateEntry(err) {
+		return ErrDuplicateFederatedIdentity
+	}
+	return err
+}
+
+func (m *MySQLProfileRepository) DeleteFederatedIdentity(identity *FederatedIdentity) error {
+	return m.DB.Delete(identity).Error
+}
+
+func isDuplicateEntry(err error) bool {
+	mysqlErr, ok := err.(*mysql.MySQLError)
+	return ok && mysqlErr.Number == mysqlDuplicateEntry
+}
@@ -21,13 +21,14 @@ import (
 )
 
 var (
-	dbHost = flag.String("db_host", "admin:admin@tcp([:::::::]:3306)/profile?charset=utf8", "DB connection string")
+	dbHost     = flag.String("db_host", "admin:admin@tcp([:::::::]:3306)/profile?charset=utf8", "DB connection string")
+	grantAdmin = flag.String("grant_admin", "", "UUID of a user to grant the admin role to after migration")
 )
 
 type User struct {
 	ID            uint   `gorm:"primary_key"`
 	UUID          string `sql:"unique;not null;index"`
-	DeviceId      string `sql:"unique;not null;index"`
+	DeviceId      string `sql:"unique;default: null;index"`
 	UserId        string `sql:"unique;default: null;index"`
 	Firstname     string
 	Birthyear     int32
@@ -37,7 +38,9 @@ type User struct {
 	Goalweightkg  float32
 	Activitylevel int32
 	Mealplan      int32
+	Dailycalories int32
 	Weightgoal    int32
+	Role          int32
 	Omnivore      bool `sql:"default: 1"`
 	Vegetarian    bool `sql:"default: 0"`
 	Vegan         bool `sql:"default: 0"`
@@ -51,6 +54,30 @@ type User struct {
 	UpdatedAt     time.Time
 }
 
+type Credential struct {
+	ID               uint   `gorm:"primary_key"`
+	UUID             string `sql:"unique;not null;index"`
+	Email            string `sql:"unique;not null;index"`
+	PasswordHash     string `sql:"not null"`
+	ResetCodeHash    string
+	ResetCodeExpires time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+type FederatedIdentity struct {
+	ID        uint   `gorm:"primary_key"`
+	UUID      string `sql:"not null;index"`
+	Issuer    string `sql:"not null;unique_index:uix_federated_identity_issuer_subject"`
+	Subject   string `sql:"not null;unique_index:uix_federated_identity_issuer_subject"`
+	Email     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Matches pb.Role_ADMIN; the bootstrap tool does not depend on go-protos.
+const adminRole = 1
+
 func main() {
 	flag.Parse()
 
@@ -64,6 +91,21 @@ func main() {
 	time.Sleep(time.Duration(10) * time.Second)
 	fmt.Println("Running migration...")
 	db.SingularTable(true)
-	db.Set("gorm:table_options", "ENGINE=InnoDB").AutoMigrate(&User{})
+	db.Set("gorm:table_options", "ENGINE=InnoDB").AutoMigrate(&User{}, &Credential{}, &FederatedIdentity{})
+	// AutoMigrate only adds columns. Profiles made by signing in may have no
+	// device identifier, which older tables did not allow.
+	if err := db.Exec("ALTER TABLE user MODIFY device_id varchar(255) DEFAULT NULL").Error; err != nil {
+		fmt.Printf("cannot migrate user.device_id: %v\n", err)
+		os.Exit(-1)
+	}
 	fmt.Println("Database migration complete!")
+
+	if *grantAdmin != "" {
+		query := db.Model(&User{}).Where(&User{UUID: *grantAdmin}).Update("Role", adminRole)
+		if query.Error != nil || query.RowsAffected == 0 {
+			fmt.Printf("cannot grant admin role to user %s: %v\n", *grantAdmin, query.Error)
+			os.Exit(-1)
+		}
+		fmt.Printf("Granted admin role to user %s\n", *grantAdmin)
+	}
 }
@@ -0,0 +1,435 @@
+/*
+// ----------------------------------------------------------------------------
+// authoring.go
+// Countertop Recipe Microservice Recipe & Recipe Pack Authoring
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/Sirupsen/logrus"
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+// Actions recorded in the version history.
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionDelete    = "delete"
+	ActionPublish   = "publish"
+	ActionUnpublish = "unpublish"
+	ActionRollback  = "rollback"
+)
+
+var ErrMissingEditor = errors.New("editor not specified")
+
+// Number of times recording a version is retried when a concurrent write
+// takes the same version number.
+const versionAttempts = 5
+
+// versionIndex keeps version numbers unique per document, so concurrent
+// writes cannot record the same version.
+var versionIndex = mgo.Index{
+	Key:    []string{"kind", "id", "version"},
+	Unique: true,
+}
+
+// EnsureIndexes creates the indexes authoring relies on. It is safe to call
+// on every startup.
+func EnsureIndexes(session *mgo.Session) error {
+	return session.DB("recipes").C("recipeversions").EnsureIndex(versionIndex)
+}
+
+// authoredDocument adapts recipes and recipe packs to the shared authoring
+// code below.
+type authoredDocument interface {
+	documentID() string
+	validate() error
+	isDraft() bool
+	setDraft(draft bool)
+	// value returns the underlying protobuf message, as stored in MongoDB.
+	value() interface{}
+	attachTo(version *pb.RecipeVersion)
+}
+
+type recipeDocument struct{ *pb.Recipe }
+
+func (d recipeDocument) documentID() string                 { return d.Id }
+func (d recipeDocument) validate() error                    { return ValidateRecipe(d.Recipe) }
+func (d recipeDocument) isDraft() bool                      { return d.Draft }
+func (d recipeDocument) setDraft(draft bool)                { d.Draft = draft }
+func (d recipeDocument) value() interface{}                 { return d.Recipe }
+func (d recipeDocument) attachTo(version *pb.RecipeVersion) { version.Recipe = d.Recipe }
+
+type packDocument struct{ *pb.RecipePack }
+
+func (d packDocument) documentID() string                 { return d.Id }
+func (d packDocument) validate() error                    { return ValidateRecipePack(d.RecipePack) }
+func (d packDocument) isDraft() bool                      { return d.Draft }
+func (d packDocument) setDraft(draft bool)                { d.Draft = draft }
+func (d packDocument) value() interface{}                 { return d.RecipePack }
+func (d packDocument) attachTo(version *pb.RecipeVersion) { version.Recipepack = d.RecipePack }
+
+type documentKind struct {
+	name       string
+	collection string
+	empty      func() authoredDocument
+	// fromVersion returns the document snapshot held by a version entry, or
+	// nil if the entry has none.
+	fromVersion func(version *pb.RecipeVersion) authoredDocument
+}
+
+var recipeKind = documentKind{
+	name:       "recipe",
+	collection: "recipes",
+	empty:      func() authoredDocument { return recipeDocument{new(pb.Recipe)} },
+	fromVersion: func(version *pb.RecipeVersion) authoredDocument {
+		if version.Recipe == nil {
+			return nil
+		}
+		return recipeDocument{version.Recipe}
+	},
+}
+
+var packKind = documentKind{
+	name:       "recipepack",
+	collection: "recipepacks",
+	empty:      func() authoredDocument { return packDocument{new(pb.RecipePack)} },
+	fromVersion: func(version *pb.RecipeVersion) authoredDocument {
+		if version.Recipepack == nil {
+			return nil
+		}
+		return packDocument{version.Recipepack}
+	},
+}
+
+func (r *Server) CreateRecipe(ctx context.Context, writeRequest *pb.RecipeWriteRequest) (*pb.RecipeVersion, error) {
+	if writeRequest.Recipe == nil {
+		return nil, r.invalidAuthoringRequest("CreateRecipe", errors.New("recipe not specified"))
+	}
+	return r.createDocument(recipeKind, recipeDocument{writeRequest.Recipe}, writeRequest.Editor, "CreateRecipe")
+}
+
+func (r *Server) UpdateRecipe(ctx context.Context, writeRequest *pb.RecipeWriteRequest) (*pb.RecipeVersion, error) {
+	if writeRequest.Recipe == nil {
+		return nil, r.invalidAuthoringRequest("UpdateRecipe", errors.New("recipe not specified"))
+	}
+	return r.updateDocument(recipeKind, recipeDocument{writeRequest.Recipe}, writeRequest.Editor, "UpdateRecipe")
+}
+
+func (r *Server) DeleteRecipe(ctx context.Context, authoringRequest *pb.AuthoringRequest) (*pb.RecipeVersion, error) {
+	return r.deleteDocument(recipeKind, authoringRequest, "DeleteRecipe")
+}
+
+func (r *Server) PublishRecipe(ctx context.Context, publishRequest *pb.PublishRequest) (*pb.RecipeVersion, error) {
+	return r.publishDocument(recipeKind, publishRequest, "PublishRecipe")
+}
+
+func (r *Server) RollbackRecipe(ctx context.Context, rollbackRequest *pb.RollbackRequest) (*pb.RecipeVersion, error) {
+	return r.rollbackDocument(recipeKind, rollbackRequest, "RollbackRecipe")
+}
+
+func (r *Server) GetRecipeHistory(ctx context.Context, authoringRequest *pb.AuthoringRequest) (*pb.RecipeHistory, error) {
+	return r.documentHistory(recipeKind, authoringRequest.Id, "GetRecipeHistory")
+}
+
+func (r *Server) CreateRecipePack(ctx context.Context, writeRequest *pb.RecipePackWriteRequest) (*pb.RecipeVersion, error) {
+	if writeRequest.Recipepack == nil {
+		return nil, r.invalidAuthoringRequest("CreateRecipePack", errors.New("recipe pack not specified"))
+	}
+	return r.createDocument(packKind, packDocument{writeRequest.Recipepack}, writeRequest.Editor, "CreateRecipePack")
+}
+
+func (r *Server) UpdateRecipePack(ctx context.Context, writeRequest *pb.RecipePackWriteRequest) (*pb.RecipeVersion, error) {
+	if writeRequest.Recipepack == nil {
+		return nil, r.invalidAuthoringRequest("UpdateRecipePack", errors.New("recipe pack not specified"))
+	}
+	return r.updateDocument(packKind, packDocument{writeRequest.Recipepack}, writeRequest.Editor, "UpdateRecipePack")
+}
+
+func (r *Server) DeleteRecipePack(ctx context.Context, authoringRequest *pb.AuthoringRequest) (*pb.RecipeVersion, error) {
+	return r.deleteDocument(packKind, authoringRequest, "DeleteRecipePack")
+}
+
+func (r *Server) PublishRecipePack(ctx context.Context, publishRequest *pb.PublishRequest) (*pb.RecipeVersion, error) {
+	return r.publishDocument(packKind, publishRequest, "PublishRecipePack")
+}
+
+func (r *Server) RollbackRecipePack(ctx context.Context, rollbackRequest *pb.RollbackRequest) (*pb.RecipeVersion, error) {
+	return r.rollbackDocument(packKind, rollbackRequest, "RollbackRecipePack")
+}
+
+func (r *Server) GetRecipePackHistory(ctx context.Context, authoringRequest *pb.AuthoringRequest) (*pb.RecipeHistory, error) {
+	return r.documentHistory(packKind, authoringRequest.Id, "GetRecipePackHistory")
+}
+
+// New documents are stored as drafts and only served once published.
+func (r *Server) createDocument(kind documentKind, document authoredDocument, editor string, rpc string) (*pb.RecipeVersion, error) {
+	if err := firstError(document.validate(), requireEditor(editor)); err != nil {
+		return nil, r.invalidAuthoringRequest(rpc, err)
+	}
+
+	session, err := r.pingedSession(rpc)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	c := session.DB("recipes").C(kind.collection)
+	existing, err := c.Find(bson.M{"id": document.documentID()}).Count()
+	if err != nil {
+		return nil, r.authoringError(rpc, "create", codes.Unknown, fmt.Errorf("cannot look up %s %s: %v", kind.name, document.documentID(), err))
+	}
+	if existing > 0 {
+		return nil, r.authoringError(rpc, "create", codes.AlreadyExists, fmt.Errorf("%s %s already exists", kind.name, document.documentID()))
+	}
+
+	document.setDraft(true)
+	if err := c.Insert(document.value()); err != nil {
+		return nil, r.authoringError(rpc, "create", codes.Unknown, fmt.Errorf("cannot insert %s %s: %v", kind.name, document.documentID(), err))
+	}
+	return r.recordVersion(session, kind, document.documentID(), document, editor, ActionCreate, rpc)
+}
+
+// Updates replace the stored document but keep its published state.
+func (r *Server) updateDocument(kind documentKind, document authoredDocument, editor string, rpc string) (*pb.RecipeVersion, error) {
+	if err := firstError(document.validate(), requireEditor(editor)); err != nil {
+		return nil, r.invalidAuthoringRequest(rpc, err)
+	}
+
+	session, err := r.pingedSession(rpc)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	current, err := r.loadDocument(session, kind, document.documentID(), rpc)
+	if err != nil {
+		return nil, err
+	}
+
+	document.setDraft(current.isDraft())
+	if err := session.DB("recipes").C(kind.collection).Update(bson.M{"id": document.documentID()}, document.value()); err != nil {
+		return nil, r.authoringError(rpc, "update", codes.Unknown, fmt.Errorf("cannot update %s %s: %v", kind.name, document.documentID(), err))
+	}
+	return r.recordVersion(session, kind, document.documentID(), document, editor, ActionUpdate, rpc)
+}
+
+// The deleted document is kept in the version history so it can be rolled
+// back.
+func (r *Server) deleteDocument(kind documentKind, authoringRequest *pb.AuthoringRequest, rpc string) (*pb.RecipeVersion, error) {
+	if err := firstError(requireID(kind, authoringRequest.Id), requireEditor(authoringRequest.Editor)); err != nil {
+		return nil, r.invalidAuthoringRequest(rpc, err)
+	}
+
+	session, err := r.pingedSession(rpc)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	current, err := r.loadDocument(session, kind, authoringRequest.Id, rpc)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := session.DB("recipes").C(kind.collection).Remove(bson.M{"id": authoringRequest.Id}); err != nil {
+		return nil, r.authoringError(rpc, "delete", codes.Unknown, fmt.Errorf("cannot delete %s %s: %v", kind.name, authoringRequest.Id, err))
+	}
+	return r.recordVersion(session, kind, authoringRequest.Id, current, authoringRequest.Editor, ActionDelete, rpc)
+}
+
+func (r *Server) publishDocument(kind documentKind, publishRequest *pb.PublishRequest, rpc string) (*pb.RecipeVersion, error) {
+	if err := firstError(requireID(kind, publishRequest.Id), requireEditor(publishRequest.Editor)); err != nil {
+		return nil, r.invalidAuthoringRequest(rpc, err)
+	}
+
+	session, err := r.pingedSession(rpc)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	current, err := r.loadDocument(session, kind, publishRequest.Id, rpc)
+	if err != nil {
+		return nil, err
+	}
+
+	action := ActionPublish
+	if !publishRequest.Publish {
+		action = ActionUnpublish
+	}
+	current.setDraft(!publishRequest.Publish)
+	if err := session.DB("recipes").C(kind.collection).Update(bson.M{"id": publishRequest.Id}, bson.M{"$set": bson.M{"draft": current.isDraft()}}); err != nil {
+		return nil, r.authoringError(rpc, action, codes.Unknown, fmt.Errorf("cannot %s %s %s: %v", action, kind.name, publishRequest.Id, err))
+	}
+	return r.recordVersion(session, kind, publishRequest.Id, current, publishRequest.Editor, action, rpc)
+}
+
+// Rolling back restores the document as it was recorded in the requested
+// version, recreating it if it has since been deleted. The rollback itself
+// becomes the newest version.
+func (r *Server) rollbackDocument(kind documentKind, rollbackRequest *pb.RollbackRequest, rpc string) (*pb.RecipeVersion, error) {
+	if err := firstError(requireID(kind, rollbackRequest.Id), requireEditor(rollbackRequest.Editor)); err != nil {
+		return nil, r.invalidAuthoringRequest(rpc, err)
+	}
+
+	session, err := r.pingedSession(rpc)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	version := new(pb.RecipeVersion)
+	err = session.DB("recipes").C("recipeversions").Find(bson.M{"kind": kind.name, "id": rollbackRequest.Id, "version": rollbackRequest.Version}).One(version)
+	if err == mgo.ErrNotFound {
+		return nil, r.authoringError(rpc, "rollback", codes.NotFound, fmt.Errorf("version %d of %s %s not found", rollbackRequest.Version, kind.name, rollbackRequest.Id))
+	}
+	if err != nil {
+		return nil, r.authoringError(rpc, "rollback", codes.Unknown, fmt.Errorf("cannot fetch version %d of %s %s: %v", rollbackRequest.Version, kind.name, rollbackRequest.Id, err))
+	}
+
+	document := kind.fromVersion(version)
+	if document == nil {
+		return nil, r.authoringError(rpc, "rollback", codes.FailedPrecondition, fmt.Errorf("version %d of %s %s has no snapshot", rollbackRequest.Version, kind.name, rollbackRequest.Id))
+	}
+	if _, err := session.DB("recipes").C(kind.collection).Upsert(bson.M{"id": rollbackRequest.Id}, document.value()); err != nil {
+		return nil, r.authoringError(rpc, "rollback", codes.Unknown, fmt.Errorf("cannot restore %s %s: %v", kind.name, rollbackRequest.Id, err))
+	}
+	return r.recordVersion(session, kind, rollbackRequest.Id, document, rollbackRequest.Editor, ActionRollback, rpc)
+}
+
+func (r *Server) documentHistory(kind documentKind, id string, rpc string) (*pb.RecipeHistory, error) {
+	if err := requireID(kind, id); err != nil {
+		return nil, r.invalidAuthoringRequest(rpc, err)
+	}
+
+	session, err := r.pingedSession(rpc)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	history := new(pb.RecipeHistory)
+	err = session.DB("recipes").C("recipeversions").Find(bson.M{"kind": kind.name, "id": id}).Sort("version").All(&history.Versions)
+	if err != nil && err != mgo.ErrNotFound {
+		return nil, r.authoringError(rpc, "fetch", codes.Unknown, fmt.Errorf("cannot fetch history of %s %s: %v", kind.name, id, err))
+	}
+	if len(history.Versions) == 0 {
+		return nil, r.authoringError(rpc, "fetch", codes.NotFound, fmt.Errorf("no history for %s %s", kind.name, id))
+	}
+	return history, nil
+}
+
+func (r *Server) loadDocument(session *mgo.Session, kind documentKind, id string, rpc string) (authoredDocument, error) {
+	document := kind.empty()
+	err := session.DB("recipes").C(kind.collection).Find(bson.M{"id": id}).One(document.value())
+	if err == mgo.ErrNotFound {
+		return nil, r.authoringError(rpc, "fetch", codes.NotFound, fmt.Errorf("%s %s not found", kind.name, id))
+	}
+	if err != nil {
+		return nil, r.authoringError(rpc, "fetch", codes.Unknown, fmt.Errorf("cannot fetch %s %s: %v", kind.name, id, err))
+	}
+	return document, nil
+}
+
+// recordVersion appends a snapshot of the document to its version history.
+func (r *Server) recordVersion(session *mgo.Session, kind documentKind, id string, document authoredDocument, editor string, action string, rpc string) (*pb.RecipeVersion, error) {
+	// Every write has completed by the time its version is recorded.
+	r.invalidateCache(kind, id)
+
+	c := session.DB("recipes").C("recipeversions")
+
+	// The unique version index rejects a version number taken by a
+	// concurrent write, in which case the next one is tried.
+	for attempt := 0; attempt < versionAttempts; attempt++ {
+		latest := new(pb.RecipeVersion)
+		err := c.Find(bson.M{"kind": kind.name, "id": id}).Sort("-version").One(latest)
+		if err != nil && err != mgo.ErrNotFound {
+			return nil, r.authoringError(rpc, action, codes.Unknown, fmt.Errorf("cannot fetch latest version of %s %s: %v", kind.name, id, err))
+		}
+
+		version := &pb.RecipeVersion{
+			Id:        id,
+			Kind:      kind.name,
+			Version:   latest.Version + 1,
+			Action:    action,
+			Editor:    editor,
+			Createdat: &pb.Timestamp{Seconds: time.Now().Unix()},
+		}
+		document.attachTo(version)
+		err = c.Insert(version)
+		if mgo.IsDup(err) {
+			continue
+		}
+		if err != nil {
+			return nil, r.authoringError(rpc, action, codes.Unknown, fmt.Errorf("cannot record version of %s %s: %v", kind.name, id, err))
+		}
+
+		r.Logger.Info(logrus.Fields{
+			"phase": "process",
+			"event": action,
+			"tag":   "authoring",
+			"rpc":   rpc},
+			fmt.Sprintf("Recorded version %d of %s %s by editor %s", version.Version, kind.name, id, editor))
+		return version, nil
+	}
+	return nil, r.authoringError(rpc, action, codes.Aborted, fmt.Errorf("cannot record version of %s %s, concurrent writes took %d version numbers in a row", kind.name, id, versionAttempts))
+}
+
+func (r *Server) invalidAuthoringRequest(rpc string, err error) error {
+	r.Logger.Error(logrus.Fields{
+		"phase": "process",
+		"event": "parseparameters",
+		"tag":   "invalidparameters",
+		"rpc":   rpc},
+		err.Error())
+	return grpc.Errorf(codes.InvalidArgument, "%v.", err)
+}
+
+func (r *Server) authoringError(rpc string, event string, code codes.Code, err error) error {
+	r.Logger.Error(logrus.Fields{
+		"phase": "process",
+		"event": event,
+		"tag":   "mongodb",
+		"rpc":   rpc},
+		err.Error())
+	return grpc.Errorf(code, "%v.", err)
+}
+
+func requireEditor(editor string) error {
+	if editor == "" {
+		return ErrMissingEditor
+	}
+	return nil
+}
+
+func requireID(kind documentKind, id string) error {
+	if id == "" {
+		return fmt.Errorf("%s ID not specified", kind.name)
+	}
+	return nil
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
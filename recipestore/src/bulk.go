@@ -0,0 +1,270 @@
+/*
+// ----------------------------------------------------------------------------
+// bulk.go
+// Countertop Recipe Microservice Bulk Import & Export
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Outcomes of importing a single document.
+const (
+	BulkInsert    = "insert"
+	BulkUpdate    = "update"
+	BulkUnchanged = "unchanged"
+)
+
+// BulkChange describes what importing a document does, or would do on a dry
+// run, to the stored copy.
+type BulkChange struct {
+	ID     string
+	Action string
+	// Top level fields that differ from the stored document, for updates.
+	Fields []string
+}
+
+// BulkLoader imports and exports the recipes or recipepacks collection of
+// Server's MongoDB. Imported documents are recorded in the version history
+// and dropped from Server's cache like any other authoring write.
+type BulkLoader struct {
+	Server *Server
+	// Editor is recorded in the version history of imported documents.
+	Editor string
+	// DryRun reports the changes an import would make without writing them.
+	DryRun bool
+	kind   documentKind
+}
+
+func NewBulkLoader(server *Server, collection string) (*BulkLoader, error) {
+	for _, kind := range []documentKind{recipeKind, packKind} {
+		if kind.collection == collection {
+			return &BulkLoader{Server: server, kind: kind}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown collection %q, expected %q or %q", collection, recipeKind.collection, packKind.collection)
+}
+
+// Decode reads documents from a JSON array or from newline delimited JSON
+// objects and validates every one of them. Fields the document type does not
+// have are rejected rather than dropped. The documents returned are
+// *pb.Recipe or *pb.RecipePack depending on the collection.
+func (l *BulkLoader) Decode(r io.Reader) ([]interface{}, error) {
+	documents, err := l.decode(r)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, 0, len(documents))
+	for _, document := range documents {
+		values = append(values, document.value())
+	}
+	return values, nil
+}
+
+func (l *BulkLoader) decode(r io.Reader) ([]authoredDocument, error) {
+	reader := bufio.NewReader(r)
+	var raw []json.RawMessage
+
+	first, err := firstNonSpace(reader)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(reader)
+	if first == '[' {
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("cannot decode JSON array: %v", err)
+		}
+	} else {
+		for {
+			var message json.RawMessage
+			if err := decoder.Decode(&message); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("cannot decode document %d: %v", len(raw)+1, err)
+			}
+			raw = append(raw, message)
+		}
+	}
+
+	documents := make([]authoredDocument, 0, len(raw))
+	seen := make(map[string]bool)
+	for i, message := range raw {
+		document := l.kind.empty()
+		decoder := json.NewDecoder(bytes.NewReader(message))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(document.value()); err != nil {
+			return nil, fmt.Errorf("document %d is not a valid %s: %v", i+1, l.kind.name, err)
+		}
+		if err := document.validate(); err != nil {
+			return nil, fmt.Errorf("document %d: %v", i+1, err)
+		}
+		if seen[document.documentID()] {
+			return nil, fmt.Errorf("document %d: %s %s appears more than once", i+1, l.kind.name, document.documentID())
+		}
+		seen[document.documentID()] = true
+		documents = append(documents, document)
+	}
+	return documents, nil
+}
+
+func firstNonSpace(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, reader.UnreadByte()
+	}
+}
+
+// Import upserts the documents by ID, reporting the change made to each, and
+// records a version of every document it inserts or updates.
+func (l *BulkLoader) Import(r io.Reader) ([]BulkChange, error) {
+	if !l.DryRun && l.Editor == "" {
+		return nil, ErrMissingEditor
+	}
+	documents, err := l.decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	session := l.Server.MongoSession.Copy()
+	defer session.Close()
+	c := session.DB("recipes").C(l.kind.collection)
+
+	changes := make([]BulkChange, 0, len(documents))
+	for _, document := range documents {
+		id := document.documentID()
+		change := BulkChange{ID: id, Action: BulkInsert}
+
+		existing := l.kind.empty().value()
+		err := c.Find(bson.M{"id": id}).One(existing)
+		if err != nil && err != mgo.ErrNotFound {
+			return changes, fmt.Errorf("cannot fetch %s %s: %v", l.kind.name, id, err)
+		}
+		if err == nil {
+			fields, err := DiffFields(existing, document.value())
+			if err != nil {
+				return changes, err
+			}
+			change.Action, change.Fields = BulkUpdate, fields
+			if len(fields) == 0 {
+				change.Action = BulkUnchanged
+			}
+		}
+
+		if !l.DryRun && change.Action != BulkUnchanged {
+			if _, err := c.Upsert(bson.M{"id": id}, document.value()); err != nil {
+				return changes, fmt.Errorf("cannot upsert %s %s: %v", l.kind.name, id, err)
+			}
+			action := ActionUpdate
+			if change.Action == BulkInsert {
+				action = ActionCreate
+			}
+			if _, err := l.Server.recordVersion(session, l.kind, id, document, l.Editor, action, "BulkImport"); err != nil {
+				return append(changes, change), err
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// Export writes every document in the collection, ordered by ID, as an
+// indented JSON array or as newline delimited JSON.
+func (l *BulkLoader) Export(w io.Writer, ndjson bool) (int, error) {
+	session := l.Server.MongoSession.Copy()
+	defer session.Close()
+
+	var documents []interface{}
+	iter := session.DB("recipes").C(l.kind.collection).Find(nil).Sort("id").Iter()
+	for document := l.kind.empty(); iter.Next(document.value()); document = l.kind.empty() {
+		documents = append(documents, document.value())
+	}
+	if err := iter.Close(); err != nil {
+		return 0, fmt.Errorf("cannot read %s collection: %v", l.kind.collection, err)
+	}
+
+	if !ndjson {
+		if documents == nil {
+			documents = []interface{}{}
+		}
+		out, err := json.MarshalIndent(documents, "", "  ")
+		if err != nil {
+			return 0, err
+		}
+		_, err = w.Write(append(out, '\n'))
+		return len(documents), err
+	}
+
+	encoder := json.NewEncoder(w)
+	for i, document := range documents {
+		if err := encoder.Encode(document); err != nil {
+			return i, err
+		}
+	}
+	return len(documents), nil
+}
+
+// DiffFields lists the top level fields that differ between two documents
+// of the same type, comparing them as they would be stored in MongoDB.
+func DiffFields(existing interface{}, incoming interface{}) ([]string, error) {
+	before, err := storedFields(existing)
+	if err != nil {
+		return nil, err
+	}
+	after, err := storedFields(incoming)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	for field, value := range after {
+		if other, ok := before[field]; !ok || other.Kind != value.Kind || !bytes.Equal(other.Data, value.Data) {
+			fields = append(fields, field)
+		}
+	}
+	for field := range before {
+		if _, ok := after[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func storedFields(document interface{}) (map[string]bson.Raw, error) {
+	data, err := bson.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	var elements bson.RawD
+	if err := bson.Unmarshal(data, &elements); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]bson.Raw, len(elements))
+	for _, element := range elements {
+		fields[element.Name] = element.Value
+	}
+	return fields, nil
+}
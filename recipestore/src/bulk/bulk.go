@@ -0,0 +1,145 @@
+/*
+// ----------------------------------------------------------------------------
+// bulk.go
+// Countertop Server RecipeStore Bulk Import & Export Tool
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/mgo.v2"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/theorangechefco/cts/go-shared-libs/cts/logger"
+	recipestoreutil "github.com/theorangechefco/cts/recipestore"
+)
+
+var (
+	mongoHost    = flag.String("mongo_host", "0.0.0.0:27017", "Hostname of MongoDB server")
+	mongoUser    = flag.String("mongo_user", "recipestoresrv", "Username of MongoDB server")
+	mongoPass    = flag.String("mongo_pass", "abc", "Password  of MongoDB server user")
+	collection   = flag.String("collection", "recipes", "Collection to import into or export from: recipes or recipepacks")
+	importFile   = flag.String("import", "", "JSON or NDJSON file to import, - for STDIN")
+	exportFile   = flag.String("export", "", "File to export to, - for STDOUT")
+	ndjson       = flag.Bool("ndjson", false, "Export newline delimited JSON instead of a JSON array")
+	dryRun       = flag.Bool("dry_run", false, "Report the changes an import would make without writing them")
+	editor       = flag.String("editor", "", "Editor recorded in the version history of imported documents, required to import")
+	mongoTimeout = 60 * time.Second
+	mongoDB      = "recipes"
+)
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(-1)
+}
+
+func openInput(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return os.Stdin, nil
+	}
+	return os.Open(name)
+}
+
+func openOutput(name string) (io.WriteCloser, error) {
+	if name == "-" {
+		return os.Stdout, nil
+	}
+	return os.Create(name)
+}
+
+func runImport(loader *recipestoreutil.BulkLoader) {
+	in, err := openInput(*importFile)
+	if err != nil {
+		fail("cannot open %s: %v", *importFile, err)
+	}
+	defer in.Close()
+
+	changes, err := loader.Import(in)
+	counts := make(map[string]int)
+	for _, change := range changes {
+		counts[change.Action]++
+		switch change.Action {
+		case recipestoreutil.BulkInsert:
+			fmt.Printf("+ %s\n", change.ID)
+		case recipestoreutil.BulkUpdate:
+			fmt.Printf("~ %s: %s\n", change.ID, strings.Join(change.Fields, ", "))
+		}
+	}
+	if err != nil {
+		fail("import of %s stopped after %d documents: %v", *importFile, len(changes), err)
+	}
+
+	verb := "Imported"
+	if *dryRun {
+		verb = "Dry run, would import"
+	}
+	fmt.Printf("%s %d %s: %d inserted, %d updated, %d unchanged\n", verb, len(changes), *collection,
+		counts[recipestoreutil.BulkInsert], counts[recipestoreutil.BulkUpdate], counts[recipestoreutil.BulkUnchanged])
+}
+
+func runExport(loader *recipestoreutil.BulkLoader) {
+	out, err := openOutput(*exportFile)
+	if err != nil {
+		fail("cannot create %s: %v", *exportFile, err)
+	}
+	defer out.Close()
+
+	count, err := loader.Export(out, *ndjson)
+	if err != nil {
+		fail("export to %s failed after %d documents: %v", *exportFile, count, err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d %s\n", count, *collection)
+}
+
+func main() {
+	flag.Parse()
+	if (*importFile == "") == (*exportFile == "") {
+		fail("exactly one of --import or --export is required")
+	}
+	if *importFile != "" && !*dryRun && *editor == "" {
+		fail("--editor is required to import")
+	}
+
+	session, err := mgo.DialWithInfo(&mgo.DialInfo{
+		Addrs:    []string{*mongoHost},
+		Timeout:  mongoTimeout,
+		Database: mongoDB,
+		Username: *mongoUser,
+		Password: *mongoPass,
+	})
+	if err != nil {
+		fail("cannot connect to MongoDB on %s: %v", *mongoHost, err)
+	}
+	defer session.Close()
+	session.SetMode(mgo.Monotonic, true)
+	if err := recipestoreutil.EnsureIndexes(session); err != nil {
+		fail("cannot create MongoDB indexes: %v", err)
+	}
+
+	server := &recipestoreutil.Server{
+		Logger:       logger.NewLogger("recipestorebulk", "", 0, true, logrus.WarnLevel),
+		MongoSession: session,
+	}
+	loader, err := recipestoreutil.NewBulkLoader(server, *collection)
+	if err != nil {
+		fail("%v", err)
+	}
+	loader.Editor = *editor
+	loader.DryRun = *dryRun
+
+	if *importFile != "" {
+		runImport(loader)
+	} else {
+		runExport(loader)
+	}
+}
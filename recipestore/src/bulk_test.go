@@ -0,0 +1,97 @@
+/*
+// ----------------------------------------------------------------------------
+// bulk_test.go
+// Countertop Recipe Microservice Bulk Import & Export Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+	recipestore "github.com/theorangechefco/cts/recipestore"
+)
+
+const bulkRecipe = `{"id": "41", "name": "Lentil Soup", "servings": 4,
+	"ingredients": [{"name": "Lentils", "quantity": 200, "unit": "g"}],
+	"mealcourses": {"lunchanddinner": true},
+	"dietaryprofile": {"vegan": true}}`
+
+func TestBulkDecodeFormats(t *testing.T) {
+	loader, err := recipestore.NewBulkLoader(nil, "recipes")
+	if err != nil {
+		t.Fatalf("NewBulkLoader(_, recipes) = _, %v", err)
+	}
+
+	second := strings.Replace(strings.Replace(bulkRecipe, `"41"`, `"42"`, 1), "\n\t", " ", -1)
+	inputs := map[string]string{
+		"array":  "[" + bulkRecipe + ",\n" + second + "]",
+		"ndjson": strings.Replace(bulkRecipe, "\n\t", " ", -1) + "\n" + second + "\n",
+	}
+	for format, input := range inputs {
+		documents, err := loader.Decode(strings.NewReader(input))
+		if err != nil {
+			t.Errorf("%s: Decode(_) = _, %v", format, err)
+			continue
+		}
+		if len(documents) != 2 {
+			t.Errorf("%s: Decode(_) returned %d documents, want 2", format, len(documents))
+			continue
+		}
+		recipe, ok := documents[1].(*pb.Recipe)
+		if !ok || recipe.Id != "42" || recipe.Ingredients[0].Quantity != 200 {
+			t.Errorf("%s: Decode(_)[1] = %v", format, documents[1])
+		}
+	}
+}
+
+func TestBulkDecodeRejectsInvalidDocuments(t *testing.T) {
+	loader, _ := recipestore.NewBulkLoader(nil, "recipes")
+	inputs := map[string]string{
+		"invalid recipe": `{"id": "41", "name": "No servings"}`,
+		"duplicate id":   "[" + bulkRecipe + "," + bulkRecipe + "]",
+		"malformed":      `{"id": "41"`,
+		"wrong schema":   `{"id": "41", "servings": "four"}`,
+		"unknown field":  strings.Replace(bulkRecipe, `"servings": 4`, `"servings": 4, "serves": 4`, 1),
+	}
+	for name, input := range inputs {
+		if _, err := loader.Decode(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: Decode(_) = _, nil, want error", name)
+		}
+	}
+
+	if _, err := recipestore.NewBulkLoader(nil, "users"); err == nil {
+		t.Errorf("NewBulkLoader(_, users) = _, nil, want error")
+	}
+}
+
+func TestBulkImportRequiresEditor(t *testing.T) {
+	loader, _ := recipestore.NewBulkLoader(nil, "recipes")
+	if _, err := loader.Import(strings.NewReader(bulkRecipe)); err != recipestore.ErrMissingEditor {
+		t.Errorf("Import(_) = _, %v, want %v", err, recipestore.ErrMissingEditor)
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	existing := validRecipe()
+	incoming := validRecipe()
+	if fields, err := recipestore.DiffFields(existing, incoming); err != nil || len(fields) != 0 {
+		t.Errorf("DiffFields(same) = %v, %v, want no fields", fields, err)
+	}
+
+	incoming.Name = "Red Lentil Soup"
+	incoming.Nutrition.Calories = 380
+	fields, err := recipestore.DiffFields(existing, incoming)
+	if err != nil {
+		t.Fatalf("DiffFields(_) = _, %v", err)
+	}
+	if want := []string{"name", "nutrition"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("DiffFields(_) = %v, want %v", fields, want)
+	}
+}
@@ -0,0 +1,200 @@
+/*
+// ----------------------------------------------------------------------------
+// cache.go
+// Countertop Recipe Microservice Read-Through Recipe Cache
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+const (
+	recipeCachePrefix = "recipe:"
+	packsCachePrefix  = "packs:"
+)
+
+// RecipeCache is an in-process LRU cache with a TTL on every entry. It sits
+// in front of MongoDB for GetRecipe and GetRecipePacks. A nil cache is valid
+// and caches nothing.
+//
+// Authoring RPCs invalidate entries as they write. Changes made out of band,
+// or through another recipestore instance, are picked up once the TTL
+// expires.
+type RecipeCache struct {
+	// Now returns the current time, and can be replaced in tests.
+	Now func() time.Time
+
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[string]*list.Element
+	order    *list.List
+	stats    CacheStats
+}
+
+type CacheStats struct {
+	Hits          uint64
+	Misses        uint64
+	Evictions     uint64
+	Invalidations uint64
+	Entries       int
+}
+
+func (s CacheStats) String() string {
+	return fmt.Sprintf("%d entries, %d hits, %d misses, %d evictions, %d invalidations",
+		s.Entries, s.Hits, s.Misses, s.Evictions, s.Invalidations)
+}
+
+type cacheEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+func NewRecipeCache(capacity int, ttl time.Duration) *RecipeCache {
+	return &RecipeCache{
+		Now:      time.Now,
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *RecipeCache) Get(key string) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if !c.Now().Before(entry.expires) {
+		c.remove(element)
+		c.stats.Misses++
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	c.stats.Hits++
+	return entry.value, true
+}
+
+func (c *RecipeCache) Set(key string, value interface{}) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.Now().Add(c.ttl)
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		entry.value, entry.expires = value, expires
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Invalidate drops the entry for the key, if any.
+func (c *RecipeCache) Invalidate(key string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+		c.stats.Invalidations++
+	}
+}
+
+// InvalidatePrefix drops every entry whose key starts with the prefix.
+func (c *RecipeCache) InvalidatePrefix(prefix string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, element := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(element)
+			c.stats.Invalidations++
+		}
+	}
+}
+
+func (c *RecipeCache) Stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	return stats
+}
+
+// Callers must hold c.mu.
+func (c *RecipeCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*cacheEntry).key)
+}
+
+func recipeCacheKey(recipeID string) string {
+	return recipeCachePrefix + recipeID
+}
+
+// packsCacheKey identifies a recipe pack query by the request fields that
+// make up the query. Ranking is applied after the cache, so it is not part
+// of the key.
+func packsCacheKey(recipePackRequest *pb.RecipePacksRequest) string {
+	profile := recipePackRequest.Dietaryprofile
+	if profile == nil {
+		profile = &pb.DietaryProfile{}
+	}
+	restriction := recipePackRequest.Dietaryrestriction
+	if restriction == nil {
+		restriction = &pb.DietaryRestriction{}
+	}
+	return fmt.Sprintf("%sprofile=%t,%t,%t,%t;restriction=%t,%t,%t,%t,%t;mealplan=%d", packsCachePrefix,
+		profile.Omnivore, profile.Vegetarian, profile.Vegan, profile.Raw,
+		restriction.Glutenfree, restriction.Nutfree, restriction.Dairyfree, restriction.Soyfree, restriction.Lowsodium,
+		recipePackRequest.Mealplan)
+}
+
+// invalidateCache drops cached copies of a document after it is written. Any
+// write to a pack can change the result of any pack query, so all of them
+// are dropped.
+func (r *Server) invalidateCache(kind documentKind, id string) {
+	switch kind.name {
+	case recipeKind.name:
+		r.Cache.Invalidate(recipeCacheKey(id))
+	case packKind.name:
+		r.Cache.InvalidatePrefix(packsCachePrefix)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+// ----------------------------------------------------------------------------
+// cache_test.go
+// Countertop Recipe Microservice Read-Through Recipe Cache Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore_test
+
+import (
+	"testing"
+	"time"
+
+	recipestore "github.com/theorangechefco/cts/recipestore"
+)
+
+func TestRecipeCacheExpiry(t *testing.T) {
+	now := time.Date(2015, 11, 30, 12, 0, 0, 0, time.UTC)
+	cache := recipestore.NewRecipeCache(10, time.Minute)
+	cache.Now = func() time.Time { return now }
+
+	if _, ok := cache.Get("recipe:1"); ok {
+		t.Errorf("Get(recipe:1) hit on an empty cache")
+	}
+	cache.Set("recipe:1", "lentil soup")
+	if value, ok := cache.Get("recipe:1"); !ok || value != "lentil soup" {
+		t.Errorf("Get(recipe:1) = %v, %t, want lentil soup, true", value, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get("recipe:1"); ok {
+		t.Errorf("Get(recipe:1) hit after the TTL expired")
+	}
+
+	want := recipestore.CacheStats{Hits: 1, Misses: 2}
+	if stats := cache.Stats(); stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestRecipeCacheEviction(t *testing.T) {
+	cache := recipestore.NewRecipeCache(2, time.Minute)
+	cache.Set("recipe:1", 1)
+	cache.Set("recipe:2", 2)
+	// Touch 1 so that 2 is the least recently used entry
+	cache.Get("recipe:1")
+	cache.Set("recipe:3", 3)
+
+	if _, ok := cache.Get("recipe:2"); ok {
+		t.Errorf("Get(recipe:2) hit, want it evicted")
+	}
+	for _, key := range []string{"recipe:1", "recipe:3"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("Get(%s) missed, want a hit", key)
+		}
+	}
+	if stats := cache.Stats(); stats.Evictions != 1 || stats.Entries != 2 {
+		t.Errorf("Stats() = %+v, want 1 eviction and 2 entries", stats)
+	}
+}
+
+func TestRecipeCacheInvalidation(t *testing.T) {
+	cache := recipestore.NewRecipeCache(10, time.Minute)
+	cache.Set("recipe:1", 1)
+	cache.Set("recipe:2", 2)
+	cache.Set("packs:vegan", 3)
+	cache.Set("packs:omnivore", 4)
+
+	cache.Invalidate("recipe:1")
+	cache.InvalidatePrefix("packs:")
+
+	for _, key := range []string{"recipe:1", "packs:vegan", "packs:omnivore"} {
+		if _, ok := cache.Get(key); ok {
+			t.Errorf("Get(%s) hit after invalidation", key)
+		}
+	}
+	if _, ok := cache.Get("recipe:2"); !ok {
+		t.Errorf("Get(recipe:2) missed, want a hit")
+	}
+	if stats := cache.Stats(); stats.Invalidations != 3 {
+		t.Errorf("Stats().Invalidations = %d, want 3", stats.Invalidations)
+	}
+}
+
+func TestNilRecipeCache(t *testing.T) {
+	var cache *recipestore.RecipeCache
+	cache.Set("recipe:1", 1)
+	cache.Invalidate("recipe:1")
+	cache.InvalidatePrefix("recipe:")
+	if _, ok := cache.Get("recipe:1"); ok {
+		t.Errorf("Get(recipe:1) hit on a nil cache")
+	}
+	if stats := cache.Stats(); stats != (recipestore.CacheStats{}) {
+		t.Errorf("Stats() = %+v on a nil cache", stats)
+	}
+}
@@ -61,6 +61,42 @@ func printRecipePack(client pb.RecipeServiceClient, recipePackRequest *pb.Recipe
 	}
 }
 
+func printSearchResults(client pb.RecipeServiceClient, searchRequest *pb.RecipeSearchRequest) {
+	pp.Printf("Searching recipes for RecipeSearchRequest (%v)", searchRequest)
+	stream, err := client.SearchRecipes(context.Background(), searchRequest)
+	if err != nil {
+		grpclog.Fatalf("%v.SearchRecipes(_) = _, %v: ", client, err)
+	}
+	for {
+		result, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			grpclog.Fatalf("%v.SearchRecipes(_) = _, %v", client, err)
+		}
+		pp.Println(result)
+	}
+}
+
+func printShoppingList(client pb.RecipeServiceClient, shoppingListRequest *pb.ShoppingListRequest) {
+	grpclog.Printf("Getting shopping list for ShoppingListRequest (%v)", shoppingListRequest)
+	shoppingList, err := client.GetShoppingList(context.Background(), shoppingListRequest)
+	if err != nil {
+		grpclog.Fatalf("%v.GetShoppingList(_) = _, %v: ", client, err)
+	}
+	pp.Println(shoppingList)
+}
+
+func printScaledRecipe(client pb.RecipeServiceClient, scaleRequest *pb.ScaleRecipeRequest) {
+	grpclog.Printf("Scaling recipe for ScaleRecipeRequest (%v)", scaleRequest)
+	recipe, err := client.ScaleRecipe(context.Background(), scaleRequest)
+	if err != nil {
+		grpclog.Fatalf("%v.ScaleRecipe(_) = _, %v: ", client, err)
+	}
+	pp.Println(recipe)
+}
+
 func main() {
 	flag.Parse()
 	// var opts []grpc.DialOption
@@ -94,4 +130,14 @@ func main() {
 	// printRecipes(client, &course)
 	printRecipe(client, &recipeRequest)
 	printRecipePack(client, &recipePackRequst)
+	printSearchResults(client, &pb.RecipeSearchRequest{
+		Query:       "salad",
+		Maxcalories: 600,
+		Pagesize:    5})
+	printShoppingList(client, &pb.ShoppingListRequest{
+		Recipeids: []string{"41", "42"}})
+	printScaledRecipe(client, &pb.ScaleRecipeRequest{
+		Recipeid:   "41",
+		Servings:   6,
+		Unitsystem: pb.UnitSystem_METRIC})
 }
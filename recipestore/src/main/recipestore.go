@@ -30,6 +30,8 @@ import (
 
 var (
 	port         = flag.Int("port", 50051, "The server port")
+	store        = flag.String("store", "mongo", "Recipe store to serve from: mongo, or file to serve offline out of --store_path")
+	storePath    = flag.String("store_path", "recipes", "Directory holding recipes.json and recipepacks.json for the file store")
 	mongoHost    = flag.String("mongo_host", "0.0.0.0:27017", "Hostname of MongoDB server")
 	mongoUser    = flag.String("mongo_user", "recipestoresrv", "Username of MongoDB server")
 	mongoPass    = flag.String("mongo_pass", "abc", "Password  of MongoDB server user")
@@ -39,6 +41,9 @@ var (
 	stdErrLog    = flag.Bool("stderr_log", false, "Log to STDERR")
 	fluentdHost  = flag.String("fluentd_host", "", "Fluentd agent hostname. If left blank, fluentd logging disabled")
 	fluentdPort  = flag.Int("fluentd_port", 24224, "Fluentd agent port")
+	cacheSize    = flag.Int("cache_size", 1000, "Maximum number of cached recipes and recipe pack queries, 0 disables the cache")
+	cacheTTL     = flag.Duration("cache_ttl", 5*time.Minute, "How long a cached entry is served before it is refetched")
+	cacheStats   = flag.Duration("cache_stats_interval", time.Minute, "How often cache statistics are logged")
 	mongoTimeout = 60 * time.Second
 	mongoDB      = "recipes"
 )
@@ -50,30 +55,71 @@ func main() {
 	recipestoreServerInstance := new(recipestoreutil.Server)
 	recipestoreServerInstance.Logger = logger.NewLogger("recipestoresrv", *fluentdHost, *fluentdPort, *stdErrLog, logrus.DebugLevel)
 
-	mongoDBDialInfo := &mgo.DialInfo{
-		Addrs:    []string{*mongoHost},
-		Timeout:  mongoTimeout,
-		Database: mongoDB,
-		Username: *mongoUser,
-		Password: *mongoPass,
-	}
+	switch *store {
+	case "mongo":
+		mongoDBDialInfo := &mgo.DialInfo{
+			Addrs:    []string{*mongoHost},
+			Timeout:  mongoTimeout,
+			Database: mongoDB,
+			Username: *mongoUser,
+			Password: *mongoPass,
+		}
 
-	// Create a session which maintains a pool of socket connections
-	// to our MongoDB.
-	recipestoreServerInstance.MongoSession, err = mgo.DialWithInfo(mongoDBDialInfo)
-	if err != nil {
-		recipestoreServerInstance.Logger.Fatal(logrus.Fields{
+		// Create a session which maintains a pool of socket connections
+		// to our MongoDB.
+		recipestoreServerInstance.MongoSession, err = mgo.DialWithInfo(mongoDBDialInfo)
+		if err != nil {
+			recipestoreServerInstance.Logger.Fatal(logrus.Fields{
+				"phase": "startup",
+				"event": "connect"},
+				fmt.Sprintf("Unable to set up connection to MongoDB: %v", err))
+		}
+		defer recipestoreServerInstance.MongoSession.Close()
+		recipestoreServerInstance.MongoSession.SetMode(mgo.Monotonic, true)
+		if err := recipestoreutil.EnsureIndexes(recipestoreServerInstance.MongoSession); err != nil {
+			recipestoreServerInstance.Logger.Fatal(logrus.Fields{
+				"phase": "startup",
+				"event": "setup"},
+				fmt.Sprintf("Unable to create MongoDB indexes: %v", err))
+		}
+		recipestoreServerInstance.Repository = &recipestoreutil.MongoRecipeRepository{Session: recipestoreServerInstance.MongoSession}
+
+		recipestoreServerInstance.Logger.Info(logrus.Fields{
 			"phase": "startup",
 			"event": "connect"},
-			fmt.Sprintf("Unable to set up connection to MongoDB: %v", err))
+			fmt.Sprintf("Successfully setup connection to MongoDB on host: %s.", *mongoHost))
+	case "file":
+		recipestoreServerInstance.Repository, err = recipestoreutil.LoadFileRecipeStore(*storePath)
+		if err != nil {
+			recipestoreServerInstance.Logger.Fatal(logrus.Fields{
+				"phase": "startup",
+				"event": "load"},
+				fmt.Sprintf("Unable to load recipe store from %s: %v", *storePath, err))
+		}
+
+		recipestoreServerInstance.Logger.Info(logrus.Fields{
+			"phase": "startup",
+			"event": "load"},
+			fmt.Sprintf("Serving recipes from %s. Meal plans and authoring are disabled.", *storePath))
+	default:
+		recipestoreServerInstance.Logger.Fatal(logrus.Fields{
+			"phase": "startup",
+			"event": "setup"},
+			fmt.Sprintf("Unknown recipe store %q, expected mongo or file", *store))
 	}
-	defer recipestoreServerInstance.MongoSession.Close()
-	recipestoreServerInstance.MongoSession.SetMode(mgo.Monotonic, true)
 
-	recipestoreServerInstance.Logger.Info(logrus.Fields{
-		"phase": "startup",
-		"event": "connect"},
-		fmt.Sprintf("Successfully setup connection to MongoDB on host: %s.", *mongoHost))
+	if *cacheSize > 0 {
+		recipestoreServerInstance.Cache = recipestoreutil.NewRecipeCache(*cacheSize, *cacheTTL)
+		go func() {
+			for range time.Tick(*cacheStats) {
+				recipestoreServerInstance.Logger.Info(logrus.Fields{
+					"phase": "process",
+					"event": "stats",
+					"tag":   "cache"},
+					fmt.Sprintf("Recipe cache: %v", recipestoreServerInstance.Cache.Stats()))
+			}
+		}()
+	}
 
 	lis, lisErr := net.Listen("tcp", fmt.Sprintf(":%d", *port))
 	if lisErr != nil {
@@ -0,0 +1,232 @@
+/*
+// ----------------------------------------------------------------------------
+// mealplans.go
+// Countertop Recipe Microservice Weekly Meal Plan RPCs
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/Sirupsen/logrus"
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+func (r *Server) GenerateMealPlan(ctx context.Context, mealPlanRequest *pb.MealPlanRequest) (*pb.WeeklyMealPlan, error) {
+	if mealPlanRequest.Useruuid == "" || mealPlanRequest.Dailycalories <= 0 {
+		errMsg := fmt.Sprintf("User ID and daily calorie target are required, got: %v", mealPlanRequest)
+		r.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "GenerateMealPlan"},
+			errMsg)
+		return nil, grpc.Errorf(codes.InvalidArgument, errMsg)
+	}
+
+	planner, err := r.newPlanner(mealPlanRequest.Dietaryprofile, mealPlanRequest.Dietaryrestriction, mealPlanRequest.Repeatwindow, "GenerateMealPlan")
+	if err != nil {
+		return nil, err
+	}
+
+	plan, planErr := planner.PlanWeek(mealPlanRequest.Useruuid, mealPlanRequest.Dailycalories)
+	if planErr != nil {
+		r.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "plan",
+			"tag":   "planner",
+			"rpc":   "GenerateMealPlan"},
+			fmt.Sprintf("Cannot plan meals for user %s: %v", mealPlanRequest.Useruuid, planErr))
+		return nil, grpc.Errorf(codes.FailedPrecondition, "Cannot plan meals: %v.", planErr)
+	}
+	plan.Createdat = &pb.Timestamp{Seconds: time.Now().Unix()}
+
+	if err := r.saveMealPlan(plan, "GenerateMealPlan"); err != nil {
+		return nil, err
+	}
+
+	r.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "plan",
+		"tag":   "planner",
+		"rpc":   "GenerateMealPlan"},
+		fmt.Sprintf("Generated meal plan for user %s with %d recipe candidates", mealPlanRequest.Useruuid, len(planner.Candidates)))
+	return plan, nil
+}
+
+func (r *Server) GetMealPlan(ctx context.Context, userID *pb.UserId) (*pb.WeeklyMealPlan, error) {
+	return r.loadMealPlan(userID.Uuid, "GetMealPlan")
+}
+
+func (r *Server) RegenerateMeal(ctx context.Context, regenerateRequest *pb.RegenerateMealRequest) (*pb.WeeklyMealPlan, error) {
+	plan, err := r.loadMealPlan(regenerateRequest.Useruuid, "RegenerateMeal")
+	if err != nil {
+		return nil, err
+	}
+
+	planner, err := r.newPlanner(regenerateRequest.Dietaryprofile, regenerateRequest.Dietaryrestriction, regenerateRequest.Repeatwindow, "RegenerateMeal")
+	if err != nil {
+		return nil, err
+	}
+
+	if planErr := planner.ReplanMeal(plan, int(regenerateRequest.Day), int(regenerateRequest.Meal)); planErr != nil {
+		r.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "plan",
+			"tag":   "planner",
+			"rpc":   "RegenerateMeal"},
+			fmt.Sprintf("Cannot regenerate meal %d on day %d for user %s: %v", regenerateRequest.Meal, regenerateRequest.Day, regenerateRequest.Useruuid, planErr))
+		if planErr == ErrMealNotInPlan {
+			return nil, grpc.Errorf(codes.InvalidArgument, "%v.", planErr)
+		}
+		return nil, grpc.Errorf(codes.FailedPrecondition, "%v.", planErr)
+	}
+
+	if err := r.saveMealPlan(plan, "RegenerateMeal"); err != nil {
+		return nil, err
+	}
+
+	r.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "plan",
+		"tag":   "planner",
+		"rpc":   "RegenerateMeal"},
+		fmt.Sprintf("Regenerated meal %d on day %d for user %s", regenerateRequest.Meal, regenerateRequest.Day, regenerateRequest.Useruuid))
+	return plan, nil
+}
+
+// newPlanner loads every recipe suitable for the dietary profile and
+// restrictions as planner candidates.
+func (r *Server) newPlanner(profile *pb.DietaryProfile, restriction *pb.DietaryRestriction, repeatWindow int32, rpc string) (*Planner, error) {
+	candidates, err := r.Repository.FindRecipes(profile, restriction)
+	if err != nil {
+		if err == ErrNoDietaryProfile {
+			r.Logger.Error(logrus.Fields{
+				"phase": "process",
+				"event": "parseparameters",
+				"tag":   "invalidparameters",
+				"rpc":   rpc},
+				err.Error())
+			return nil, grpc.Errorf(codes.InvalidArgument, "%v.", err)
+		}
+		errMsg := fmt.Sprintf("Unknown recipe lookup error: %v", err)
+		r.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "repository",
+			"rpc":   rpc},
+			errMsg)
+		return nil, grpc.Errorf(codes.Unknown, errMsg)
+	}
+
+	window := int(repeatWindow)
+	if window <= 0 {
+		window = DefaultRepeatWindow
+	}
+	return &Planner{
+		Candidates:   candidates,
+		RepeatWindow: window,
+		Rand:         rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+func (r *Server) loadMealPlan(userUUID string, rpc string) (*pb.WeeklyMealPlan, error) {
+	if userUUID == "" {
+		r.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   rpc},
+			"User ID not specified.")
+		return nil, grpc.Errorf(codes.InvalidArgument, "User ID not specified.")
+	}
+
+	session, err := r.pingedSession(rpc)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	plan := new(pb.WeeklyMealPlan)
+	err = session.DB("recipes").C("mealplans").Find(bson.M{"useruuid": userUUID}).One(plan)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			r.Logger.Error(logrus.Fields{
+				"phase": "process",
+				"event": "fetch",
+				"tag":   "mongodb",
+				"rpc":   rpc},
+				fmt.Sprintf("Meal plan for user %s not found", userUUID))
+			return nil, grpc.Errorf(codes.NotFound, "Meal plan not found.")
+		}
+		errMsg := fmt.Sprintf("Unknown meal plan lookup error: %v", err)
+		r.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "mongodb",
+			"rpc":   rpc},
+			errMsg)
+		return nil, grpc.Errorf(codes.Unknown, errMsg)
+	}
+	return plan, nil
+}
+
+// Plans are stored one per user, replacing any previous plan.
+func (r *Server) saveMealPlan(plan *pb.WeeklyMealPlan, rpc string) error {
+	session, err := r.pingedSession(rpc)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	if _, err := session.DB("recipes").C("mealplans").Upsert(bson.M{"useruuid": plan.Useruuid}, plan); err != nil {
+		errMsg := fmt.Sprintf("Cannot save meal plan for user %s: %v", plan.Useruuid, err)
+		r.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "update",
+			"tag":   "mongodb",
+			"rpc":   rpc},
+			errMsg)
+		return grpc.Errorf(codes.Unknown, errMsg)
+	}
+	return nil
+}
+
+// pingedSession returns a copy of the MongoDB session once the server has
+// answered a ping. Callers must close the returned session.
+func (r *Server) pingedSession(rpc string) (*mgo.Session, error) {
+	if r.MongoSession == nil {
+		r.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "connect",
+			"tag":   "mongodb",
+			"rpc":   rpc},
+			"No MongoDB session, the server is running on an offline recipe store")
+		return nil, grpc.Errorf(codes.Unimplemented, "%s requires the MongoDB recipe store.", rpc)
+	}
+	session := r.MongoSession.Copy()
+	if err := session.Ping(); err != nil {
+		session.Close()
+		r.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "ping",
+			"tag":   "mongodb",
+			"rpc":   rpc},
+			fmt.Sprintf("Cannot ping MongoDB server. Error: %v", err))
+		return nil, grpc.Errorf(codes.Unknown, "Cannot ping MongDB server. Error: %v", err)
+	}
+	return session, nil
+}
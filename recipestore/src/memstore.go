@@ -0,0 +1,268 @@
+/*
+// ----------------------------------------------------------------------------
+// memstore.go
+// Countertop Recipe Microservice In-Memory & File Backed Recipe Repository
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/mgo.v2/bson"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+// MemoryRecipeStore is a RecipeRepository held in memory, used for testing
+// and for running the service offline.
+type MemoryRecipeStore struct {
+	mu      sync.RWMutex
+	recipes map[string]*pb.Recipe
+	packs   map[string]*pb.RecipePack
+}
+
+func NewMemoryRecipeStore(recipes ...*pb.Recipe) *MemoryRecipeStore {
+	store := &MemoryRecipeStore{
+		recipes: make(map[string]*pb.Recipe),
+		packs:   make(map[string]*pb.RecipePack),
+	}
+	store.PutRecipes(recipes...)
+	return store
+}
+
+// LoadFileRecipeStore reads recipes.json and recipepacks.json from the
+// directory into a memory store. The files use the JSON array or newline
+// delimited JSON format written by the bulk export tool, and either may be
+// missing.
+func LoadFileRecipeStore(dir string) (*MemoryRecipeStore, error) {
+	store := NewMemoryRecipeStore()
+	for _, kind := range []documentKind{recipeKind, packKind} {
+		path := filepath.Join(dir, kind.collection+".json")
+		file, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		documents, err := (&BulkLoader{kind: kind}).decode(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+
+		for _, document := range documents {
+			switch value := document.value().(type) {
+			case *pb.Recipe:
+				store.PutRecipes(value)
+			case *pb.RecipePack:
+				store.PutRecipePacks(value)
+			}
+		}
+	}
+	return store, nil
+}
+
+// PutRecipes adds the recipes, replacing any with the same ID.
+func (m *MemoryRecipeStore) PutRecipes(recipes ...*pb.Recipe) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, recipe := range recipes {
+		m.recipes[recipe.Id] = recipe
+	}
+}
+
+// PutRecipePacks adds the recipe packs, replacing any with the same ID.
+func (m *MemoryRecipeStore) PutRecipePacks(packs ...*pb.RecipePack) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, pack := range packs {
+		m.packs[pack.Id] = pack
+	}
+}
+
+func (m *MemoryRecipeStore) GetRecipe(recipeID string) (*pb.Recipe, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	recipe, ok := m.recipes[recipeID]
+	if !ok || recipe.Draft {
+		return nil, ErrRecipeNotFound
+	}
+	return recipe, nil
+}
+
+func (m *MemoryRecipeStore) GetRecipes(recipeIDs []string) ([]*pb.Recipe, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var recipes []*pb.Recipe
+	seen := make(map[string]bool)
+	for _, recipeID := range recipeIDs {
+		if recipe, ok := m.recipes[recipeID]; ok && !recipe.Draft && !seen[recipeID] {
+			recipes = append(recipes, recipe)
+			seen[recipeID] = true
+		}
+	}
+	return recipes, nil
+}
+
+func (m *MemoryRecipeStore) FindRecipes(profile *pb.DietaryProfile, restriction *pb.DietaryRestriction) ([]*pb.Recipe, error) {
+	query, err := recipesQuery(profile, restriction)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var recipes []*pb.Recipe
+	for _, id := range sortedKeys(m.recipes) {
+		matched, err := matchesQuery(m.recipes[id], query)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			recipes = append(recipes, m.recipes[id])
+		}
+	}
+	return recipes, nil
+}
+
+func (m *MemoryRecipeStore) FindRecipePacks(recipePackRequest *pb.RecipePacksRequest) ([]pb.RecipePack, error) {
+	query, err := RecipePackQuery(recipePackRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var recipePacks []pb.RecipePack
+	for _, id := range sortedKeys(m.packs) {
+		matched, err := matchesQuery(m.packs[id], query)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			recipePacks = append(recipePacks, *m.packs[id])
+		}
+	}
+	return recipePacks, nil
+}
+
+func (m *MemoryRecipeStore) Search(searchRequest *pb.RecipeSearchRequest, after string, limit int) ([]*pb.Recipe, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	recipes := []*pb.Recipe{}
+	for _, id := range sortedKeys(m.recipes) {
+		if len(recipes) >= limit {
+			break
+		}
+		if id > after && matchesSearch(m.recipes[id], searchRequest) {
+			recipes = append(recipes, m.recipes[id])
+		}
+	}
+	return recipes, nil
+}
+
+// sortedKeys returns the keys of a map with string keys in order.
+func sortedKeys(documents interface{}) []string {
+	keys := reflect.ValueOf(documents).MapKeys()
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ids = append(ids, key.String())
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// matchesQuery evaluates a query built by PackQueryBuilder against a
+// document the way MongoDB would. Only what the builder emits is supported:
+// $and, $ne and equality on dotted field paths.
+func matchesQuery(document interface{}, query bson.M) (bool, error) {
+	data, err := bson.Marshal(document)
+	if err != nil {
+		return false, err
+	}
+	fields := bson.M{}
+	if err := bson.Unmarshal(data, fields); err != nil {
+		return false, err
+	}
+	return matchesFields(fields, query)
+}
+
+func matchesFields(fields bson.M, query bson.M) (bool, error) {
+	for key, condition := range query {
+		if key == "$and" {
+			clauses, ok := condition.([]bson.M)
+			if !ok {
+				return false, fmt.Errorf("unsupported $and clause %v", condition)
+			}
+			for _, clause := range clauses {
+				if matched, err := matchesFields(fields, clause); !matched || err != nil {
+					return false, err
+				}
+			}
+			continue
+		}
+
+		value, found := lookupField(fields, key)
+		operators, ok := condition.(bson.M)
+		if !ok {
+			if !found || !sameValue(value, condition) {
+				return false, nil
+			}
+			continue
+		}
+		for operator, operand := range operators {
+			if operator != "$ne" {
+				return false, fmt.Errorf("unsupported query operator %s", operator)
+			}
+			if found && sameValue(value, operand) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+func lookupField(fields bson.M, path string) (interface{}, bool) {
+	var value interface{} = fields
+	for _, name := range strings.Split(path, ".") {
+		document, ok := value.(bson.M)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = document[name]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// sameValue compares a stored value with a query operand after converting
+// the operand the same way it would be stored, so that enums compare equal
+// to the integers they are stored as.
+func sameValue(stored interface{}, operand interface{}) bool {
+	data, err := bson.Marshal(bson.M{"v": operand})
+	if err != nil {
+		return false
+	}
+	converted := bson.M{}
+	if err := bson.Unmarshal(data, converted); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(stored, converted["v"])
+}
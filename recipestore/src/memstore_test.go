@@ -0,0 +1,145 @@
+/*
+// ----------------------------------------------------------------------------
+// memstore_test.go
+// Countertop Recipe Microservice In-Memory & File Backed Repository Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+	recipestore "github.com/theorangechefco/cts/recipestore"
+)
+
+func testRecipePacks() []*pb.RecipePack {
+	return []*pb.RecipePack{
+		&pb.RecipePack{
+			Id:                 "p1",
+			Name:               "Omnivore 1500",
+			Dietaryprofile:     &pb.DietaryProfile{Omnivore: true},
+			Dietaryrestriction: &pb.DietaryRestriction{Glutenfree: true},
+			Mealplan:           pb.MealPlan_FIFTEEN_HUNDRED,
+		},
+		&pb.RecipePack{
+			Id:             "p2",
+			Name:           "Vegan 1500",
+			Dietaryprofile: &pb.DietaryProfile{Omnivore: true, Vegetarian: true, Vegan: true},
+			Mealplan:       pb.MealPlan_FIFTEEN_HUNDRED,
+		},
+		&pb.RecipePack{
+			Id:             "p3",
+			Name:           "Vegan 1800",
+			Dietaryprofile: &pb.DietaryProfile{Omnivore: true, Vegetarian: true, Vegan: true},
+			Mealplan:       pb.MealPlan_EIGHTEEN_HUNDRED,
+		},
+		&pb.RecipePack{
+			Id:             "p4",
+			Name:           "Unpublished vegan 1500",
+			Dietaryprofile: &pb.DietaryProfile{Omnivore: true, Vegetarian: true, Vegan: true},
+			Mealplan:       pb.MealPlan_FIFTEEN_HUNDRED,
+			Draft:          true,
+		},
+	}
+}
+
+func packIDs(recipePacks []pb.RecipePack) []string {
+	ids := []string{}
+	for _, recipePack := range recipePacks {
+		ids = append(ids, recipePack.Id)
+	}
+	return ids
+}
+
+func TestMemoryStoreFindRecipePacks(t *testing.T) {
+	store := recipestore.NewMemoryRecipeStore()
+	store.PutRecipePacks(testRecipePacks()...)
+
+	cases := []struct {
+		name    string
+		request pb.RecipePacksRequest
+		want    []string
+	}{
+		{"omnivore", pb.RecipePacksRequest{Dietaryprofile: &pb.DietaryProfile{Omnivore: true}, Mealplan: pb.MealPlan_FIFTEEN_HUNDRED}, []string{"p1", "p2"}},
+		{"vegan", pb.RecipePacksRequest{Dietaryprofile: &pb.DietaryProfile{Vegan: true}, Mealplan: pb.MealPlan_FIFTEEN_HUNDRED}, []string{"p2"}},
+		{"gluten free", pb.RecipePacksRequest{
+			Dietaryprofile:     &pb.DietaryProfile{Omnivore: true},
+			Dietaryrestriction: &pb.DietaryRestriction{Glutenfree: true},
+			Mealplan:           pb.MealPlan_FIFTEEN_HUNDRED}, []string{"p1"}},
+		{"meal plan", pb.RecipePacksRequest{Dietaryprofile: &pb.DietaryProfile{Vegan: true}, Mealplan: pb.MealPlan_EIGHTEEN_HUNDRED}, []string{"p3"}},
+		{"no match", pb.RecipePacksRequest{Dietaryprofile: &pb.DietaryProfile{Vegan: true, Raw: true}}, []string{}},
+	}
+	for _, c := range cases {
+		recipePacks, err := store.FindRecipePacks(&c.request)
+		if err != nil {
+			t.Errorf("%s: FindRecipePacks(_) = _, %v", c.name, err)
+			continue
+		}
+		if got := packIDs(recipePacks); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: FindRecipePacks(_) = %v, want %v", c.name, got, c.want)
+		}
+	}
+
+	if _, err := store.FindRecipePacks(&pb.RecipePacksRequest{}); err != recipestore.ErrNoDietaryProfile {
+		t.Errorf("FindRecipePacks(no profile) = _, %v, want %v", err, recipestore.ErrNoDietaryProfile)
+	}
+}
+
+func TestMemoryStoreGetRecipe(t *testing.T) {
+	recipes := testRecipes()
+	recipes[1].Draft = true
+	store := recipestore.NewMemoryRecipeStore(recipes...)
+
+	if recipe, err := store.GetRecipe("1"); err != nil || recipe.Name != "Kale Caesar Salad" {
+		t.Errorf("GetRecipe(1) = %v, %v", recipe, err)
+	}
+	for _, id := range []string{"2", "missing"} {
+		if _, err := store.GetRecipe(id); err != recipestore.ErrRecipeNotFound {
+			t.Errorf("GetRecipe(%s) = _, %v, want %v", id, err, recipestore.ErrRecipeNotFound)
+		}
+	}
+
+	found, err := store.GetRecipes([]string{"3", "2", "missing", "1", "3"})
+	if err != nil || len(found) != 2 {
+		t.Errorf("GetRecipes(_) = %v, %v, want recipes 3 and 1", found, err)
+	}
+}
+
+func TestLoadFileRecipeStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recipestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Only recipes are provided, the recipe pack file is optional
+	if err := ioutil.WriteFile(filepath.Join(dir, "recipes.json"), []byte(bulkRecipe+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := recipestore.LoadFileRecipeStore(dir)
+	if err != nil {
+		t.Fatalf("LoadFileRecipeStore(_) = _, %v", err)
+	}
+	if recipe, err := store.GetRecipe("41"); err != nil || recipe.Name != "Lentil Soup" {
+		t.Errorf("GetRecipe(41) = %v, %v", recipe, err)
+	}
+	recipes, err := store.FindRecipes(&pb.DietaryProfile{Vegan: true}, nil)
+	if err != nil || len(recipes) != 1 {
+		t.Errorf("FindRecipes(vegan) = %v, %v, want recipe 41", recipes, err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "recipepacks.json"), []byte(`{"id": "p1"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recipestore.LoadFileRecipeStore(dir); err == nil {
+		t.Errorf("LoadFileRecipeStore(_) = _, nil, want an error for an invalid recipe pack")
+	}
+}
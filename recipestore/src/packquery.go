@@ -0,0 +1,120 @@
+/*
+// ----------------------------------------------------------------------------
+// packquery.go
+// Countertop Recipe Microservice Recipe Pack Query Builder
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore
+
+import (
+	"errors"
+
+	"gopkg.in/mgo.v2/bson"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+var ErrNoDietaryProfile = errors.New("Dietary Profile not provided")
+
+// Documents loaded out of band have no draft field, so match on it not being
+// set rather than on it being false.
+var publishedClause = bson.M{"draft": bson.M{"$ne": true}}
+
+// PackQueryBuilder composes a MongoDB query against the recipepacks
+// collection, or against the recipes collection when no meal plan is
+// added. Every clause added narrows the result set, so a pack or recipe has
+// to satisfy all of the requested profile and restriction flags.
+type PackQueryBuilder struct {
+	clauses []bson.M
+	err     error
+}
+
+func NewPackQueryBuilder() *PackQueryBuilder {
+	return &PackQueryBuilder{clauses: []bson.M{}}
+}
+
+// Require adds a clause requiring the boolean field to be set on the pack.
+func (b *PackQueryBuilder) Require(field string) *PackQueryBuilder {
+	b.clauses = append(b.clauses, bson.M{field: true})
+	return b
+}
+
+// DietaryProfile narrows packs to the most restrictive profile selected:
+// vegan packs suit vegetarians and omnivores, vegetarian packs suit
+// omnivores, and any pack suits an omnivore. Raw is layered on top.
+func (b *PackQueryBuilder) DietaryProfile(profile *pb.DietaryProfile) *PackQueryBuilder {
+	if profile == nil || !(profile.Omnivore || profile.Vegetarian || profile.Vegan) {
+		b.err = ErrNoDietaryProfile
+		return b
+	}
+
+	switch {
+	case profile.Vegan:
+		b.Require("dietaryprofile.vegan")
+	case profile.Vegetarian:
+		b.Require("dietaryprofile.vegetarian")
+	}
+	if profile.Raw {
+		b.Require("dietaryprofile.raw")
+	}
+	return b
+}
+
+// DietaryRestriction requires every restriction the user has. Restrictions
+// the user does not have are left unconstrained.
+func (b *PackQueryBuilder) DietaryRestriction(restriction *pb.DietaryRestriction) *PackQueryBuilder {
+	if restriction == nil {
+		return b
+	}
+
+	if restriction.Glutenfree {
+		b.Require("dietaryrestriction.glutenfree")
+	}
+	if restriction.Nutfree {
+		b.Require("dietaryrestriction.nutfree")
+	}
+	if restriction.Dairyfree {
+		b.Require("dietaryrestriction.dairyfree")
+	}
+	if restriction.Soyfree {
+		b.Require("dietaryrestriction.soyfree")
+	}
+	if restriction.Lowsodium {
+		b.Require("dietaryrestriction.lowsodium")
+	}
+	return b
+}
+
+// Published leaves out drafts that have been authored but not published yet.
+func (b *PackQueryBuilder) Published() *PackQueryBuilder {
+	b.clauses = append(b.clauses, publishedClause)
+	return b
+}
+
+func (b *PackQueryBuilder) MealPlan(mealPlan pb.MealPlan) *PackQueryBuilder {
+	b.clauses = append(b.clauses, bson.M{"mealplan": mealPlan})
+	return b
+}
+
+func (b *PackQueryBuilder) Build() (bson.M, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.clauses) == 0 {
+		return bson.M{}, nil
+	}
+	return bson.M{"$and": b.clauses}, nil
+}
+
+// RecipePackQuery builds the query for a RecipePacksRequest.
+func RecipePackQuery(recipePackRequest *pb.RecipePacksRequest) (bson.M, error) {
+	return NewPackQueryBuilder().
+		DietaryProfile(recipePackRequest.Dietaryprofile).
+		DietaryRestriction(recipePackRequest.Dietaryrestriction).
+		MealPlan(recipePackRequest.Mealplan).
+		Published().
+		Build()
+}
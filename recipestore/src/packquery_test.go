@@ -0,0 +1,130 @@
+/*
+// ----------------------------------------------------------------------------
+// packquery_test.go
+// Countertop Recipe Microservice Recipe Pack Query Builder Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore_test
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+	recipestore "github.com/theorangechefco/cts/recipestore"
+)
+
+var profileCases = []struct {
+	name    string
+	profile pb.DietaryProfile
+	want    []string
+	invalid bool
+}{
+	{"none", pb.DietaryProfile{}, nil, true},
+	{"raw only", pb.DietaryProfile{Raw: true}, nil, true},
+	{"omnivore", pb.DietaryProfile{Omnivore: true}, nil, false},
+	{"omnivore raw", pb.DietaryProfile{Omnivore: true, Raw: true}, []string{"dietaryprofile.raw"}, false},
+	{"vegetarian", pb.DietaryProfile{Vegetarian: true}, []string{"dietaryprofile.vegetarian"}, false},
+	{"vegetarian raw", pb.DietaryProfile{Vegetarian: true, Raw: true}, []string{"dietaryprofile.vegetarian", "dietaryprofile.raw"}, false},
+	{"vegan", pb.DietaryProfile{Vegan: true}, []string{"dietaryprofile.vegan"}, false},
+	{"vegan raw", pb.DietaryProfile{Vegan: true, Raw: true}, []string{"dietaryprofile.vegan", "dietaryprofile.raw"}, false},
+	{"omnivore vegetarian", pb.DietaryProfile{Omnivore: true, Vegetarian: true}, []string{"dietaryprofile.vegetarian"}, false},
+	{"omnivore vegetarian raw", pb.DietaryProfile{Omnivore: true, Vegetarian: true, Raw: true}, []string{"dietaryprofile.vegetarian", "dietaryprofile.raw"}, false},
+	{"omnivore vegan", pb.DietaryProfile{Omnivore: true, Vegan: true}, []string{"dietaryprofile.vegan"}, false},
+	{"omnivore vegan raw", pb.DietaryProfile{Omnivore: true, Vegan: true, Raw: true}, []string{"dietaryprofile.vegan", "dietaryprofile.raw"}, false},
+	{"vegetarian vegan", pb.DietaryProfile{Vegetarian: true, Vegan: true}, []string{"dietaryprofile.vegan"}, false},
+	{"vegetarian vegan raw", pb.DietaryProfile{Vegetarian: true, Vegan: true, Raw: true}, []string{"dietaryprofile.vegan", "dietaryprofile.raw"}, false},
+	{"all", pb.DietaryProfile{Omnivore: true, Vegetarian: true, Vegan: true}, []string{"dietaryprofile.vegan"}, false},
+	{"all raw", pb.DietaryProfile{Omnivore: true, Vegetarian: true, Vegan: true, Raw: true}, []string{"dietaryprofile.vegan", "dietaryprofile.raw"}, false},
+}
+
+var restrictionFields = []string{
+	"dietaryrestriction.glutenfree",
+	"dietaryrestriction.nutfree",
+	"dietaryrestriction.dairyfree",
+	"dietaryrestriction.soyfree",
+	"dietaryrestriction.lowsodium",
+}
+
+// restrictionCase expands the bitmask into a DietaryRestriction and the
+// clauses it should produce, in the order the builder adds them.
+func restrictionCase(mask uint) (*pb.DietaryRestriction, []string) {
+	restriction := &pb.DietaryRestriction{
+		Glutenfree: mask&1 != 0,
+		Nutfree:    mask&2 != 0,
+		Dairyfree:  mask&4 != 0,
+		Soyfree:    mask&8 != 0,
+		Lowsodium:  mask&16 != 0,
+	}
+	var fields []string
+	for i, field := range restrictionFields {
+		if mask&(1<<uint(i)) != 0 {
+			fields = append(fields, field)
+		}
+	}
+	return restriction, fields
+}
+
+func expectedQuery(fields []string, mealPlan pb.MealPlan) bson.M {
+	clauses := []bson.M{}
+	for _, field := range fields {
+		clauses = append(clauses, bson.M{field: true})
+	}
+	clauses = append(clauses, bson.M{"mealplan": mealPlan})
+	clauses = append(clauses, bson.M{"draft": bson.M{"$ne": true}})
+	return bson.M{"$and": clauses}
+}
+
+func TestRecipePackQueryAllCombinations(t *testing.T) {
+	for _, profileCase := range profileCases {
+		for mask := uint(0); mask < 1<<uint(len(restrictionFields)); mask++ {
+			profile := profileCase.profile
+			restriction, restrictionClauses := restrictionCase(mask)
+			request := pb.RecipePacksRequest{
+				Dietaryprofile:     &profile,
+				Dietaryrestriction: restriction,
+				Mealplan:           pb.MealPlan_EIGHTEEN_HUNDRED,
+			}
+
+			query, err := recipestore.RecipePackQuery(&request)
+			if profileCase.invalid {
+				if err != recipestore.ErrNoDietaryProfile {
+					t.Errorf("%s/%05b: RecipePackQuery(_) = _, %v, want ErrNoDietaryProfile", profileCase.name, mask, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s/%05b: RecipePackQuery(_) = _, %v", profileCase.name, mask, err)
+				continue
+			}
+
+			want := expectedQuery(append(append([]string{}, profileCase.want...), restrictionClauses...), request.Mealplan)
+			if !reflect.DeepEqual(query, want) {
+				t.Errorf("%s/%05b: RecipePackQuery(_) = %v, want %v", profileCase.name, mask, query, want)
+			}
+		}
+	}
+}
+
+func TestRecipePackQueryMissingFields(t *testing.T) {
+	if _, err := recipestore.RecipePackQuery(&pb.RecipePacksRequest{}); err != recipestore.ErrNoDietaryProfile {
+		t.Errorf("RecipePackQuery(_) without profile = _, %v, want ErrNoDietaryProfile", err)
+	}
+
+	request := pb.RecipePacksRequest{
+		Dietaryprofile: &pb.DietaryProfile{Vegan: true},
+		Mealplan:       pb.MealPlan_EIGHTEEN_HUNDRED,
+	}
+	query, err := recipestore.RecipePackQuery(&request)
+	if err != nil {
+		t.Fatalf("RecipePackQuery(_) without restrictions = _, %v", err)
+	}
+	if want := expectedQuery([]string{"dietaryprofile.vegan"}, request.Mealplan); !reflect.DeepEqual(query, want) {
+		t.Errorf("RecipePackQuery(_) = %v, want %v", query, want)
+	}
+}
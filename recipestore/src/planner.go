@@ -0,0 +1,233 @@
+/*
+// ----------------------------------------------------------------------------
+// planner.go
+// Countertop Recipe Microservice Weekly Meal Planner
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+const (
+	DaysPerPlan         = 7
+	DefaultRepeatWindow = 3
+	plannerChoices      = 3
+)
+
+var (
+	ErrNoCandidateRecipes = errors.New("no recipes match the dietary profile")
+	ErrNoAlternativeMeal  = errors.New("no alternative recipe available for meal")
+	ErrMealNotInPlan      = errors.New("meal not found in plan")
+)
+
+// Each day is made up of these meals, with the share of the daily calorie
+// target each one should aim for.
+var dailyMeals = []struct {
+	course pb.MealCourse
+	share  float64
+}{
+	{pb.MealCourse_BREAKFAST, 0.25},
+	{pb.MealCourse_LUNCHANDDINNER, 0.30},
+	{pb.MealCourse_LUNCHANDDINNER, 0.30},
+	{pb.MealCourse_SNACK, 0.10},
+	{pb.MealCourse_DESSERT, 0.05},
+}
+
+// Planner assembles weekly meal plans out of candidate recipes. Recipes are
+// not repeated within RepeatWindow days of each other unless there is no
+// other option for a meal.
+type Planner struct {
+	Candidates   []*pb.Recipe
+	RepeatWindow int
+	// Rand picks between the closest matching recipes; if nil, the closest
+	// match is always used.
+	Rand *rand.Rand
+}
+
+// recipeUsage maps recipe IDs to the plan days they are used on.
+type recipeUsage map[string][]int
+
+func (u recipeUsage) add(recipeID string, day int) {
+	u[recipeID] = append(u[recipeID], day)
+}
+
+// distance returns how many days away the closest use of the recipe is.
+func (u recipeUsage) distance(recipeID string, day int) int {
+	closest := math.MaxInt32
+	for _, used := range u[recipeID] {
+		d := day - used
+		if d < 0 {
+			d = -d
+		}
+		if d < closest {
+			closest = d
+		}
+	}
+	return closest
+}
+
+type mealCandidate struct {
+	recipe   *pb.Recipe
+	distance int
+	offset   float64
+}
+
+type byCalorieOffset []mealCandidate
+
+func (c byCalorieOffset) Len() int           { return len(c) }
+func (c byCalorieOffset) Less(i, j int) bool { return c[i].offset < c[j].offset }
+func (c byCalorieOffset) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+
+type byLeastRecentlyUsed []mealCandidate
+
+func (c byLeastRecentlyUsed) Len() int      { return len(c) }
+func (c byLeastRecentlyUsed) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c byLeastRecentlyUsed) Less(i, j int) bool {
+	if c[i].distance != c[j].distance {
+		return c[i].distance > c[j].distance
+	}
+	return c[i].offset < c[j].offset
+}
+
+func servesCourse(recipe *pb.Recipe, course pb.MealCourse) bool {
+	if recipe.Mealcourses == nil {
+		return false
+	}
+	switch course {
+	case pb.MealCourse_BREAKFAST:
+		return recipe.Mealcourses.Breakfast
+	case pb.MealCourse_LUNCHANDDINNER:
+		return recipe.Mealcourses.Lunchanddinner
+	case pb.MealCourse_SNACK:
+		return recipe.Mealcourses.Snacks
+	case pb.MealCourse_DESSERT:
+		return recipe.Mealcourses.Dessert
+	}
+	return false
+}
+
+func recipeCalories(recipe *pb.Recipe) float64 {
+	if recipe.Nutrition == nil {
+		return 0
+	}
+	return float64(recipe.Nutrition.Calories)
+}
+
+// pick chooses a recipe for a meal on the given day, preferring recipes that
+// have not been used within the repeat window and whose calories are closest
+// to the target. The recipe with the ID in exclude is never chosen.
+func (p *Planner) pick(course pb.MealCourse, target float64, day int, usage recipeUsage, exclude string) *pb.Recipe {
+	var fresh, stale []mealCandidate
+	for _, recipe := range p.Candidates {
+		if recipe.Id == exclude || !servesCourse(recipe, course) {
+			continue
+		}
+		c := mealCandidate{recipe, usage.distance(recipe.Id, day), math.Abs(recipeCalories(recipe) - target)}
+		if c.distance > p.RepeatWindow {
+			fresh = append(fresh, c)
+		} else {
+			stale = append(stale, c)
+		}
+	}
+
+	if len(fresh) > 0 {
+		sort.Stable(byCalorieOffset(fresh))
+		choices := plannerChoices
+		if len(fresh) < choices {
+			choices = len(fresh)
+		}
+		if p.Rand == nil {
+			return fresh[0].recipe
+		}
+		return fresh[p.Rand.Intn(choices)].recipe
+	}
+
+	// Everything was used recently, fall back to the least recently used.
+	// Never repeat a recipe on the same day though.
+	sort.Stable(byLeastRecentlyUsed(stale))
+	if len(stale) == 0 || stale[0].distance == 0 {
+		return nil
+	}
+	return stale[0].recipe
+}
+
+func dayCalories(dayPlan *pb.DayPlan) float32 {
+	var calories float32
+	for _, meal := range dayPlan.Meals {
+		calories += float32(recipeCalories(meal.Recipe))
+	}
+	return calories
+}
+
+// PlanWeek builds a seven day plan aiming for dailyCalories per day. Meals
+// for which no recipe is available are left out of the plan.
+func (p *Planner) PlanWeek(userUUID string, dailyCalories int32) (*pb.WeeklyMealPlan, error) {
+	if len(p.Candidates) == 0 {
+		return nil, ErrNoCandidateRecipes
+	}
+
+	plan := &pb.WeeklyMealPlan{Useruuid: userUUID, Dailycalories: dailyCalories}
+	usage := make(recipeUsage)
+	for day := 0; day < DaysPerPlan; day++ {
+		dayPlan := &pb.DayPlan{Day: int32(day)}
+		for _, meal := range dailyMeals {
+			recipe := p.pick(meal.course, meal.share*float64(dailyCalories), day, usage, "")
+			if recipe == nil {
+				continue
+			}
+			usage.add(recipe.Id, day)
+			dayPlan.Meals = append(dayPlan.Meals, &pb.PlannedMeal{Course: meal.course, Recipe: recipe})
+		}
+		dayPlan.Calories = dayCalories(dayPlan)
+		plan.Days = append(plan.Days, dayPlan)
+	}
+	return plan, nil
+}
+
+// ReplanMeal swaps the recipe for a single meal in an existing plan for a
+// different one, keeping the rest of the plan unchanged.
+func (p *Planner) ReplanMeal(plan *pb.WeeklyMealPlan, day int, meal int) error {
+	if day < 0 || day >= len(plan.Days) || meal < 0 || meal >= len(plan.Days[day].Meals) {
+		return ErrMealNotInPlan
+	}
+
+	usage := make(recipeUsage)
+	for d, dayPlan := range plan.Days {
+		for m, plannedMeal := range dayPlan.Meals {
+			if (d != day || m != meal) && plannedMeal.Recipe != nil {
+				usage.add(plannedMeal.Recipe.Id, d)
+			}
+		}
+	}
+
+	current := plan.Days[day].Meals[meal]
+	target := float64(plan.Dailycalories)
+	for _, dailyMeal := range dailyMeals {
+		if dailyMeal.course == current.Course {
+			target *= dailyMeal.share
+			break
+		}
+	}
+
+	var currentID string
+	if current.Recipe != nil {
+		currentID = current.Recipe.Id
+	}
+	recipe := p.pick(current.Course, target, day, usage, currentID)
+	if recipe == nil {
+		return ErrNoAlternativeMeal
+	}
+	current.Recipe = recipe
+	plan.Days[day].Calories = dayCalories(plan.Days[day])
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+// ----------------------------------------------------------------------------
+// planner_test.go
+// Countertop Recipe Microservice Weekly Meal Planner Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+	recipestore "github.com/theorangechefco/cts/recipestore"
+)
+
+// plannerRecipes returns count recipes for the course, with calories
+// spread around the given value.
+func plannerRecipes(prefix string, count int, courses pb.MealCourses, calories float32) []*pb.Recipe {
+	var recipes []*pb.Recipe
+	for i := 0; i < count; i++ {
+		mealCourses := courses
+		recipes = append(recipes, &pb.Recipe{
+			Id:          fmt.Sprintf("%s-%d", prefix, i),
+			Mealcourses: &mealCourses,
+			Nutrition:   &pb.Nutrition{Calories: calories + float32(i*10)},
+		})
+	}
+	return recipes
+}
+
+func plannerCandidates() []*pb.Recipe {
+	var recipes []*pb.Recipe
+	recipes = append(recipes, plannerRecipes("breakfast", 5, pb.MealCourses{Breakfast: true}, 450)...)
+	recipes = append(recipes, plannerRecipes("main", 9, pb.MealCourses{Lunchanddinner: true}, 550)...)
+	recipes = append(recipes, plannerRecipes("snack", 4, pb.MealCourses{Snacks: true}, 180)...)
+	recipes = append(recipes, plannerRecipes("dessert", 4, pb.MealCourses{Dessert: true}, 90)...)
+	return recipes
+}
+
+func checkRepeatWindow(t *testing.T, plan *pb.WeeklyMealPlan, window int) {
+	lastUsed := make(map[string]int)
+	for day, dayPlan := range plan.Days {
+		for _, meal := range dayPlan.Meals {
+			if last, ok := lastUsed[meal.Recipe.Id]; ok && day-last <= window {
+				t.Errorf("Recipe %s used on day %d and again on day %d", meal.Recipe.Id, last, day)
+			}
+			lastUsed[meal.Recipe.Id] = day
+		}
+	}
+}
+
+func TestPlanWeek(t *testing.T) {
+	planner := recipestore.Planner{
+		Candidates:   plannerCandidates(),
+		RepeatWindow: 3,
+		Rand:         rand.New(rand.NewSource(42)),
+	}
+
+	plan, err := planner.PlanWeek("user", 1800)
+	if err != nil {
+		t.Fatalf("PlanWeek(_) = _, %v", err)
+	}
+	if len(plan.Days) != recipestore.DaysPerPlan {
+		t.Fatalf("Plan has %d days, want %d", len(plan.Days), recipestore.DaysPerPlan)
+	}
+
+	for _, dayPlan := range plan.Days {
+		if len(dayPlan.Meals) != 5 {
+			t.Errorf("Day %d has %d meals, want 5", dayPlan.Day, len(dayPlan.Meals))
+		}
+		if dayPlan.Calories < 1500 || dayPlan.Calories > 2100 {
+			t.Errorf("Day %d has %v calories, too far from 1800", dayPlan.Day, dayPlan.Calories)
+		}
+	}
+	checkRepeatWindow(t, plan, 3)
+}
+
+func TestPlanWeekFallsBackToLeastRecentlyUsed(t *testing.T) {
+	planner := recipestore.Planner{
+		Candidates:   plannerRecipes("main", 3, pb.MealCourses{Lunchanddinner: true}, 500),
+		RepeatWindow: 3,
+	}
+
+	plan, err := planner.PlanWeek("user", 1800)
+	if err != nil {
+		t.Fatalf("PlanWeek(_) = _, %v", err)
+	}
+	for _, dayPlan := range plan.Days {
+		if len(dayPlan.Meals) != 2 {
+			t.Fatalf("Day %d has %d meals, want lunch and dinner only", dayPlan.Day, len(dayPlan.Meals))
+		}
+		if dayPlan.Meals[0].Recipe.Id == dayPlan.Meals[1].Recipe.Id {
+			t.Errorf("Day %d repeats recipe %s", dayPlan.Day, dayPlan.Meals[0].Recipe.Id)
+		}
+	}
+}
+
+func TestPlanWeekNoCandidates(t *testing.T) {
+	planner := recipestore.Planner{RepeatWindow: 3}
+	if _, err := planner.PlanWeek("user", 1800); err != recipestore.ErrNoCandidateRecipes {
+		t.Errorf("PlanWeek(_) = _, %v, want ErrNoCandidateRecipes", err)
+	}
+}
+
+func TestReplanMeal(t *testing.T) {
+	planner := recipestore.Planner{
+		Candidates:   plannerCandidates(),
+		RepeatWindow: 2,
+	}
+	plan, err := planner.PlanWeek("user", 1800)
+	if err != nil {
+		t.Fatalf("PlanWeek(_) = _, %v", err)
+	}
+
+	before := plan.Days[3].Meals[1].Recipe.Id
+	breakfast := plan.Days[3].Meals[0].Recipe.Id
+	if err := planner.ReplanMeal(plan, 3, 1); err != nil {
+		t.Fatalf("ReplanMeal(_) = %v", err)
+	}
+	if plan.Days[3].Meals[1].Recipe.Id == before {
+		t.Errorf("ReplanMeal(_) kept recipe %s", before)
+	}
+	if plan.Days[3].Meals[0].Recipe.Id != breakfast {
+		t.Errorf("ReplanMeal(_) changed another meal")
+	}
+	checkRepeatWindow(t, plan, 2)
+
+	if err := planner.ReplanMeal(plan, 7, 0); err != recipestore.ErrMealNotInPlan {
+		t.Errorf("ReplanMeal(_) on missing day = %v, want ErrMealNotInPlan", err)
+	}
+}
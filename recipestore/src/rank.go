@@ -0,0 +1,100 @@
+/*
+// ----------------------------------------------------------------------------
+// rank.go
+// Countertop Recipe Microservice Recipe Pack Ranking
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore
+
+import (
+	"sort"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+// Weight given to a pack's carbohydrate share at each activity level, so
+// that more active users are offered more carbohydrates. Unknown levels are
+// weighted as sedentary.
+var activityCarbWeights = map[pb.ActivityLevel]float64{
+	pb.ActivityLevel_SEDENTARY:         0,
+	pb.ActivityLevel_LIGHTLY_ACTIVE:    0.1,
+	pb.ActivityLevel_MODERATELY_ACTIVE: 0.2,
+	pb.ActivityLevel_VERY_ACTIVE:       0.3,
+	pb.ActivityLevel_EXTRA_ACTIVE:      0.4,
+}
+
+// PackNutrition returns the average per-recipe nutrition of a recipe pack.
+func PackNutrition(pack *pb.RecipePack) pb.Nutrition {
+	var total pb.Nutrition
+	count := 0
+	for _, recipe := range pack.Recipes {
+		if recipe == nil || recipe.Nutrition == nil {
+			continue
+		}
+		total.Calories += recipe.Nutrition.Calories
+		total.Protein += recipe.Nutrition.Protein
+		total.Carbohydrates += recipe.Nutrition.Carbohydrates
+		total.Fat += recipe.Nutrition.Fat
+		total.Sodium += recipe.Nutrition.Sodium
+		count++
+	}
+	if count == 0 {
+		return total
+	}
+	n := float32(count)
+	return pb.Nutrition{
+		Calories:      total.Calories / n,
+		Protein:       total.Protein / n,
+		Carbohydrates: total.Carbohydrates / n,
+		Fat:           total.Fat / n,
+		Sodium:        total.Sodium / n,
+	}
+}
+
+// PackScore favours protein dense packs, penalises calories when losing
+// weight and rewards them when gaining, and leans towards carbohydrates
+// as the user's activity level increases. Packs without nutrition score 0.
+func PackScore(pack *pb.RecipePack, weightGoal pb.WeightGoal, activityLevel pb.ActivityLevel) float64 {
+	nutrition := PackNutrition(pack)
+	if nutrition.Calories <= 0 {
+		return 0
+	}
+
+	calories := float64(nutrition.Calories)
+	proteinShare := float64(nutrition.Protein) * 4 / calories
+	carbShare := float64(nutrition.Carbohydrates) * 4 / calories
+
+	score := proteinShare + carbShare*activityCarbWeights[activityLevel]
+	switch weightGoal {
+	case pb.WeightGoal_LOSE:
+		score -= calories / 1000
+	case pb.WeightGoal_GAIN:
+		score += calories / 1000
+	}
+	return score
+}
+
+type rankedPacks struct {
+	packs  []pb.RecipePack
+	scores []float64
+}
+
+func (r rankedPacks) Len() int           { return len(r.packs) }
+func (r rankedPacks) Less(i, j int) bool { return r.scores[i] > r.scores[j] }
+func (r rankedPacks) Swap(i, j int) {
+	r.packs[i], r.packs[j] = r.packs[j], r.packs[i]
+	r.scores[i], r.scores[j] = r.scores[j], r.scores[i]
+}
+
+// RankRecipePacks orders packs best first for the given weight goal and
+// activity level. Packs with equal scores keep their original order.
+func RankRecipePacks(packs []pb.RecipePack, weightGoal pb.WeightGoal, activityLevel pb.ActivityLevel) {
+	ranked := rankedPacks{packs: packs, scores: make([]float64, len(packs))}
+	for i := range packs {
+		ranked.scores[i] = PackScore(&packs[i], weightGoal, activityLevel)
+	}
+	sort.Stable(ranked)
+}
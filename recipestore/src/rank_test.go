@@ -0,0 +1,122 @@
+/*
+// ----------------------------------------------------------------------------
+// rank_test.go
+// Countertop Recipe Microservice Recipe Pack Ranking Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+	recipestore "github.com/theorangechefco/cts/recipestore"
+)
+
+// nutritionPack returns a pack of one recipe with the given calories and
+// grams of protein and carbohydrates.
+func nutritionPack(id string, calories, protein, carbohydrates float32) pb.RecipePack {
+	return pb.RecipePack{
+		Id: id,
+		Recipes: []*pb.Recipe{{
+			Id:        id + "-recipe",
+			Nutrition: &pb.Nutrition{Calories: calories, Protein: protein, Carbohydrates: carbohydrates},
+		}},
+	}
+}
+
+func TestPackNutrition(t *testing.T) {
+	pack := pb.RecipePack{Recipes: []*pb.Recipe{
+		{Nutrition: &pb.Nutrition{Calories: 400, Protein: 30, Sodium: 500}},
+		{Nutrition: &pb.Nutrition{Calories: 600, Protein: 10, Sodium: 300}},
+		{},
+		nil,
+	}}
+	want := pb.Nutrition{Calories: 500, Protein: 20, Sodium: 400}
+	if got := recipestore.PackNutrition(&pack); got != want {
+		t.Errorf("PackNutrition(_) = %+v, want %+v", got, want)
+	}
+	if got := recipestore.PackNutrition(&pb.RecipePack{}); got != (pb.Nutrition{}) {
+		t.Errorf("PackNutrition(empty) = %+v, want zero", got)
+	}
+}
+
+func TestPackScore(t *testing.T) {
+	// 20% of calories from protein and 40% from carbohydrates
+	pack := nutritionPack("p", 500, 25, 50)
+	tests := []struct {
+		name          string
+		pack          pb.RecipePack
+		weightGoal    pb.WeightGoal
+		activityLevel pb.ActivityLevel
+		want          float64
+	}{
+		{"maintain sedentary", pack, pb.WeightGoal_MAINTAIN, pb.ActivityLevel_SEDENTARY, 0.2},
+		{"maintain lightly active", pack, pb.WeightGoal_MAINTAIN, pb.ActivityLevel_LIGHTLY_ACTIVE, 0.24},
+		{"maintain moderately active", pack, pb.WeightGoal_MAINTAIN, pb.ActivityLevel_MODERATELY_ACTIVE, 0.28},
+		{"maintain very active", pack, pb.WeightGoal_MAINTAIN, pb.ActivityLevel_VERY_ACTIVE, 0.32},
+		{"maintain extra active", pack, pb.WeightGoal_MAINTAIN, pb.ActivityLevel_EXTRA_ACTIVE, 0.36},
+		{"unknown activity level", pack, pb.WeightGoal_MAINTAIN, pb.ActivityLevel(99), 0.2},
+		{"lose", pack, pb.WeightGoal_LOSE, pb.ActivityLevel_SEDENTARY, -0.3},
+		{"gain", pack, pb.WeightGoal_GAIN, pb.ActivityLevel_MODERATELY_ACTIVE, 0.78},
+		{"no nutrition", pb.RecipePack{Recipes: []*pb.Recipe{{Id: "1"}}}, pb.WeightGoal_GAIN, pb.ActivityLevel_EXTRA_ACTIVE, 0},
+	}
+	for _, test := range tests {
+		if got := recipestore.PackScore(&test.pack, test.weightGoal, test.activityLevel); math.Abs(got-test.want) > 1e-6 {
+			t.Errorf("%s: PackScore(_) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRankRecipePacks(t *testing.T) {
+	packs := []pb.RecipePack{
+		nutritionPack("light", 300, 30, 20),
+		nutritionPack("hearty", 900, 45, 100),
+		nutritionPack("carby", 600, 15, 120),
+		{Id: "empty"},
+	}
+	tests := []struct {
+		name          string
+		weightGoal    pb.WeightGoal
+		activityLevel pb.ActivityLevel
+		want          []string
+	}{
+		{"maintain sedentary", pb.WeightGoal_MAINTAIN, pb.ActivityLevel_SEDENTARY, []string{"light", "hearty", "carby", "empty"}},
+		{"maintain very active", pb.WeightGoal_MAINTAIN, pb.ActivityLevel_VERY_ACTIVE, []string{"light", "carby", "hearty", "empty"}},
+		{"lose sedentary", pb.WeightGoal_LOSE, pb.ActivityLevel_SEDENTARY, []string{"light", "empty", "carby", "hearty"}},
+		{"gain extra active", pb.WeightGoal_GAIN, pb.ActivityLevel_EXTRA_ACTIVE, []string{"hearty", "carby", "light", "empty"}},
+	}
+	for _, test := range tests {
+		ranked := append([]pb.RecipePack(nil), packs...)
+		recipestore.RankRecipePacks(ranked, test.weightGoal, test.activityLevel)
+		var ids []string
+		for _, pack := range ranked {
+			ids = append(ids, pack.Id)
+		}
+		if !reflect.DeepEqual(ids, test.want) {
+			t.Errorf("%s: RankRecipePacks(_) ordered %v, want %v", test.name, ids, test.want)
+		}
+	}
+}
+
+func TestRankRecipePacksKeepsOrderOfEqualScores(t *testing.T) {
+	packs := []pb.RecipePack{
+		nutritionPack("first", 500, 25, 50),
+		nutritionPack("best", 500, 50, 50),
+		nutritionPack("second", 500, 25, 50),
+		nutritionPack("third", 500, 25, 50),
+	}
+	recipestore.RankRecipePacks(packs, pb.WeightGoal_MAINTAIN, pb.ActivityLevel_LIGHTLY_ACTIVE)
+	var ids []string
+	for _, pack := range packs {
+		ids = append(ids, pack.Id)
+	}
+	if want := []string{"best", "first", "second", "third"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("RankRecipePacks(_) ordered %v, want %v", ids, want)
+	}
+}
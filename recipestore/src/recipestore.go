@@ -14,7 +14,6 @@ import (
 	"fmt"
 
 	mgo "gopkg.in/mgo.v2"
-	"gopkg.in/mgo.v2/bson"
 
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
@@ -26,35 +25,34 @@ import (
 	"google.golang.org/grpc/codes"
 )
 
+// Server serves recipes out of its Repository. Meal plans and authoring are
+// backed by MongoSession directly, and are unavailable when it is nil.
 type Server struct {
 	Logger       *logger.CtsLogger
 	MongoSession *mgo.Session
+	Repository   RecipeRepository
+	Cache        *RecipeCache
 }
 
 func (r *Server) GetRecipe(ctx context.Context, recipeRequest *pb.RecipeRequest) (*pb.Recipe, error) {
-	recipe := new(pb.Recipe)
-	session := r.MongoSession.Copy()
-	defer session.Close()
-
-	if err := session.Ping(); err != nil {
-		r.Logger.Error(logrus.Fields{
+	if cached, ok := r.Cache.Get(recipeCacheKey(recipeRequest.Recipeid)); ok {
+		recipe := cached.(*pb.Recipe)
+		r.Logger.Info(logrus.Fields{
 			"phase": "process",
-			"event": "ping",
-			"tag":   "mongodb",
+			"event": "fetch",
+			"tag":   "cache",
 			"rpc":   "GetRecipe"},
-			fmt.Sprintf("Cannot ping MongoDB server while fetching Recipe %s Error: %v", recipeRequest.Recipeid, err))
-		return nil, grpc.Errorf(codes.Unknown, "Cannot ping MongDB server when fetching Recipe %s. Error: %v", recipeRequest.Recipeid, err)
+			fmt.Sprintf("Returning cached recipe %s with ID: %s", recipe.Name, recipe.Id))
+		return recipe, nil
 	}
 
-	c := session.DB("recipes").C("recipes")
-
-	err := c.Find(bson.M{"id": recipeRequest.Recipeid}).One(&recipe)
+	recipe, err := r.Repository.GetRecipe(recipeRequest.Recipeid)
 	if err != nil {
-		if err == mgo.ErrNotFound {
+		if err == ErrRecipeNotFound {
 			r.Logger.Error(logrus.Fields{
 				"phase": "process",
 				"event": "fetch",
-				"tag":   "mongodb",
+				"tag":   "repository",
 				"rpc":   "GetRecipe"},
 				fmt.Sprintf("Recipe %s not found", recipeRequest.Recipeid))
 			return nil, grpc.Errorf(codes.NotFound, "Recipe not found.")
@@ -63,17 +61,18 @@ func (r *Server) GetRecipe(ctx context.Context, recipeRequest *pb.RecipeRequest)
 		r.Logger.Error(logrus.Fields{
 			"phase": "process",
 			"event": "fetch",
-			"tag":   "mongodb",
+			"tag":   "repository",
 			"rpc":   "GetRecipe"},
 			errMsg)
 		return nil, grpc.Errorf(codes.Unknown, errMsg)
 	}
+	r.Cache.Set(recipeCacheKey(recipeRequest.Recipeid), recipe)
 
 	infoMsg := fmt.Sprintf("Returning recipe %s with ID: %s", recipe.Name, recipe.Id)
 	r.Logger.Info(logrus.Fields{
 		"phase": "process",
 		"event": "fetch",
-		"tag":   "mongodb",
+		"tag":   "repository",
 		"rpc":   "GetRecipe"},
 		infoMsg)
 
@@ -104,101 +103,59 @@ func (r *Server) GetRecipe(ctx context.Context, recipeRequest *pb.RecipeRequest)
 // }
 
 func (r *Server) GetRecipePacks(recipePackRequest *pb.RecipePacksRequest, stream pb.RecipeService_GetRecipePacksServer) error {
-	recipePacks := new([]pb.RecipePack)
-	session := r.MongoSession.Copy()
-	defer session.Close()
+	var recipePacks []pb.RecipePack
+	cacheKey := packsCacheKey(recipePackRequest)
+	if cached, ok := r.Cache.Get(cacheKey); ok {
+		recipePacks = cached.([]pb.RecipePack)
+	} else {
+		var err error
+		if recipePacks, err = r.findRecipePacks(recipePackRequest); err != nil {
+			return err
+		}
+		r.Cache.Set(cacheKey, recipePacks)
+	}
 
-	if err := session.Ping(); err != nil {
-		r.Logger.Error(logrus.Fields{
-			"phase": "process",
-			"event": "ping",
-			"tag":   "mongodb",
-			"rpc":   "GetRecipePacks"},
-			fmt.Sprintf("Cannot ping MongoDB server while fetching Recipepack %v Error: %v", recipePackRequest, err))
-		return grpc.Errorf(codes.Unknown, "Cannot ping MongDB server when fetching Recipepacks %v. Error: %v", recipePackRequest, err)
+	if recipePackRequest.Rank {
+		// Rank a copy, the cached packs are shared between requests
+		recipePacks = append([]pb.RecipePack(nil), recipePacks...)
+		RankRecipePacks(recipePacks, recipePackRequest.Weightgoal, recipePackRequest.Activitylevel)
 	}
 
-	c := session.DB("recipes").C("recipepacks")
-	// TODO(ppietkiewicz): add dietary restrictions to responses!
-	var err error
-	switch {
-	case recipePackRequest.Dietaryprofile.Omnivore && recipePackRequest.Dietaryprofile.Raw:
-		err = c.Find(bson.M{"$and": []bson.M{
-			bson.M{"dietaryprofile.omnivore": recipePackRequest.Dietaryprofile.Omnivore},
-			bson.M{"dietaryprofile.raw": recipePackRequest.Dietaryprofile.Raw},
-			bson.M{"mealplan": recipePackRequest.Mealplan}}}).All(recipePacks)
-	case recipePackRequest.Dietaryprofile.Omnivore == true:
-		err = c.Find(bson.M{"mealplan": recipePackRequest.Mealplan}).All(recipePacks)
-	case recipePackRequest.Dietaryprofile.Vegetarian && recipePackRequest.Dietaryprofile.Raw:
-		err = c.Find(bson.M{"$and": []bson.M{
-			bson.M{"dietaryprofile.vegetarian": recipePackRequest.Dietaryprofile.Vegetarian},
-			bson.M{"dietaryprofile.raw": recipePackRequest.Dietaryprofile.Raw},
-			bson.M{"mealplan": recipePackRequest.Mealplan}}}).All(recipePacks)
-	case recipePackRequest.Dietaryprofile.Vegetarian:
-		err = c.Find(bson.M{"$and": []bson.M{
-			bson.M{"dietaryprofile.vegetarian": recipePackRequest.Dietaryprofile.Vegetarian},
-			bson.M{"mealplan": recipePackRequest.Mealplan}}}).All(recipePacks)
-	case recipePackRequest.Dietaryprofile.Vegan && recipePackRequest.Dietaryprofile.Raw:
-		err = c.Find(bson.M{"$and": []bson.M{
-			bson.M{"dietaryprofile.vegan": recipePackRequest.Dietaryprofile.Vegan},
-			bson.M{"dietaryprofile.raw": recipePackRequest.Dietaryprofile.Raw},
-			bson.M{"mealplan": recipePackRequest.Mealplan}}}).All(recipePacks)
-	case recipePackRequest.Dietaryprofile.Vegan:
-		err = c.Find(bson.M{"$and": []bson.M{
-			bson.M{"dietaryprofile.vegan": recipePackRequest.Dietaryprofile.Vegan},
-			bson.M{"mealplan": recipePackRequest.Mealplan}}}).All(recipePacks)
-	default:
-		r.Logger.Error(logrus.Fields{
+	for _, recipePack := range recipePacks {
+		if err := stream.Send(&recipePack); err != nil {
+			return err
+		}
+		infoMsg := fmt.Sprintf("Returning recipepack %s with ID: %s", recipePack.Name, recipePack.Id)
+		r.Logger.Info(logrus.Fields{
 			"phase": "process",
 			"event": "fetch",
-			"tag":   "mongodb",
+			"tag":   "repository",
 			"rpc":   "GetRecipePacks"},
-			"Dietary Profile not provided")
-		return grpc.Errorf(codes.InvalidArgument, "Dietary Profile not provided.")
+			infoMsg)
 	}
+	return nil
+}
 
-	// err := c.Find(bson.M{"$and": []bson.M{
-	// 	bson.M{"dietaryprofile.omnivore": recipePackRequest.Dietaryprofile.Omnivore},
-	// 	bson.M{"dietaryprofile.vegetarian": recipePackRequest.Dietaryprofile.Vegetarian},
-	// 	bson.M{"dietaryprofile.vegan": recipePackRequest.Dietaryprofile.Vegan},
-	// 	bson.M{"dietaryprofile.raw": recipePackRequest.Dietaryprofile.Raw},
-	// 	bson.M{"dietaryrestriction.glutenfree": recipePackRequest.Dietaryrestriction.Glutenfree},
-	// 	bson.M{"dietaryrestriction.nutfree": recipePackRequest.Dietaryrestriction.Nutfree},
-	// 	bson.M{"dietaryrestriction.dairyfree": recipePackRequest.Dietaryrestriction.Dairyfree},
-	// 	bson.M{"dietaryrestriction.soyfree": recipePackRequest.Dietaryrestriction.Soyfree},
-	// 	bson.M{"dietaryrestriction.lowsodium": recipePackRequest.Dietaryrestriction.Lowsodium},
-	// 	bson.M{"mealplan": recipePackRequest.Mealplan}}}).All(recipePacks)
+func (r *Server) findRecipePacks(recipePackRequest *pb.RecipePacksRequest) ([]pb.RecipePack, error) {
+	recipePacks, err := r.Repository.FindRecipePacks(recipePackRequest)
 	if err != nil {
-		if err == mgo.ErrNotFound {
+		if err == ErrNoDietaryProfile {
 			r.Logger.Error(logrus.Fields{
 				"phase": "process",
-				"event": "fetch",
-				"tag":   "mongodb",
+				"event": "parseparameters",
+				"tag":   "invalidparameters",
 				"rpc":   "GetRecipePacks"},
-				fmt.Sprintf("Recipe Pack not found: %v", err))
-			return grpc.Errorf(codes.NotFound, "Recipepacks not found.")
+				err.Error())
+			return nil, grpc.Errorf(codes.InvalidArgument, "%v.", err)
 		}
 		errMsg := fmt.Sprintf("Unknown recipepack lookup error: %v", err)
 		r.Logger.Error(logrus.Fields{
 			"phase": "process",
 			"event": "fetch",
-			"tag":   "mongodb",
+			"tag":   "repository",
 			"rpc":   "GetRecipePacks"},
 			errMsg)
-		return grpc.Errorf(codes.Unknown, errMsg)
-	}
-
-	for _, recipePack := range *recipePacks {
-		if err := stream.Send(&recipePack); err != nil {
-			return err
-		}
-		infoMsg := fmt.Sprintf("Returning recipepack %s with ID: %s", recipePack.Name, recipePack.Id)
-		r.Logger.Info(logrus.Fields{
-			"phase": "process",
-			"event": "fetch",
-			"tag":   "mongodb",
-			"rpc":   "GetRecipePacks"},
-			infoMsg)
+		return nil, grpc.Errorf(codes.Unknown, errMsg)
 	}
-	return err
+	return recipePacks, nil
 }
@@ -0,0 +1,155 @@
+/*
+// ----------------------------------------------------------------------------
+// repository.go
+// Countertop Recipe Microservice Recipe Repository
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore
+
+import (
+	"errors"
+	"fmt"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+var ErrRecipeNotFound = errors.New("recipe not found")
+
+// RecipeRepository is the read side of the recipe store. Drafts are never
+// returned. Recipe and recipe pack queries are described by the dietary
+// profile and restrictions, and fail with ErrNoDietaryProfile when no
+// profile is selected.
+type RecipeRepository interface {
+	RecipeSearcher
+	// GetRecipe returns the recipe with the ID, or ErrRecipeNotFound.
+	GetRecipe(recipeID string) (*pb.Recipe, error)
+	// GetRecipes returns the recipes with the IDs in no particular order,
+	// leaving out IDs that are not found.
+	GetRecipes(recipeIDs []string) ([]*pb.Recipe, error)
+	// FindRecipes returns every recipe suitable for the profile and
+	// restrictions.
+	FindRecipes(profile *pb.DietaryProfile, restriction *pb.DietaryRestriction) ([]*pb.Recipe, error)
+	// FindRecipePacks returns every recipe pack matching the request.
+	FindRecipePacks(recipePackRequest *pb.RecipePacksRequest) ([]pb.RecipePack, error)
+}
+
+// recipesQuery builds the query FindRecipes runs against the recipes
+// collection.
+func recipesQuery(profile *pb.DietaryProfile, restriction *pb.DietaryRestriction) (bson.M, error) {
+	return NewPackQueryBuilder().DietaryProfile(profile).DietaryRestriction(restriction).Published().Build()
+}
+
+//
+// MongoDB backed repository
+//
+
+type MongoRecipeRepository struct {
+	Session *mgo.Session
+}
+
+// pingedSession returns a copy of the session once the server has answered
+// a ping. Callers must close the returned session.
+func (m *MongoRecipeRepository) pingedSession() (*mgo.Session, error) {
+	session := m.Session.Copy()
+	if err := session.Ping(); err != nil {
+		session.Close()
+		return nil, fmt.Errorf("cannot ping MongoDB server: %v", err)
+	}
+	return session, nil
+}
+
+func (m *MongoRecipeRepository) GetRecipe(recipeID string) (*pb.Recipe, error) {
+	session, err := m.pingedSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	recipe := new(pb.Recipe)
+	err = session.DB("recipes").C("recipes").Find(bson.M{"$and": []bson.M{
+		bson.M{"id": recipeID},
+		publishedClause}}).One(recipe)
+	if err == mgo.ErrNotFound {
+		return nil, ErrRecipeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return recipe, nil
+}
+
+func (m *MongoRecipeRepository) GetRecipes(recipeIDs []string) ([]*pb.Recipe, error) {
+	session, err := m.pingedSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	var recipes []*pb.Recipe
+	err = session.DB("recipes").C("recipes").Find(bson.M{"$and": []bson.M{
+		bson.M{"id": bson.M{"$in": recipeIDs}},
+		publishedClause}}).All(&recipes)
+	if err != nil && err != mgo.ErrNotFound {
+		return nil, err
+	}
+	return recipes, nil
+}
+
+func (m *MongoRecipeRepository) FindRecipes(profile *pb.DietaryProfile, restriction *pb.DietaryRestriction) ([]*pb.Recipe, error) {
+	query, err := recipesQuery(profile, restriction)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := m.pingedSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	var recipes []*pb.Recipe
+	if err := session.DB("recipes").C("recipes").Find(query).All(&recipes); err != nil && err != mgo.ErrNotFound {
+		return nil, err
+	}
+	return recipes, nil
+}
+
+func (m *MongoRecipeRepository) FindRecipePacks(recipePackRequest *pb.RecipePacksRequest) ([]pb.RecipePack, error) {
+	query, err := RecipePackQuery(recipePackRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := m.pingedSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	var recipePacks []pb.RecipePack
+	if err := session.DB("recipes").C("recipepacks").Find(query).All(&recipePacks); err != nil && err != mgo.ErrNotFound {
+		return nil, err
+	}
+	return recipePacks, nil
+}
+
+func (m *MongoRecipeRepository) Search(searchRequest *pb.RecipeSearchRequest, after string, limit int) ([]*pb.Recipe, error) {
+	session, err := m.pingedSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	var recipes []*pb.Recipe
+	err = session.DB("recipes").C("recipes").Find(searchQuery(searchRequest, after)).Sort("id").Limit(limit).All(&recipes)
+	if err != nil && err != mgo.ErrNotFound {
+		return nil, err
+	}
+	return recipes, nil
+}
@@ -0,0 +1,100 @@
+/*
+// ----------------------------------------------------------------------------
+// scale.go
+// Countertop Recipe Microservice Recipe Scaling
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/Sirupsen/logrus"
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+var (
+	ErrInvalidServings = errors.New("requested servings must be positive")
+	ErrUnknownServings = errors.New("recipe does not specify its servings")
+)
+
+// Scale returns a copy of the recipe sized for the requested servings, with
+// ingredient quantities converted to the unit system if one is given.
+// Ingredients in units that cannot be converted, such as pieces, are only
+// scaled. The recipe's nutrition is per serving, so it is carried over as is.
+func Scale(recipe *pb.Recipe, servings int32, unitSystem pb.UnitSystem) (*pb.Recipe, error) {
+	if servings <= 0 {
+		return nil, ErrInvalidServings
+	}
+	if recipe.Servings <= 0 {
+		return nil, ErrUnknownServings
+	}
+
+	factor := float64(servings) / float64(recipe.Servings)
+	scaled := *recipe
+	scaled.Servings = servings
+	if recipe.Nutrition != nil {
+		nutrition := *recipe.Nutrition
+		scaled.Nutrition = &nutrition
+	}
+
+	scaled.Ingredients = make([]*pb.Ingredient, 0, len(recipe.Ingredients))
+	for _, ingredient := range recipe.Ingredients {
+		if ingredient == nil {
+			continue
+		}
+		scaledIngredient := *ingredient
+		quantity := float64(ingredient.Quantity) * factor
+		if conversion, ok := lookupUnit(ingredient.Unit); ok && unitSystem != pb.UnitSystem_ORIGINAL {
+			quantity, scaledIngredient.Unit = formatQuantity(quantity*conversion.toBase, conversion.dimension, unitSystem == pb.UnitSystem_IMPERIAL)
+		}
+		scaledIngredient.Quantity = roundQuantity(quantity)
+		scaled.Ingredients = append(scaled.Ingredients, &scaledIngredient)
+	}
+	return &scaled, nil
+}
+
+func (r *Server) ScaleRecipe(ctx context.Context, scaleRequest *pb.ScaleRecipeRequest) (*pb.Recipe, error) {
+	if scaleRequest.Recipeid == "" || scaleRequest.Servings <= 0 {
+		errMsg := fmt.Sprintf("Recipe ID and positive servings are required, got: %v", scaleRequest)
+		r.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "ScaleRecipe"},
+			errMsg)
+		return nil, grpc.Errorf(codes.InvalidArgument, errMsg)
+	}
+
+	recipes, err := r.fetchRecipes([]string{scaleRequest.Recipeid}, "ScaleRecipe")
+	if err != nil {
+		return nil, err
+	}
+
+	scaled, scaleErr := Scale(recipes[0], scaleRequest.Servings, scaleRequest.Unitsystem)
+	if scaleErr != nil {
+		r.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "scale",
+			"tag":   "scaler",
+			"rpc":   "ScaleRecipe"},
+			fmt.Sprintf("Cannot scale recipe %s: %v", scaleRequest.Recipeid, scaleErr))
+		return nil, grpc.Errorf(codes.FailedPrecondition, "Cannot scale recipe: %v.", scaleErr)
+	}
+
+	r.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "scale",
+		"tag":   "scaler",
+		"rpc":   "ScaleRecipe"},
+		fmt.Sprintf("Scaled recipe %s from %d to %d servings", scaleRequest.Recipeid, recipes[0].Servings, scaled.Servings))
+	return scaled, nil
+}
@@ -0,0 +1,91 @@
+/*
+// ----------------------------------------------------------------------------
+// scale_test.go
+// Countertop Recipe Microservice Recipe Scaling Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore_test
+
+import (
+	"testing"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+	recipestore "github.com/theorangechefco/cts/recipestore"
+)
+
+func pancakes() *pb.Recipe {
+	return &pb.Recipe{
+		Id:       "pancakes",
+		Servings: 4,
+		Ingredients: []*pb.Ingredient{
+			{Name: "Flour", Quantity: 250, Unit: "g"},
+			{Name: "Milk", Quantity: 2, Unit: "cups"},
+			{Name: "Butter", Quantity: 2, Unit: "oz"},
+			{Name: "Eggs", Quantity: 2},
+		},
+		Nutrition: &pb.Nutrition{Calories: 320, Protein: 9},
+	}
+}
+
+func TestScale(t *testing.T) {
+	tests := []struct {
+		servings   int32
+		unitSystem pb.UnitSystem
+		want       []pb.Ingredient
+	}{
+		{2, pb.UnitSystem_ORIGINAL, []pb.Ingredient{
+			{Name: "Flour", Quantity: 125, Unit: "g"},
+			{Name: "Milk", Quantity: 1, Unit: "cups"},
+			{Name: "Butter", Quantity: 1, Unit: "oz"},
+			{Name: "Eggs", Quantity: 1},
+		}},
+		{8, pb.UnitSystem_METRIC, []pb.Ingredient{
+			{Name: "Flour", Quantity: 500, Unit: "g"},
+			{Name: "Milk", Quantity: 946.35, Unit: "ml"},
+			{Name: "Butter", Quantity: 113.4, Unit: "g"},
+			{Name: "Eggs", Quantity: 4},
+		}},
+		{16, pb.UnitSystem_IMPERIAL, []pb.Ingredient{
+			{Name: "Flour", Quantity: 2.2, Unit: "lb"},
+			{Name: "Milk", Quantity: 8, Unit: "cup"},
+			{Name: "Butter", Quantity: 8, Unit: "oz"},
+			{Name: "Eggs", Quantity: 8},
+		}},
+	}
+
+	for _, test := range tests {
+		recipe := pancakes()
+		scaled, err := recipestore.Scale(recipe, test.servings, test.unitSystem)
+		if err != nil {
+			t.Fatalf("Scale(_, %d, %v) = _, %v", test.servings, test.unitSystem, err)
+		}
+		if scaled.Servings != test.servings {
+			t.Errorf("Scale(_, %d, %v) has %d servings", test.servings, test.unitSystem, scaled.Servings)
+		}
+		for i, want := range test.want {
+			got := scaled.Ingredients[i]
+			if got.Name != want.Name || got.Quantity != want.Quantity || got.Unit != want.Unit {
+				t.Errorf("Scale(_, %d, %v) ingredient %d = %v %s %s, want %v %s %s",
+					test.servings, test.unitSystem, i, got.Quantity, got.Unit, got.Name, want.Quantity, want.Unit, want.Name)
+			}
+		}
+		if scaled.Nutrition.Calories != 320 || scaled.Nutrition.Protein != 9 {
+			t.Errorf("Scale(_, %d, %v) changed per serving nutrition to %v", test.servings, test.unitSystem, scaled.Nutrition)
+		}
+		if recipe.Servings != 4 || recipe.Ingredients[0].Quantity != 250 {
+			t.Errorf("Scale(_, %d, %v) modified the original recipe", test.servings, test.unitSystem)
+		}
+	}
+}
+
+func TestScaleErrors(t *testing.T) {
+	if _, err := recipestore.Scale(pancakes(), 0, pb.UnitSystem_ORIGINAL); err != recipestore.ErrInvalidServings {
+		t.Errorf("Scale(_, 0, _) = _, %v, want ErrInvalidServings", err)
+	}
+	if _, err := recipestore.Scale(&pb.Recipe{Id: "unknown"}, 2, pb.UnitSystem_ORIGINAL); err != recipestore.ErrUnknownServings {
+		t.Errorf("Scale(unknown servings, 2, _) = _, %v, want ErrUnknownServings", err)
+	}
+}
@@ -0,0 +1,233 @@
+/*
+// ----------------------------------------------------------------------------
+// search.go
+// Countertop Recipe Microservice Search Functions
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/Sirupsen/logrus"
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+const (
+	DefaultSearchPageSize = 20
+	MaxSearchPageSize     = 100
+)
+
+// RecipeSearcher returns up to limit recipes matching the search request,
+// ordered by recipe ID and starting after the recipe ID passed in after.
+type RecipeSearcher interface {
+	Search(searchRequest *pb.RecipeSearchRequest, after string, limit int) ([]*pb.Recipe, error)
+}
+
+func (r *Server) SearchRecipes(searchRequest *pb.RecipeSearchRequest, stream pb.RecipeService_SearchRecipesServer) error {
+	if err := validateSearchRequest(searchRequest); err != nil {
+		r.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "SearchRecipes"},
+			fmt.Sprintf("Invalid search request %v. Error: %v", searchRequest, err))
+		return grpc.Errorf(codes.InvalidArgument, "Invalid search request: %v", err)
+	}
+
+	after, err := decodeSearchCursor(searchRequest.Cursor)
+	if err != nil {
+		r.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "SearchRecipes"},
+			fmt.Sprintf("Invalid search cursor %s. Error: %v", searchRequest.Cursor, err))
+		return grpc.Errorf(codes.InvalidArgument, "Invalid search cursor.")
+	}
+
+	recipes, err := r.Repository.Search(searchRequest, after, searchPageSize(searchRequest.Pagesize))
+	if err != nil {
+		errMsg := fmt.Sprintf("Unknown recipe search error: %v", err)
+		r.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "search",
+			"rpc":   "SearchRecipes"},
+			errMsg)
+		return grpc.Errorf(codes.Unknown, errMsg)
+	}
+
+	for _, recipe := range recipes {
+		result := pb.RecipeSearchResult{Recipe: recipe, Cursor: encodeSearchCursor(recipe.Id)}
+		if err := stream.Send(&result); err != nil {
+			return err
+		}
+	}
+
+	r.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "fetch",
+		"tag":   "search",
+		"rpc":   "SearchRecipes"},
+		fmt.Sprintf("Returned %d recipes for search request %v", len(recipes), searchRequest))
+	return nil
+}
+
+func validateSearchRequest(searchRequest *pb.RecipeSearchRequest) error {
+	ranges := []struct {
+		name     string
+		min, max float32
+	}{
+		{"calories", searchRequest.Mincalories, searchRequest.Maxcalories},
+		{"protein", searchRequest.Minprotein, searchRequest.Maxprotein},
+		{"carbohydrates", searchRequest.Mincarbohydrates, searchRequest.Maxcarbohydrates},
+		{"fat", searchRequest.Minfat, searchRequest.Maxfat},
+	}
+	for _, nutrient := range ranges {
+		if nutrient.min < 0 || nutrient.max < 0 {
+			return fmt.Errorf("%s range cannot be negative", nutrient.name)
+		}
+		if nutrient.max > 0 && nutrient.min > nutrient.max {
+			return fmt.Errorf("minimum %s greater than maximum", nutrient.name)
+		}
+	}
+	if searchRequest.Pagesize < 0 {
+		return fmt.Errorf("page size cannot be negative")
+	}
+	return nil
+}
+
+func searchPageSize(requested int32) int {
+	switch {
+	case requested <= 0:
+		return DefaultSearchPageSize
+	case requested > MaxSearchPageSize:
+		return MaxSearchPageSize
+	}
+	return int(requested)
+}
+
+// Cursors are the URL-safe base64 encoded ID of the last recipe returned.
+func encodeSearchCursor(recipeID string) string {
+	return base64.URLEncoding.EncodeToString([]byte(recipeID))
+}
+
+func decodeSearchCursor(cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+	recipeID, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", err
+	}
+	return string(recipeID), nil
+}
+
+func searchQuery(searchRequest *pb.RecipeSearchRequest, after string) bson.M {
+	clauses := []bson.M{publishedClause}
+
+	if query := strings.TrimSpace(searchRequest.Query); query != "" {
+		pattern := bson.RegEx{Pattern: regexp.QuoteMeta(query), Options: "i"}
+		clauses = append(clauses, bson.M{"$or": []bson.M{
+			bson.M{"name": pattern},
+			bson.M{"description": pattern}}})
+	}
+	for _, ingredient := range searchRequest.Includeingredients {
+		pattern := bson.RegEx{Pattern: regexp.QuoteMeta(ingredient), Options: "i"}
+		clauses = append(clauses, bson.M{"ingredients.name": pattern})
+	}
+	for _, ingredient := range searchRequest.Excludeingredients {
+		pattern := bson.RegEx{Pattern: regexp.QuoteMeta(ingredient), Options: "i"}
+		clauses = append(clauses, bson.M{"ingredients.name": bson.M{"$not": pattern}})
+	}
+
+	clauses = appendRangeClause(clauses, "nutrition.calories", searchRequest.Mincalories, searchRequest.Maxcalories)
+	clauses = appendRangeClause(clauses, "nutrition.protein", searchRequest.Minprotein, searchRequest.Maxprotein)
+	clauses = appendRangeClause(clauses, "nutrition.carbohydrates", searchRequest.Mincarbohydrates, searchRequest.Maxcarbohydrates)
+	clauses = appendRangeClause(clauses, "nutrition.fat", searchRequest.Minfat, searchRequest.Maxfat)
+
+	if after != "" {
+		clauses = append(clauses, bson.M{"id": bson.M{"$gt": after}})
+	}
+
+	return bson.M{"$and": clauses}
+}
+
+// A zero bound is treated as unset.
+func appendRangeClause(clauses []bson.M, field string, min float32, max float32) []bson.M {
+	bounds := bson.M{}
+	if min > 0 {
+		bounds["$gte"] = min
+	}
+	if max > 0 {
+		bounds["$lte"] = max
+	}
+	if len(bounds) == 0 {
+		return clauses
+	}
+	return append(clauses, bson.M{field: bounds})
+}
+
+// matchesSearch mirrors searchQuery for recipes held in memory.
+func matchesSearch(recipe *pb.Recipe, searchRequest *pb.RecipeSearchRequest) bool {
+	if recipe.Draft {
+		return false
+	}
+	if query := strings.ToLower(strings.TrimSpace(searchRequest.Query)); query != "" {
+		if !strings.Contains(strings.ToLower(recipe.Name), query) &&
+			!strings.Contains(strings.ToLower(recipe.Description), query) {
+			return false
+		}
+	}
+	for _, ingredient := range searchRequest.Includeingredients {
+		if !hasIngredient(recipe, ingredient) {
+			return false
+		}
+	}
+	for _, ingredient := range searchRequest.Excludeingredients {
+		if hasIngredient(recipe, ingredient) {
+			return false
+		}
+	}
+
+	nutrition := recipe.Nutrition
+	if nutrition == nil {
+		nutrition = &pb.Nutrition{}
+	}
+	return inRange(nutrition.Calories, searchRequest.Mincalories, searchRequest.Maxcalories) &&
+		inRange(nutrition.Protein, searchRequest.Minprotein, searchRequest.Maxprotein) &&
+		inRange(nutrition.Carbohydrates, searchRequest.Mincarbohydrates, searchRequest.Maxcarbohydrates) &&
+		inRange(nutrition.Fat, searchRequest.Minfat, searchRequest.Maxfat)
+}
+
+func hasIngredient(recipe *pb.Recipe, name string) bool {
+	name = strings.ToLower(name)
+	for _, ingredient := range recipe.Ingredients {
+		if strings.Contains(strings.ToLower(ingredient.Name), name) {
+			return true
+		}
+	}
+	return false
+}
+
+func inRange(value float32, min float32, max float32) bool {
+	if min > 0 && value < min {
+		return false
+	}
+	if max > 0 && value > max {
+		return false
+	}
+	return true
+}
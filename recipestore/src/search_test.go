@@ -0,0 +1,186 @@
+/*
+// ----------------------------------------------------------------------------
+// search_test.go
+// Countertop Recipe Microservice Search Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore_test
+
+import (
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+	pb "github.com/theorangechefco/cts/go-protos"
+	"github.com/theorangechefco/cts/go-shared-libs/cts/logger"
+	recipestore "github.com/theorangechefco/cts/recipestore"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type searchStream struct {
+	grpc.ServerStream
+	results []*pb.RecipeSearchResult
+}
+
+func (s *searchStream) Send(result *pb.RecipeSearchResult) error {
+	s.results = append(s.results, result)
+	return nil
+}
+
+func (s *searchStream) Context() context.Context {
+	return context.Background()
+}
+
+func testRecipes() []*pb.Recipe {
+	return []*pb.Recipe{
+		&pb.Recipe{
+			Id:          "1",
+			Name:        "Kale Caesar Salad",
+			Description: "Crunchy kale with a light caesar dressing",
+			Ingredients: []*pb.Ingredient{{Name: "Kale"}, {Name: "Parmesan"}, {Name: "Anchovy"}},
+			Nutrition:   &pb.Nutrition{Calories: 320, Protein: 12, Carbohydrates: 18, Fat: 22},
+		},
+		&pb.Recipe{
+			Id:          "2",
+			Name:        "Grilled Chicken Bowl",
+			Description: "Chicken breast over brown rice",
+			Ingredients: []*pb.Ingredient{{Name: "Chicken breast"}, {Name: "Brown rice"}, {Name: "Peanuts"}},
+			Nutrition:   &pb.Nutrition{Calories: 610, Protein: 45, Carbohydrates: 60, Fat: 15},
+		},
+		&pb.Recipe{
+			Id:          "3",
+			Name:        "Lentil Soup",
+			Description: "Hearty soup with a side salad",
+			Ingredients: []*pb.Ingredient{{Name: "Lentils"}, {Name: "Carrot"}, {Name: "Kale"}},
+			Nutrition:   &pb.Nutrition{Calories: 410, Protein: 24, Carbohydrates: 55, Fat: 6},
+		},
+		&pb.Recipe{
+			Id:          "4",
+			Name:        "Chocolate Mousse",
+			Description: "Rich dessert",
+			Ingredients: []*pb.Ingredient{{Name: "Dark chocolate"}, {Name: "Cream"}},
+			Nutrition:   &pb.Nutrition{Calories: 480, Protein: 6, Carbohydrates: 40, Fat: 34},
+		},
+	}
+}
+
+func newTestServer() *recipestore.Server {
+	return &recipestore.Server{
+		Logger:     logger.NewLogger("test", "", 8080, true, logrus.DebugLevel),
+		Repository: recipestore.NewMemoryRecipeStore(testRecipes()...),
+	}
+}
+
+func resultIDs(results []*pb.RecipeSearchResult) []string {
+	var ids []string
+	for _, result := range results {
+		ids = append(ids, result.Recipe.Id)
+	}
+	return ids
+}
+
+func equalIDs(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSearchRecipes(t *testing.T) {
+	tests := []struct {
+		name    string
+		request pb.RecipeSearchRequest
+		want    []string
+	}{
+		{"everything", pb.RecipeSearchRequest{}, []string{"1", "2", "3", "4"}},
+		{"name match", pb.RecipeSearchRequest{Query: "chicken"}, []string{"2"}},
+		{"description match", pb.RecipeSearchRequest{Query: "SALAD"}, []string{"1", "3"}},
+		{"include ingredient", pb.RecipeSearchRequest{Includeingredients: []string{"kale"}}, []string{"1", "3"}},
+		{"include all ingredients", pb.RecipeSearchRequest{Includeingredients: []string{"kale", "lentil"}}, []string{"3"}},
+		{"exclude ingredient", pb.RecipeSearchRequest{Excludeingredients: []string{"peanut", "cream"}}, []string{"1", "3"}},
+		{"calorie range", pb.RecipeSearchRequest{Mincalories: 400, Maxcalories: 500}, []string{"3", "4"}},
+		{"protein floor", pb.RecipeSearchRequest{Minprotein: 20}, []string{"2", "3"}},
+		{"fat ceiling", pb.RecipeSearchRequest{Maxfat: 20}, []string{"2", "3"}},
+		{"carbohydrate range", pb.RecipeSearchRequest{Mincarbohydrates: 20, Maxcarbohydrates: 50}, []string{"4"}},
+		{"combined", pb.RecipeSearchRequest{Query: "salad", Includeingredients: []string{"kale"}, Maxcalories: 350}, []string{"1"}},
+		{"no match", pb.RecipeSearchRequest{Query: "pizza"}, nil},
+	}
+
+	server := newTestServer()
+	for _, test := range tests {
+		stream := new(searchStream)
+		if err := server.SearchRecipes(&test.request, stream); err != nil {
+			t.Errorf("%s: SearchRecipes(_) = %v", test.name, err)
+			continue
+		}
+		if got := resultIDs(stream.results); !equalIDs(got, test.want) {
+			t.Errorf("%s: SearchRecipes(_) returned %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSearchRecipesPagination(t *testing.T) {
+	server := newTestServer()
+	request := pb.RecipeSearchRequest{Pagesize: 3}
+
+	var pages [][]string
+	for {
+		stream := new(searchStream)
+		if err := server.SearchRecipes(&request, stream); err != nil {
+			t.Fatalf("SearchRecipes(_) = %v", err)
+		}
+		if len(stream.results) == 0 {
+			break
+		}
+		pages = append(pages, resultIDs(stream.results))
+		request.Cursor = stream.results[len(stream.results)-1].Cursor
+	}
+
+	if len(pages) != 2 || !equalIDs(pages[0], []string{"1", "2", "3"}) || !equalIDs(pages[1], []string{"4"}) {
+		t.Errorf("Unexpected pages %v", pages)
+	}
+}
+
+func TestSearchRecipesInvalidRequest(t *testing.T) {
+	requests := []pb.RecipeSearchRequest{
+		{Mincalories: 500, Maxcalories: 400},
+		{Minfat: -1},
+		{Pagesize: -5},
+		{Cursor: "not base64!"},
+	}
+
+	server := newTestServer()
+	for _, request := range requests {
+		err := server.SearchRecipes(&request, new(searchStream))
+		if grpc.Code(err) != codes.InvalidArgument {
+			t.Errorf("SearchRecipes(%v) = %v, want InvalidArgument", request, err)
+		}
+	}
+}
+
+func TestSearchRecipesSkipsDrafts(t *testing.T) {
+	recipes := testRecipes()
+	recipes[0].Draft = true
+	server := &recipestore.Server{
+		Logger:     logger.NewLogger("test", "", 8080, true, logrus.DebugLevel),
+		Repository: recipestore.NewMemoryRecipeStore(recipes...),
+	}
+
+	stream := new(searchStream)
+	if err := server.SearchRecipes(&pb.RecipeSearchRequest{Includeingredients: []string{"kale"}}, stream); err != nil {
+		t.Fatalf("SearchRecipes(_) = %v", err)
+	}
+	if got := resultIDs(stream.results); !equalIDs(got, []string{"3"}) {
+		t.Errorf("SearchRecipes(_) returned %v, want only published recipe 3", got)
+	}
+}
@@ -0,0 +1,233 @@
+/*
+// ----------------------------------------------------------------------------
+// shoppinglist.go
+// Countertop Recipe Microservice Shopping List Aggregation
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/Sirupsen/logrus"
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+// Items whose ingredients carry no category are listed under this aisle,
+// after every other aisle.
+const OtherAisle = "Other"
+
+// shoppingEntry accumulates one ingredient across recipes, in the base unit
+// of its dimension. Ingredients with units that cannot be converted are only
+// merged with the same unit.
+type shoppingEntry struct {
+	name     string
+	category string
+	unit     string
+	quantity float64
+	// Whether every quantity merged so far was in US customary units.
+	imperial bool
+}
+
+// shoppingKey identifies the entry an ingredient is merged into.
+func shoppingKey(ingredient *pb.Ingredient) string {
+	name := strings.ToLower(strings.TrimSpace(ingredient.Name))
+	unit := normalizeUnit(ingredient.Unit)
+	if conversion, ok := lookupUnit(unit); ok {
+		switch conversion.dimension {
+		case massDimension:
+			return name + "|mass"
+		case volumeDimension:
+			return name + "|volume"
+		}
+	}
+	return name + "|" + unit
+}
+
+func (e *shoppingEntry) add(ingredient *pb.Ingredient) {
+	conversion, ok := lookupUnit(ingredient.Unit)
+	if !ok {
+		e.quantity += float64(ingredient.Quantity)
+		return
+	}
+	e.quantity += float64(ingredient.Quantity) * conversion.toBase
+	e.imperial = e.imperial && conversion.imperial
+}
+
+// displayQuantity converts the accumulated base quantity to the unit it
+// reads best in, keeping to US customary units when no metric quantity was
+// merged in.
+func (e *shoppingEntry) displayQuantity() (float64, string) {
+	conversion, ok := lookupUnit(e.unit)
+	if !ok {
+		return e.quantity, e.unit
+	}
+	return formatQuantity(e.quantity, conversion.dimension, e.imperial)
+}
+
+type byItemName []*pb.ShoppingItem
+
+func (i byItemName) Len() int      { return len(i) }
+func (i byItemName) Swap(j, k int) { i[j], i[k] = i[k], i[j] }
+func (i byItemName) Less(j, k int) bool {
+	return strings.ToLower(i[j].Name) < strings.ToLower(i[k].Name)
+}
+
+type byAisle []*pb.ShoppingAisle
+
+func (a byAisle) Len() int      { return len(a) }
+func (a byAisle) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byAisle) Less(i, j int) bool {
+	if (a[i].Category == OtherAisle) != (a[j].Category == OtherAisle) {
+		return a[j].Category == OtherAisle
+	}
+	return a[i].Category < a[j].Category
+}
+
+// BuildShoppingList merges the ingredients of every recipe into a single
+// list grouped by aisle. A recipe listed more than once contributes its
+// ingredients each time.
+func BuildShoppingList(recipes []*pb.Recipe) *pb.ShoppingList {
+	entries := make(map[string]*shoppingEntry)
+	var order []string
+	for _, recipe := range recipes {
+		for _, ingredient := range recipe.Ingredients {
+			if ingredient == nil || strings.TrimSpace(ingredient.Name) == "" {
+				continue
+			}
+			key := shoppingKey(ingredient)
+			entry, ok := entries[key]
+			if !ok {
+				category := strings.TrimSpace(ingredient.Category)
+				if category == "" {
+					category = OtherAisle
+				}
+				entry = &shoppingEntry{
+					name:     strings.TrimSpace(ingredient.Name),
+					category: category,
+					unit:     normalizeUnit(ingredient.Unit),
+					imperial: true,
+				}
+				entries[key] = entry
+				order = append(order, key)
+			}
+			entry.add(ingredient)
+		}
+	}
+
+	aisles := make(map[string]*pb.ShoppingAisle)
+	shoppingList := new(pb.ShoppingList)
+	for _, key := range order {
+		entry := entries[key]
+		aisle, ok := aisles[entry.category]
+		if !ok {
+			aisle = &pb.ShoppingAisle{Category: entry.category}
+			aisles[entry.category] = aisle
+			shoppingList.Aisles = append(shoppingList.Aisles, aisle)
+		}
+		quantity, unit := entry.displayQuantity()
+		aisle.Items = append(aisle.Items, &pb.ShoppingItem{
+			Name:     entry.name,
+			Quantity: roundQuantity(quantity),
+			Unit:     unit,
+		})
+	}
+
+	sort.Stable(byAisle(shoppingList.Aisles))
+	for _, aisle := range shoppingList.Aisles {
+		sort.Stable(byItemName(aisle.Items))
+	}
+	return shoppingList
+}
+
+// Builds a shopping list from the requested recipes and, if asked for, every
+// meal in the user's stored meal plan
+func (r *Server) GetShoppingList(ctx context.Context, shoppingListRequest *pb.ShoppingListRequest) (*pb.ShoppingList, error) {
+	if len(shoppingListRequest.Recipeids) == 0 && !shoppingListRequest.Frommealplan {
+		errMsg := "Recipe IDs or meal plan required for shopping list."
+		r.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "parseparameters",
+			"tag":   "invalidparameters",
+			"rpc":   "GetShoppingList"},
+			errMsg)
+		return nil, grpc.Errorf(codes.InvalidArgument, errMsg)
+	}
+
+	recipes, err := r.fetchRecipes(shoppingListRequest.Recipeids, "GetShoppingList")
+	if err != nil {
+		return nil, err
+	}
+
+	if shoppingListRequest.Frommealplan {
+		plan, err := r.loadMealPlan(shoppingListRequest.Useruuid, "GetShoppingList")
+		if err != nil {
+			return nil, err
+		}
+		for _, dayPlan := range plan.Days {
+			for _, meal := range dayPlan.Meals {
+				if meal.Recipe != nil {
+					recipes = append(recipes, meal.Recipe)
+				}
+			}
+		}
+	}
+
+	shoppingList := BuildShoppingList(recipes)
+	r.Logger.Info(logrus.Fields{
+		"phase": "process",
+		"event": "aggregate",
+		"tag":   "shoppinglist",
+		"rpc":   "GetShoppingList"},
+		fmt.Sprintf("Built shopping list with %d aisles from %d recipes", len(shoppingList.Aisles), len(recipes)))
+	return shoppingList, nil
+}
+
+// fetchRecipes looks up recipes by ID, returning them in the order and with
+// the repetitions requested.
+func (r *Server) fetchRecipes(recipeIDs []string, rpc string) ([]*pb.Recipe, error) {
+	if len(recipeIDs) == 0 {
+		return nil, nil
+	}
+
+	found, err := r.Repository.GetRecipes(recipeIDs)
+	if err != nil {
+		errMsg := fmt.Sprintf("Unknown recipe lookup error: %v", err)
+		r.Logger.Error(logrus.Fields{
+			"phase": "process",
+			"event": "fetch",
+			"tag":   "repository",
+			"rpc":   rpc},
+			errMsg)
+		return nil, grpc.Errorf(codes.Unknown, errMsg)
+	}
+
+	byID := make(map[string]*pb.Recipe)
+	for _, recipe := range found {
+		byID[recipe.Id] = recipe
+	}
+	recipes := make([]*pb.Recipe, 0, len(recipeIDs))
+	for _, recipeID := range recipeIDs {
+		recipe, ok := byID[recipeID]
+		if !ok {
+			r.Logger.Error(logrus.Fields{
+				"phase": "process",
+				"event": "fetch",
+				"tag":   "repository",
+				"rpc":   rpc},
+				fmt.Sprintf("Recipe %s not found", recipeID))
+			return nil, grpc.Errorf(codes.NotFound, "Recipe %s not found.", recipeID)
+		}
+		recipes = append(recipes, recipe)
+	}
+	return recipes, nil
+}
@@ -0,0 +1,106 @@
+/*
+// ----------------------------------------------------------------------------
+// shoppinglist_test.go
+// Countertop Recipe Microservice Shopping List Aggregation Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore_test
+
+import (
+	"testing"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+	recipestore "github.com/theorangechefco/cts/recipestore"
+)
+
+func findItem(t *testing.T, shoppingList *pb.ShoppingList, category string, name string) *pb.ShoppingItem {
+	for _, aisle := range shoppingList.Aisles {
+		if aisle.Category != category {
+			continue
+		}
+		for _, item := range aisle.Items {
+			if item.Name == name {
+				return item
+			}
+		}
+	}
+	t.Fatalf("Item %s not found in aisle %s: %v", name, category, shoppingList)
+	return nil
+}
+
+func TestBuildShoppingList(t *testing.T) {
+	recipes := []*pb.Recipe{
+		{Id: "1", Ingredients: []*pb.Ingredient{
+			{Name: "Flour", Quantity: 750, Unit: "g", Category: "Baking"},
+			{Name: "Milk", Quantity: 500, Unit: "ml", Category: "Dairy"},
+			{Name: "Olive oil", Quantity: 1, Unit: "tbsp", Category: "Pantry"},
+			{Name: "Eggs", Quantity: 2, Category: "Dairy"},
+		}},
+		{Id: "2", Ingredients: []*pb.Ingredient{
+			{Name: "flour", Quantity: 0.5, Unit: "kg", Category: "Baking"},
+			{Name: "Milk", Quantity: 0.75, Unit: "L", Category: "Dairy"},
+			{Name: "Olive oil", Quantity: 0.5, Unit: "cup", Category: "Pantry"},
+			{Name: "Eggs", Quantity: 3, Category: "Dairy"},
+			{Name: "Salt", Quantity: 1, Unit: "tsp"},
+		}},
+	}
+
+	shoppingList := recipestore.BuildShoppingList(recipes)
+
+	categories := []string{"Baking", "Dairy", "Pantry", recipestore.OtherAisle}
+	if len(shoppingList.Aisles) != len(categories) {
+		t.Fatalf("BuildShoppingList(_) has %d aisles, want %d", len(shoppingList.Aisles), len(categories))
+	}
+	for i, category := range categories {
+		if shoppingList.Aisles[i].Category != category {
+			t.Errorf("Aisle %d is %s, want %s", i, shoppingList.Aisles[i].Category, category)
+		}
+	}
+
+	tests := []struct {
+		category string
+		name     string
+		quantity float32
+		unit     string
+	}{
+		{"Baking", "Flour", 1.25, "kg"},
+		{"Dairy", "Milk", 1.25, "l"},
+		{"Dairy", "Eggs", 5, ""},
+		{"Pantry", "Olive oil", 0.56, "cup"},
+		{recipestore.OtherAisle, "Salt", 1, "tsp"},
+	}
+	for _, test := range tests {
+		item := findItem(t, shoppingList, test.category, test.name)
+		if item.Quantity != test.quantity || item.Unit != test.unit {
+			t.Errorf("%s = %v %s, want %v %s", test.name, item.Quantity, item.Unit, test.quantity, test.unit)
+		}
+	}
+}
+
+func TestBuildShoppingListMixedUnits(t *testing.T) {
+	recipes := []*pb.Recipe{
+		{Id: "1", Ingredients: []*pb.Ingredient{
+			{Name: "Stock", Quantity: 1, Unit: "cup", Category: "Pantry"},
+			{Name: "Stock", Quantity: 100, Unit: "ml", Category: "Pantry"},
+			{Name: "Garlic", Quantity: 2, Unit: "cloves", Category: "Produce"},
+			{Name: "Garlic", Quantity: 1, Unit: "bulb", Category: "Produce"},
+		}},
+	}
+
+	shoppingList := recipestore.BuildShoppingList(recipes)
+
+	// Mixing metric and kitchen measures falls back to metric.
+	if stock := findItem(t, shoppingList, "Pantry", "Stock"); stock.Quantity != 336.59 || stock.Unit != "ml" {
+		t.Errorf("Stock = %v %s, want 336.59 ml", stock.Quantity, stock.Unit)
+	}
+
+	// Units that cannot be converted are kept as separate items.
+	for _, aisle := range shoppingList.Aisles {
+		if aisle.Category == "Produce" && len(aisle.Items) != 2 {
+			t.Errorf("Produce has %d items, want 2: %v", len(aisle.Items), aisle.Items)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+/*
+// ----------------------------------------------------------------------------
+// units.go
+// Countertop Recipe Microservice Ingredient Unit Conversion
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore
+
+import (
+	"math"
+	"strings"
+)
+
+type unitDimension int
+
+const (
+	massDimension unitDimension = iota
+	volumeDimension
+)
+
+type unitConversion struct {
+	dimension unitDimension
+	// Multiplier to the base unit of the dimension: grams or millilitres.
+	toBase float64
+	// US customary units: cups, spoons, fluid ounces, ounces and pounds.
+	imperial bool
+}
+
+const (
+	gramsPerOunce            = 28.3495
+	gramsPerPound            = 453.592
+	millilitresPerCup        = 236.588
+	millilitresPerFluidOunce = 29.5735
+	millilitresPerTablespoon = 14.787
+	millilitresPerTeaspoon   = 4.929
+)
+
+var unitConversions = map[string]unitConversion{
+	"g":           {massDimension, 1, false},
+	"gram":        {massDimension, 1, false},
+	"grams":       {massDimension, 1, false},
+	"kg":          {massDimension, 1000, false},
+	"kilogram":    {massDimension, 1000, false},
+	"kilograms":   {massDimension, 1000, false},
+	"oz":          {massDimension, gramsPerOunce, true},
+	"ounce":       {massDimension, gramsPerOunce, true},
+	"ounces":      {massDimension, gramsPerOunce, true},
+	"lb":          {massDimension, gramsPerPound, true},
+	"lbs":         {massDimension, gramsPerPound, true},
+	"pound":       {massDimension, gramsPerPound, true},
+	"pounds":      {massDimension, gramsPerPound, true},
+	"ml":          {volumeDimension, 1, false},
+	"millilitre":  {volumeDimension, 1, false},
+	"millilitres": {volumeDimension, 1, false},
+	"l":           {volumeDimension, 1000, false},
+	"litre":       {volumeDimension, 1000, false},
+	"litres":      {volumeDimension, 1000, false},
+	"fl oz":       {volumeDimension, millilitresPerFluidOunce, true},
+	"cup":         {volumeDimension, millilitresPerCup, true},
+	"cups":        {volumeDimension, millilitresPerCup, true},
+	"tbsp":        {volumeDimension, millilitresPerTablespoon, true},
+	"tablespoon":  {volumeDimension, millilitresPerTablespoon, true},
+	"tablespoons": {volumeDimension, millilitresPerTablespoon, true},
+	"tsp":         {volumeDimension, millilitresPerTeaspoon, true},
+	"teaspoon":    {volumeDimension, millilitresPerTeaspoon, true},
+	"teaspoons":   {volumeDimension, millilitresPerTeaspoon, true},
+}
+
+func normalizeUnit(unit string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(unit)), ".")
+}
+
+func lookupUnit(unit string) (unitConversion, bool) {
+	conversion, ok := unitConversions[normalizeUnit(unit)]
+	return conversion, ok
+}
+
+// formatQuantity expresses a quantity in grams or millilitres in the unit it
+// reads best in. Metric quantities switch to kilograms and litres from 1000
+// up; imperial ones use pounds from 16 oz and cups from a quarter cup.
+func formatQuantity(base float64, dimension unitDimension, imperial bool) (float64, string) {
+	switch dimension {
+	case massDimension:
+		if imperial {
+			if base >= 16*gramsPerOunce {
+				return base / gramsPerPound, "lb"
+			}
+			return base / gramsPerOunce, "oz"
+		}
+		if base >= 1000 {
+			return base / 1000, "kg"
+		}
+		return base, "g"
+	case volumeDimension:
+		if imperial {
+			switch {
+			case base >= millilitresPerCup/4:
+				return base / millilitresPerCup, "cup"
+			case base >= millilitresPerTablespoon:
+				return base / millilitresPerTablespoon, "tbsp"
+			}
+			return base / millilitresPerTeaspoon, "tsp"
+		}
+		if base >= 1000 {
+			return base / 1000, "l"
+		}
+		return base, "ml"
+	}
+	return base, ""
+}
+
+func roundQuantity(quantity float64) float32 {
+	return float32(math.Floor(quantity*100+0.5) / 100)
+}
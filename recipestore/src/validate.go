@@ -0,0 +1,79 @@
+/*
+// ----------------------------------------------------------------------------
+// validate.go
+// Countertop Recipe Microservice Recipe & Recipe Pack Validation
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore
+
+import (
+	"fmt"
+	"strings"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+)
+
+// ValidateRecipe checks that a recipe has everything the read RPCs and the
+// planner rely on before it is written to the recipes collection.
+func ValidateRecipe(recipe *pb.Recipe) error {
+	if strings.TrimSpace(recipe.Id) == "" {
+		return fmt.Errorf("recipe ID not specified")
+	}
+	if strings.TrimSpace(recipe.Name) == "" {
+		return fmt.Errorf("recipe %s has no name", recipe.Id)
+	}
+	if recipe.Servings <= 0 {
+		return fmt.Errorf("recipe %s has no servings", recipe.Id)
+	}
+	if len(recipe.Ingredients) == 0 {
+		return fmt.Errorf("recipe %s has no ingredients", recipe.Id)
+	}
+	for i, ingredient := range recipe.Ingredients {
+		if ingredient == nil || strings.TrimSpace(ingredient.Name) == "" {
+			return fmt.Errorf("recipe %s ingredient %d has no name", recipe.Id, i)
+		}
+		if ingredient.Quantity < 0 {
+			return fmt.Errorf("recipe %s ingredient %s has a negative quantity", recipe.Id, ingredient.Name)
+		}
+	}
+	if nutrition := recipe.Nutrition; nutrition != nil {
+		if nutrition.Calories < 0 || nutrition.Protein < 0 || nutrition.Carbohydrates < 0 || nutrition.Fat < 0 || nutrition.Sodium < 0 {
+			return fmt.Errorf("recipe %s has negative nutrition values", recipe.Id)
+		}
+	}
+	if courses := recipe.Mealcourses; courses == nil || !(courses.Breakfast || courses.Lunchanddinner || courses.Snacks || courses.Dessert) {
+		return fmt.Errorf("recipe %s is not assigned to a meal course", recipe.Id)
+	}
+	if profile := recipe.Dietaryprofile; profile == nil || !(profile.Omnivore || profile.Vegetarian || profile.Vegan) {
+		return fmt.Errorf("recipe %s: %v", recipe.Id, ErrNoDietaryProfile)
+	}
+	return nil
+}
+
+// ValidateRecipePack checks the pack and every recipe it embeds.
+func ValidateRecipePack(pack *pb.RecipePack) error {
+	if strings.TrimSpace(pack.Id) == "" {
+		return fmt.Errorf("recipe pack ID not specified")
+	}
+	if strings.TrimSpace(pack.Name) == "" {
+		return fmt.Errorf("recipe pack %s has no name", pack.Id)
+	}
+	if profile := pack.Dietaryprofile; profile == nil || !(profile.Omnivore || profile.Vegetarian || profile.Vegan) {
+		return fmt.Errorf("recipe pack %s: %v", pack.Id, ErrNoDietaryProfile)
+	}
+	if len(pack.Recipes) == 0 {
+		return fmt.Errorf("recipe pack %s has no recipes", pack.Id)
+	}
+	for _, recipe := range pack.Recipes {
+		if recipe == nil {
+			return fmt.Errorf("recipe pack %s contains an empty recipe", pack.Id)
+		}
+		if err := ValidateRecipe(recipe); err != nil {
+			return fmt.Errorf("recipe pack %s: %v", pack.Id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+/*
+// ----------------------------------------------------------------------------
+// validate_test.go
+// Countertop Recipe Microservice Recipe & Recipe Pack Validation Tests
+
+// Copyright (c) 2015 The Orange Chef Company. All rights reserved.
+// ----------------------------------------------------------------------------
+*/
+
+package recipestore_test
+
+import (
+	"testing"
+
+	pb "github.com/theorangechefco/cts/go-protos"
+	recipestore "github.com/theorangechefco/cts/recipestore"
+)
+
+func validRecipe() *pb.Recipe {
+	return &pb.Recipe{
+		Id:             "41",
+		Name:           "Lentil Soup",
+		Servings:       4,
+		Ingredients:    []*pb.Ingredient{{Name: "Lentils", Quantity: 200, Unit: "g"}},
+		Nutrition:      &pb.Nutrition{Calories: 410},
+		Mealcourses:    &pb.MealCourses{Lunchanddinner: true},
+		Dietaryprofile: &pb.DietaryProfile{Vegan: true},
+	}
+}
+
+func TestValidateRecipe(t *testing.T) {
+	if err := recipestore.ValidateRecipe(validRecipe()); err != nil {
+		t.Fatalf("ValidateRecipe(valid) = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*pb.Recipe)
+	}{
+		{"missing id", func(r *pb.Recipe) { r.Id = "" }},
+		{"missing name", func(r *pb.Recipe) { r.Name = " " }},
+		{"no servings", func(r *pb.Recipe) { r.Servings = 0 }},
+		{"no ingredients", func(r *pb.Recipe) { r.Ingredients = nil }},
+		{"unnamed ingredient", func(r *pb.Recipe) { r.Ingredients[0].Name = "" }},
+		{"negative quantity", func(r *pb.Recipe) { r.Ingredients[0].Quantity = -1 }},
+		{"negative nutrition", func(r *pb.Recipe) { r.Nutrition.Fat = -1 }},
+		{"no meal course", func(r *pb.Recipe) { r.Mealcourses = &pb.MealCourses{} }},
+		{"no dietary profile", func(r *pb.Recipe) { r.Dietaryprofile = nil }},
+	}
+	for _, test := range tests {
+		recipe := validRecipe()
+		test.modify(recipe)
+		if err := recipestore.ValidateRecipe(recipe); err == nil {
+			t.Errorf("%s: ValidateRecipe(_) = nil, want error", test.name)
+		}
+	}
+}
+
+func TestValidateRecipePack(t *testing.T) {
+	pack := &pb.RecipePack{
+		Id:             "7",
+		Name:           "Vegan Week",
+		Dietaryprofile: &pb.DietaryProfile{Vegan: true},
+		Recipes:        []*pb.Recipe{validRecipe()},
+	}
+	if err := recipestore.ValidateRecipePack(pack); err != nil {
+		t.Fatalf("ValidateRecipePack(valid) = %v", err)
+	}
+
+	pack.Recipes[0].Servings = 0
+	if err := recipestore.ValidateRecipePack(pack); err == nil {
+		t.Errorf("ValidateRecipePack(_) with invalid recipe = nil, want error")
+	}
+
+	pack.Recipes = nil
+	if err := recipestore.ValidateRecipePack(pack); err == nil {
+		t.Errorf("ValidateRecipePack(_) without recipes = nil, want error")
+	}
+}